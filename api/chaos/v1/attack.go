@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"github.com/slok/ragnarok/api"
+	"github.com/slok/ragnarok/attack"
+)
+
+const (
+	// AttackKind is the kind an attack.
+	AttackKind = "attack"
+	// AttackVersion is the version of an attack.
+	AttackVersion = "chaos/v1"
+
+	attackListKind    = "attackList"
+	attackListVersion = "chaos/v1"
+)
+
+// AttackTypeMeta is the attack type metadata.
+var AttackTypeMeta = api.TypeMeta{
+	Kind:    AttackKind,
+	Version: AttackVersion,
+}
+
+// AttackListTypeMeta is the attack list type metadata.
+var AttackListTypeMeta = api.TypeMeta{
+	Kind:    attackListKind,
+	Version: attackListVersion,
+}
+
+// AttackSpec is the specification of an attack that can be used on the failures.
+type AttackSpec struct {
+	// Description is the description of the attack.
+	Description string `json:"description,omitempty"`
+	// Options is the schema of the options the attack accepts.
+	Options attack.Schema `json:"options,omitempty"`
+}
+
+// Attack is an attack of the catalog that can be used on the attacks of a
+// failure, the ID of the metadata is the ID of the attack.
+type Attack struct {
+	api.TypeMeta `json:",inline"`
+
+	Metadata api.ObjectMeta `json:"metadata,omitempty"`
+	Spec     AttackSpec     `json:"spec,omitempty"`
+}
+
+// NewAttack is a plain Attack object contructor.
+func NewAttack() Attack {
+	return Attack{
+		TypeMeta: api.TypeMeta{
+			Kind:    AttackKind,
+			Version: AttackVersion,
+		},
+	}
+}
+
+// NewAttackFromInfo returns a new Attack object from the information of a
+// registered attack.
+func NewAttackFromInfo(info attack.Info) Attack {
+	a := NewAttack()
+	a.Metadata.ID = info.ID
+	a.Spec = AttackSpec{
+		Description: info.Description,
+		Options:     info.Schema,
+	}
+	return a
+}
+
+// GetObjectMetadata satisfies object interface.
+func (a *Attack) GetObjectMetadata() api.ObjectMeta {
+	return a.Metadata
+}
+
+// DeepCopy satisfies object interface.
+func (a *Attack) DeepCopy() api.Object {
+	copy := *a
+	copy.Spec.Options = append(attack.Schema(nil), a.Spec.Options...)
+	return &copy
+}
+
+// AttackList is an attack list.
+type AttackList struct {
+	api.TypeMeta `json:",inline"`
+	ListMetadata api.ListMeta `json:"listMetadata,omitempty"`
+	Items        []*Attack    `json:"items,omitempty"`
+}
+
+// NewAttackList returns a new AttackList.
+func NewAttackList(attacks []*Attack, continueList string) AttackList {
+	return AttackList{
+		TypeMeta: AttackListTypeMeta,
+		ListMetadata: api.ListMeta{
+			Continue: continueList,
+		},
+		Items: attacks,
+	}
+}
+
+// GetObjectMetadata satisfies object interface.
+func (a *AttackList) GetObjectMetadata() api.ObjectMeta {
+	return api.NoObjectMeta
+}
+
+// GetListMetadata satisfies objectList interface.
+func (a *AttackList) GetListMetadata() api.ListMeta {
+	return a.ListMetadata
+}
+
+// GetItems satisfies ObjectList interface.
+func (a *AttackList) GetItems() []api.Object {
+	res := make([]api.Object, len(a.Items))
+	for i, item := range a.Items {
+		res[i] = api.Object(item)
+	}
+	return res
+}
+
+// DeepCopy satisfies object interface.
+func (a *AttackList) DeepCopy() api.Object {
+	as := make([]*Attack, len(a.Items))
+	for i, attack := range a.Items {
+		as[i] = attack.DeepCopy().(*Attack)
+	}
+	copy := NewAttackList(as, a.ListMetadata.Continue)
+	return &copy
+}
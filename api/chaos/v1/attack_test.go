@@ -0,0 +1,86 @@
+package v1_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/ragnarok/api"
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
+	chaosv1pb "github.com/slok/ragnarok/api/chaos/v1/pb"
+	"github.com/slok/ragnarok/apimachinery/serializer"
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+var testAttackList = &chaosv1.AttackList{
+	TypeMeta: chaosv1.AttackListTypeMeta,
+	Items: []*chaosv1.Attack{
+		&chaosv1.Attack{
+			TypeMeta: chaosv1.AttackTypeMeta,
+			Metadata: api.ObjectMeta{ID: "attack1"},
+			Spec: chaosv1.AttackSpec{
+				Description: "first attack",
+				Options: attack.Schema{
+					{Name: "size", Type: attack.SizeOptType, Required: true, Description: "The size."},
+					{Name: "kind", Type: attack.StringOptType, Default: "file", Description: "The kind."},
+				},
+			},
+		},
+		&chaosv1.Attack{
+			TypeMeta: chaosv1.AttackTypeMeta,
+			Metadata: api.ObjectMeta{ID: "attack2"},
+		},
+	},
+}
+
+const testEncAttackList = `{"kind":"attackList","version":"chaos/v1","listMetadata":{},"items":[{"kind":"attack","version":"chaos/v1","metadata":{"id":"attack1"},"spec":{"description":"first attack","options":[{"name":"size","type":"size","required":true,"description":"The size."},{"name":"kind","type":"string","default":"file","description":"The kind."}]}},{"kind":"attack","version":"chaos/v1","metadata":{"id":"attack2"},"spec":{}}]}`
+
+func TestNewAttackFromInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	info := attack.Info{
+		ID:          "attack1",
+		Description: "first attack",
+		Schema:      testAttackList.Items[0].Spec.Options,
+	}
+	a := chaosv1.NewAttackFromInfo(info)
+	assert.Equal(*testAttackList.Items[0], a)
+}
+
+func TestJSONEncodeChaosV1AttackList(t *testing.T) {
+	assert := assert.New(t)
+
+	s := serializer.NewJSONSerializer(serializer.ObjTyper, serializer.ObjFactory, log.Dummy)
+	var b bytes.Buffer
+	if assert.NoError(s.Encode(testAttackList, &b)) {
+		assert.Equal(testEncAttackList, strings.TrimSuffix(b.String(), "\n"))
+	}
+}
+
+func TestJSONDecodeChaosV1AttackList(t *testing.T) {
+	assert := assert.New(t)
+
+	s := serializer.NewJSONSerializer(serializer.ObjTyper, serializer.ObjFactory, log.Dummy)
+	obj, err := s.Decode([]byte(testEncAttackList))
+	if assert.NoError(err) {
+		assert.Equal(testAttackList, obj)
+	}
+}
+
+func TestPBChaosV1AttackList(t *testing.T) {
+	assert := assert.New(t)
+
+	s := serializer.NewPBSerializer(log.Dummy)
+	pb := &chaosv1pb.AttackList{}
+	if assert.NoError(s.Encode(testAttackList, pb)) {
+		assert.Equal(testEncAttackList, strings.TrimSuffix(pb.SerializedData, "\n"))
+
+		obj, err := s.Decode(pb)
+		if assert.NoError(err) {
+			assert.Equal(testAttackList, obj)
+		}
+	}
+}
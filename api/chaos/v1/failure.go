@@ -83,6 +83,49 @@ type FailureStatus struct {
 	Creation      time.Time    `json:"creation,omitempty"`      // Creation is when the failure injection was created.
 	Executed      time.Time    `json:"executed,omitempty"`      // Executed is when the failure injectionwas executed.
 	Finished      time.Time    `json:"finished,omitempty"`      // Finished is when the failure injection was reverted.
+	Message       string       `json:"message,omitempty"`       // Message is the detail of the current state, like the error of an errored failure.
+}
+
+// Ramp increases the intensity of the attacks of a stage on steps, on every step
+// the attacks are recreated with the ramped options set to step/steps of their
+// value and the attacks of the previous step are reverted.
+type Ramp struct {
+	Steps    int           `json:"steps,omitempty"`    // Steps is the number of steps until the options reach their value.
+	Interval time.Duration `json:"interval,omitempty"` // Interval is the time between the steps.
+	Options  []string      `json:"options,omitempty"`  // Options are the numeric options of the attacks that will be ramped.
+}
+
+// Stage is a group of attacks applied at the same time, the stages of a failure
+// are applied sequentially.
+type Stage struct {
+	// Delay is the time waited before applying the stage, after the previous stage
+	// has finished or after the failure has been executed if it's the first one.
+	Delay time.Duration `json:"delay,omitempty"`
+	// Duration is the time the stage attacks will be applied, 0 will apply them until
+	// the failure finishes. The next stage will not start until this one finishes.
+	Duration time.Duration `json:"duration,omitempty"`
+	// Ramp will increase the intensity of the stage attacks, optional.
+	Ramp *Ramp `json:"ramp,omitempty"`
+	// Attacks are the attacks of the stage.
+	Attacks []AttackMap `json:"attacks,omitempty"`
+}
+
+// Activation is the way the attacks of a failure are activated on a node.
+type Activation struct {
+	// Probability is the probability (from 0 to 1) of the failure being applied on
+	// a node, not set will always apply the failure.
+	Probability *float64 `json:"probability,omitempty"`
+	// StartWindow is the window of the random delay waited before applying the
+	// attacks, 0 will apply the attacks without delay.
+	StartWindow time.Duration `json:"startWindow,omitempty"`
+	// On is the time the attacks are applied before reverting them when flapping,
+	// 0 will not flap.
+	On time.Duration `json:"on,omitempty"`
+	// Off is the time the attacks are reverted before applying them again when
+	// flapping, required if flapping.
+	Off time.Duration `json:"off,omitempty"`
+	// Seed is the seed of the random decisions, 0 will use a random seed.
+	Seed int64 `json:"seed,omitempty"`
 }
 
 // FailureSpec is the specification that has the information to it can be created and applied.
@@ -91,7 +134,16 @@ type FailureSpec struct {
 	Timeout time.Duration `json:"timeout,omitempty"`
 	// Attacks used an array so the no repeated elements of map limitation can be bypassed.
 	Attacks []AttackMap `json:"attacks,omitempty"`
-	// TODO: accuracy
+	// Stages are applied sequentially after the attacks have been applied.
+	Stages []Stage `json:"stages,omitempty"`
+	// Activation is the way the attacks are activated, by default they are applied
+	// without delay and kept until the failure finishes.
+	Activation *Activation `json:"activation,omitempty"`
+	// ApplyTimeout is the deadline of every attack apply, 0 will use the node one.
+	ApplyTimeout time.Duration `json:"applyTimeout,omitempty"`
+	// RevertTimeout is the deadline of every attack revert attempt, 0 will use the
+	// node one.
+	RevertTimeout time.Duration `json:"revertTimeout,omitempty"`
 }
 
 // Failure is the way a failure is defined.
@@ -1479,3 +1479,44 @@ items:
 		})
 	}
 }
+
+func TestJSONChaosV1FailureStages(t *testing.T) {
+	assert := assert.New(t)
+
+	failure := &chaosv1.Failure{
+		TypeMeta: chaosv1.FailureTypeMeta,
+		Metadata: api.ObjectMeta{
+			ID: "flr-001",
+		},
+		Spec: chaosv1.FailureSpec{
+			Timeout: 5 * time.Minute,
+			Stages: []chaosv1.Stage{
+				{
+					Attacks: []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "1GiB"}}},
+				},
+				{
+					Delay:    2 * time.Minute,
+					Duration: time.Minute,
+					Ramp: &chaosv1.Ramp{
+						Steps:    3,
+						Interval: 10 * time.Second,
+						Options:  []string{"percent"},
+					},
+					Attacks: []chaosv1.AttackMap{{"attack2": attack.Opts{"percent": float64(90)}}},
+				},
+			},
+		},
+	}
+	expEncFailure := `{"kind":"failure","version":"chaos/v1","metadata":{"id":"flr-001"},"spec":{"timeout":300000000000,"stages":[{"attacks":[{"attack1":{"size":"1GiB"}}]},{"delay":120000000000,"duration":60000000000,"ramp":{"steps":3,"interval":10000000000,"options":["percent"]},"attacks":[{"attack2":{"percent":90}}]}]},"status":{"creation":"0001-01-01T00:00:00Z","executed":"0001-01-01T00:00:00Z","finished":"0001-01-01T00:00:00Z"}}`
+
+	s := serializer.NewJSONSerializer(serializer.ObjTyper, serializer.ObjFactory, log.Dummy)
+	var b bytes.Buffer
+	if assert.NoError(s.Encode(failure, &b)) {
+		assert.Equal(expEncFailure, strings.TrimSuffix(b.String(), "\n"))
+	}
+
+	obj, err := s.Decode([]byte(expEncFailure))
+	if assert.NoError(err) {
+		assert.Equal(failure, obj)
+	}
+}
@@ -1,15 +1,6 @@
 // Code generated by protoc-gen-gogo. DO NOT EDIT.
 // source: chaos/v1/pb/failure.proto
 
-/*
-Package pb is a generated protocol buffer package.
-
-It is generated from these files:
-	chaos/v1/pb/failure.proto
-
-It has these top-level messages:
-	Failure
-*/
 package pb
 
 import proto "github.com/golang/protobuf/proto"
@@ -23,12 +14,6 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
-
 // Failure reprensents a failure information. For now use a simple json serialization.
 // TODO: create proto files from the API objects correctly.
 type Failure struct {
@@ -61,7 +61,9 @@ func (n NodeState) String() string {
 }
 
 // NodeSpec has the node specific fields.
-type NodeSpec struct{}
+type NodeSpec struct {
+	Attacks []string `json:"attacks,omitempty"` // Attacks are the IDs of the attacks the node supports.
+}
 
 // NodeStatus has the state fo the node.
 type NodeStatus struct {
@@ -96,6 +98,9 @@ func (n *Node) GetObjectMetadata() api.ObjectMeta {
 // DeepCopy satisfies object interface.
 func (n *Node) DeepCopy() api.Object {
 	copy := *n
+	if n.Spec.Attacks != nil {
+		copy.Spec.Attacks = append([]string{}, n.Spec.Attacks...)
+	}
 	return &copy
 }
 
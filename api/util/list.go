@@ -37,6 +37,13 @@ func NewObjectList(objs []api.Object, continueList string) (api.ObjectList, erro
 		}
 		l := chaosv1.NewExperimentList(es, continueList)
 		return &l, nil
+	case *chaosv1.Attack:
+		as := make([]*chaosv1.Attack, len(objs))
+		for i, obj := range objs {
+			as[i] = obj.(*chaosv1.Attack)
+		}
+		l := chaosv1.NewAttackList(as, continueList)
+		return &l, nil
 	// This is the test object used for some tests aroudn the app.
 	// TODO: Rethink to not get this code here (registrators?)
 	case *test.TestObj:
@@ -82,6 +82,12 @@ func (o *objFactory) NewPlainObject(t api.TypeMeta) (api.Object, error) {
 	case t == chaosv1.ExperimentListTypeMeta:
 		n := chaosv1.NewExperimentList([]*chaosv1.Experiment{}, "")
 		return &n, nil
+	case t == chaosv1.AttackTypeMeta:
+		n := chaosv1.NewAttack()
+		return &n, nil
+	case t == chaosv1.AttackListTypeMeta:
+		n := chaosv1.NewAttackList([]*chaosv1.Attack{}, "")
+		return &n, nil
 	default:
 		return nil, fmt.Errorf("unknown %s object type", t)
 	}
@@ -134,6 +140,11 @@ func (o *objTyper) SetType(obj api.Object) error {
 	case *chaosv1.ExperimentList:
 		v.TypeMeta = chaosv1.ExperimentListTypeMeta
 		o.setTypesOnListObjects(v, chaosv1.ExperimentTypeMeta)
+	case *chaosv1.Attack:
+		v.TypeMeta = chaosv1.AttackTypeMeta
+	case *chaosv1.AttackList:
+		v.TypeMeta = chaosv1.AttackListTypeMeta
+		o.setTypesOnListObjects(v, chaosv1.AttackTypeMeta)
 	default:
 		return fmt.Errorf("could not set the type of object because isn't a valid object type")
 	}
@@ -34,6 +34,15 @@ func (p *PBSerializer) encodeChaosV1Failure(obj api.Object, out *chaosv1pb.Failu
 	return nil
 }
 
+func (p *PBSerializer) encodeChaosV1AttackList(obj api.Object, out *chaosv1pb.AttackList) error {
+	var b bytes.Buffer
+	if err := p.serializer.Encode(obj, &b); err != nil {
+		return err
+	}
+	out.SerializedData = b.String()
+	return nil
+}
+
 func (p *PBSerializer) encodeClusterV1Node(obj api.Object, out *clusterv1pb.Node) error {
 	var b bytes.Buffer
 	if err := p.serializer.Encode(obj, &b); err != nil {
@@ -49,6 +58,9 @@ func (p *PBSerializer) decodeClusterV1Node(in *clusterv1pb.Node) (api.Object, er
 func (p *PBSerializer) decodeChaosV1Failure(in *chaosv1pb.Failure) (api.Object, error) {
 	return p.serializer.Decode([]byte(in.SerializedData))
 }
+func (p *PBSerializer) decodeChaosV1AttackList(in *chaosv1pb.AttackList) (api.Object, error) {
+	return p.serializer.Decode([]byte(in.SerializedData))
+}
 
 // Encode satisfies Serializer interface.
 func (p *PBSerializer) Encode(obj api.Object, out interface{}) error {
@@ -59,6 +71,8 @@ func (p *PBSerializer) Encode(obj api.Object, out interface{}) error {
 		err = p.encodeClusterV1Node(obj, pb)
 	case *chaosv1pb.Failure:
 		err = p.encodeChaosV1Failure(obj, pb)
+	case *chaosv1pb.AttackList:
+		err = p.encodeChaosV1AttackList(obj, pb)
 	default:
 		err = fmt.Errorf("unknown pb type")
 	}
@@ -77,6 +91,8 @@ func (p *PBSerializer) Decode(data interface{}) (api.Object, error) {
 		obj, err = p.decodeClusterV1Node(pb)
 	case *chaosv1pb.Failure:
 		obj, err = p.decodeChaosV1Failure(pb)
+	case *chaosv1pb.AttackList:
+		obj, err = p.decodeChaosV1AttackList(pb)
 	default:
 		err = fmt.Errorf("unknown pb type")
 	}
@@ -5,6 +5,8 @@ import (
 	"regexp"
 
 	"github.com/slok/ragnarok/api"
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/attack"
 )
 
 var (
@@ -69,3 +71,76 @@ func errorIfWrongTypeMeta(obj api.Object) error {
 	}
 	return nil
 }
+
+// errorIfInvalidAttacks will check if the attacks exist and their options are valid,
+// the node attacks missing on the registry are valid without checking the options.
+func errorIfInvalidAttacks(attacks []chaosv1.AttackMap, reg attack.Registry, nodeAttacks []string) []error {
+	errors := []error{}
+
+	for _, am := range attacks {
+		for id, opts := range am {
+			if !reg.Exists(id) && stringInSlice(id, nodeAttacks) {
+				continue
+			}
+			if err := reg.Validate(id, opts); err != nil {
+				errors = append(errors, fmt.Errorf("attack error: %s", err))
+			}
+		}
+	}
+	return errors
+}
+
+// stringInSlice returns true if the string is on the slice.
+func stringInSlice(str string, strs []string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// errorIfInvalidStages will check if the stages have attacks and valid timings.
+func errorIfInvalidStages(stages []chaosv1.Stage) []error {
+	errors := []error{}
+
+	for i, st := range stages {
+		if len(st.Attacks) == 0 {
+			errors = append(errors, fmt.Errorf("stage %d error: stage without attacks", i))
+		}
+		if st.Delay < 0 || st.Duration < 0 {
+			errors = append(errors, fmt.Errorf("stage %d error: delay and duration can't be negative", i))
+		}
+		if r := st.Ramp; r != nil {
+			if r.Steps < 1 {
+				errors = append(errors, fmt.Errorf("stage %d error: ramp needs at least one step", i))
+			}
+			if r.Interval < 0 {
+				errors = append(errors, fmt.Errorf("stage %d error: ramp interval can't be negative", i))
+			}
+			if len(r.Options) == 0 {
+				errors = append(errors, fmt.Errorf("stage %d error: ramp without options", i))
+			}
+		}
+	}
+	return errors
+}
+
+// errorIfInvalidActivation will check if the activation probability and timings are valid.
+func errorIfInvalidActivation(act *chaosv1.Activation) []error {
+	errors := []error{}
+	if act == nil {
+		return errors
+	}
+
+	if p := act.Probability; p != nil && (*p < 0 || *p > 1) {
+		errors = append(errors, fmt.Errorf("activation error: probability must be between 0 and 1"))
+	}
+	if act.StartWindow < 0 || act.On < 0 || act.Off < 0 {
+		errors = append(errors, fmt.Errorf("activation error: durations can't be negative"))
+	}
+	if (act.On == 0) != (act.Off == 0) {
+		errors = append(errors, fmt.Errorf("activation error: flapping needs on and off durations"))
+	}
+	return errors
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/slok/ragnarok/api"
 	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
 	clusterv1 "github.com/slok/ragnarok/api/cluster/v1"
+	"github.com/slok/ragnarok/attack"
 )
 
 const (
@@ -34,8 +35,24 @@ type ObjectValidator interface {
 	Validate(obj api.Object) ErrorList
 }
 
+// NodeAttacksGetter returns the IDs of the attacks a node reports it supports.
+type NodeAttacksGetter interface {
+	GetNodeAttacks(nodeID string) ([]string, error)
+}
+
+// NodeAttacksGetterFunc is a helper to use functions as NodeAttacksGetter.
+type NodeAttacksGetterFunc func(nodeID string) ([]string, error)
+
+// GetNodeAttacks satisfies NodeAttacksGetter interface.
+func (f NodeAttacksGetterFunc) GetNodeAttacks(nodeID string) ([]string, error) {
+	return f(nodeID)
+}
+
 // Object inplements the validation of the objects
-type Object struct{}
+type Object struct {
+	attackReg   attack.Registry   // attackReg is the registry used to validate the failure attacks, if nil they will not be validated.
+	nodeAttacks NodeAttacksGetter // nodeAttacks gets the attacks of the failure nodes that are not on the registry (plugins), if nil only the registry attacks are valid.
+}
 
 // DefaultObject is the default object validator.
 var DefaultObject = NewObject()
@@ -45,6 +62,39 @@ func NewObject() *Object {
 	return &Object{}
 }
 
+// NewObjectWithAttacks returns a new object validator that will validate the
+// failure attacks and their options with the attack registry.
+func NewObjectWithAttacks(reg attack.Registry) *Object {
+	return &Object{
+		attackReg: reg,
+	}
+}
+
+// NewObjectWithNodeAttacks returns a new object validator that will validate the
+// failure attacks with the attack registry, the attacks missing on the registry
+// will be valid if the failure node reports them (like the node plugin attacks),
+// their options will be validated by the node.
+func NewObjectWithNodeAttacks(reg attack.Registry, nodeAttacks NodeAttacksGetter) *Object {
+	return &Object{
+		attackReg:   reg,
+		nodeAttacks: nodeAttacks,
+	}
+}
+
+// getNodeAttacks returns the attacks of the failure node, if any.
+func (o *Object) getNodeAttacks(flr *chaosv1.Failure) []string {
+	nodeID, ok := flr.Metadata.Labels[api.LabelNode]
+	if o.nodeAttacks == nil || !ok {
+		return nil
+	}
+	// If the node can't be get the attacks will be validated only with the registry.
+	atts, err := o.nodeAttacks.GetNodeAttacks(nodeID)
+	if err != nil {
+		return nil
+	}
+	return atts
+}
+
 func (o *Object) validateObjectMeta(meta api.ObjectMeta) ErrorList {
 	errors := []error{}
 
@@ -96,6 +146,26 @@ func (o *Object) validateFailure(flr *chaosv1.Failure) ErrorList {
 	// Check failure labels correct.
 	errors = append(errors, errorIfNoLabelKeys(flr.Metadata.Labels, requiredFailureLabels)...)
 
+	// Check failure stages.
+	errors = append(errors, errorIfInvalidStages(flr.Spec.Stages)...)
+
+	// Check failure activation.
+	errors = append(errors, errorIfInvalidActivation(flr.Spec.Activation)...)
+
+	// Check failure timeouts.
+	if flr.Spec.ApplyTimeout < 0 || flr.Spec.RevertTimeout < 0 {
+		errors = append(errors, fmt.Errorf("apply and revert timeouts can't be negative"))
+	}
+
+	// Check failure attacks.
+	if o.attackReg != nil {
+		nodeAtts := o.getNodeAttacks(flr)
+		errors = append(errors, errorIfInvalidAttacks(flr.Spec.Attacks, o.attackReg, nodeAtts)...)
+		for _, st := range flr.Spec.Stages {
+			errors = append(errors, errorIfInvalidAttacks(st.Attacks, o.attackReg, nodeAtts)...)
+		}
+	}
+
 	return errors
 }
 
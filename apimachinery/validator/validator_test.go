@@ -1,12 +1,15 @@
 package validator_test
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/slok/ragnarok/api"
 	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
 	clusterv1 "github.com/slok/ragnarok/api/cluster/v1"
 	"github.com/slok/ragnarok/apimachinery/validator"
+	"github.com/slok/ragnarok/attack"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -299,6 +302,376 @@ func TestValidateFailure(t *testing.T) {
 	}
 }
 
+func TestValidateFailureAttacks(t *testing.T) {
+	reg := attack.NewSimpleRegistry()
+	reg.Register("attack1", attack.NewSchemaCreator("attack 1", attack.Schema{
+		{Name: "size", Type: attack.SizeOptType, Required: true},
+	}, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) { return nil, nil })))
+
+	tests := []struct {
+		name       string
+		attacks    []chaosv1.AttackMap
+		expInvalid bool
+	}{
+		{
+			name: "A failure with valid attacks should not return an error.",
+			attacks: []chaosv1.AttackMap{
+				{"attack1": attack.Opts{"size": "512MiB"}},
+				{"attack1": attack.Opts{"size": float64(1024)}},
+			},
+			expInvalid: false,
+		},
+		{
+			name: "A failure with a missing attack should return an error.",
+			attacks: []chaosv1.AttackMap{
+				{"attack2": attack.Opts{}},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A failure with invalid attack options should return an error.",
+			attacks: []chaosv1.AttackMap{
+				{"attack1": attack.Opts{"size": "big"}},
+			},
+			expInvalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			flr := &chaosv1.Failure{
+				TypeMeta: api.TypeMeta{Kind: chaosv1.FailureKind, Version: chaosv1.FailureVersion},
+				Metadata: api.ObjectMeta{
+					ID: "failure1",
+					Labels: map[string]string{
+						api.LabelExperiment: "exp1",
+						api.LabelNode:       "node1",
+					},
+				},
+				Spec: chaosv1.FailureSpec{
+					Attacks: test.attacks,
+				},
+			}
+
+			ov := validator.NewObjectWithAttacks(reg)
+			errs := ov.Validate(flr)
+
+			if test.expInvalid {
+				assert.NotEmpty(errs)
+			} else {
+				assert.Empty(errs)
+			}
+
+			// Without attack registry the attacks are not validated.
+			assert.Empty(validator.NewObject().Validate(flr))
+		})
+	}
+}
+
+func TestValidateFailureNodeAttacks(t *testing.T) {
+	reg := attack.NewSimpleRegistry()
+	reg.Register("attack1", attack.NewSchemaCreator("attack 1", attack.Schema{
+		{Name: "size", Type: attack.SizeOptType, Required: true},
+	}, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) { return nil, nil })))
+
+	nodeAtts := validator.NodeAttacksGetterFunc(func(nodeID string) ([]string, error) {
+		if nodeID != "node1" {
+			return nil, fmt.Errorf("missing node")
+		}
+		return []string{"attack1", "plugin1"}, nil
+	})
+
+	tests := []struct {
+		name       string
+		node       string
+		attacks    []chaosv1.AttackMap
+		expInvalid bool
+	}{
+		{
+			name: "A failure with attacks reported by its node should not return an error.",
+			node: "node1",
+			attacks: []chaosv1.AttackMap{
+				{"attack1": attack.Opts{"size": "512MiB"}},
+				{"plugin1": attack.Opts{"anything": true}},
+			},
+			expInvalid: false,
+		},
+		{
+			name: "A failure with attacks not reported by its node should return an error.",
+			node: "node1",
+			attacks: []chaosv1.AttackMap{
+				{"plugin2": attack.Opts{}},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A failure with registry attacks should be validated with the registry even if its node reports them.",
+			node: "node1",
+			attacks: []chaosv1.AttackMap{
+				{"attack1": attack.Opts{"size": "big"}},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A failure of a missing node should only accept the registry attacks.",
+			node: "node2",
+			attacks: []chaosv1.AttackMap{
+				{"plugin1": attack.Opts{}},
+			},
+			expInvalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			flr := &chaosv1.Failure{
+				TypeMeta: api.TypeMeta{Kind: chaosv1.FailureKind, Version: chaosv1.FailureVersion},
+				Metadata: api.ObjectMeta{
+					ID: "failure1",
+					Labels: map[string]string{
+						api.LabelExperiment: "exp1",
+						api.LabelNode:       test.node,
+					},
+				},
+				Spec: chaosv1.FailureSpec{
+					Attacks: test.attacks,
+				},
+			}
+
+			ov := validator.NewObjectWithNodeAttacks(reg, nodeAtts)
+			errs := ov.Validate(flr)
+
+			if test.expInvalid {
+				assert.NotEmpty(errs)
+			} else {
+				assert.Empty(errs)
+			}
+		})
+	}
+}
+
+func TestValidateFailureStages(t *testing.T) {
+	reg := attack.NewSimpleRegistry()
+	reg.Register("attack1", attack.NewSchemaCreator("attack 1", attack.Schema{
+		{Name: "size", Type: attack.SizeOptType, Required: true},
+	}, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) { return nil, nil })))
+
+	tests := []struct {
+		name       string
+		stages     []chaosv1.Stage
+		expInvalid bool
+	}{
+		{
+			name: "A failure with valid stages should not return an error.",
+			stages: []chaosv1.Stage{
+				{Attacks: []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "512MiB"}}}},
+				{
+					Delay:    2 * time.Minute,
+					Duration: 5 * time.Minute,
+					Ramp:     &chaosv1.Ramp{Steps: 5, Interval: time.Minute, Options: []string{"size"}},
+					Attacks:  []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "1GiB"}}},
+				},
+			},
+			expInvalid: false,
+		},
+		{
+			name: "A stage without attacks should return an error.",
+			stages: []chaosv1.Stage{
+				{Delay: time.Minute},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A stage with a negative delay should return an error.",
+			stages: []chaosv1.Stage{
+				{Delay: -time.Minute, Attacks: []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "512MiB"}}}},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A stage with a ramp without steps should return an error.",
+			stages: []chaosv1.Stage{
+				{
+					Ramp:    &chaosv1.Ramp{Interval: time.Minute, Options: []string{"size"}},
+					Attacks: []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "512MiB"}}},
+				},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A stage with a ramp without options should return an error.",
+			stages: []chaosv1.Stage{
+				{
+					Ramp:    &chaosv1.Ramp{Steps: 2, Interval: time.Minute},
+					Attacks: []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "512MiB"}}},
+				},
+			},
+			expInvalid: true,
+		},
+		{
+			name: "A stage with invalid attack options should return an error.",
+			stages: []chaosv1.Stage{
+				{Attacks: []chaosv1.AttackMap{{"attack1": attack.Opts{"size": "big"}}}},
+			},
+			expInvalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			flr := &chaosv1.Failure{
+				TypeMeta: api.TypeMeta{Kind: chaosv1.FailureKind, Version: chaosv1.FailureVersion},
+				Metadata: api.ObjectMeta{
+					ID: "failure1",
+					Labels: map[string]string{
+						api.LabelExperiment: "exp1",
+						api.LabelNode:       "node1",
+					},
+				},
+				Spec: chaosv1.FailureSpec{
+					Stages: test.stages,
+				},
+			}
+
+			ov := validator.NewObjectWithAttacks(reg)
+			errs := ov.Validate(flr)
+
+			if test.expInvalid {
+				assert.NotEmpty(errs)
+			} else {
+				assert.Empty(errs)
+			}
+		})
+	}
+}
+
+func TestValidateFailureActivation(t *testing.T) {
+	valid, zero, big, negative := 0.3, 0.0, 2.0, -0.1
+	tests := []struct {
+		name       string
+		activation *chaosv1.Activation
+		expInvalid bool
+	}{
+		{
+			name:       "A failure with a valid activation should not return an error.",
+			activation: &chaosv1.Activation{Probability: &valid, StartWindow: time.Minute, On: time.Minute, Off: 30 * time.Second, Seed: 1},
+			expInvalid: false,
+		},
+		{
+			name:       "A failure with a 0 probability should not return an error.",
+			activation: &chaosv1.Activation{Probability: &zero},
+			expInvalid: false,
+		},
+		{
+			name:       "A failure with an invalid probability should return an error.",
+			activation: &chaosv1.Activation{Probability: &big},
+			expInvalid: true,
+		},
+		{
+			name:       "A failure with a negative probability should return an error.",
+			activation: &chaosv1.Activation{Probability: &negative},
+			expInvalid: true,
+		},
+		{
+			name:       "A failure with a negative start window should return an error.",
+			activation: &chaosv1.Activation{StartWindow: -time.Second},
+			expInvalid: true,
+		},
+		{
+			name:       "A failure flapping without off time should return an error.",
+			activation: &chaosv1.Activation{On: time.Minute},
+			expInvalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			flr := &chaosv1.Failure{
+				TypeMeta: api.TypeMeta{Kind: chaosv1.FailureKind, Version: chaosv1.FailureVersion},
+				Metadata: api.ObjectMeta{
+					ID: "failure1",
+					Labels: map[string]string{
+						api.LabelExperiment: "exp1",
+						api.LabelNode:       "node1",
+					},
+				},
+				Spec: chaosv1.FailureSpec{
+					Activation: test.activation,
+				},
+			}
+
+			errs := validator.NewObject().Validate(flr)
+			if test.expInvalid {
+				assert.NotEmpty(errs)
+			} else {
+				assert.Empty(errs)
+			}
+		})
+	}
+}
+
+func TestValidateFailureTimeouts(t *testing.T) {
+	tests := []struct {
+		name          string
+		applyTimeout  time.Duration
+		revertTimeout time.Duration
+		expInvalid    bool
+	}{
+		{
+			name:          "A failure with apply and revert timeouts should not return an error.",
+			applyTimeout:  time.Minute,
+			revertTimeout: 2 * time.Minute,
+			expInvalid:    false,
+		},
+		{
+			name:         "A failure with a negative apply timeout should return an error.",
+			applyTimeout: -time.Minute,
+			expInvalid:   true,
+		},
+		{
+			name:          "A failure with a negative revert timeout should return an error.",
+			revertTimeout: -time.Minute,
+			expInvalid:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			flr := &chaosv1.Failure{
+				TypeMeta: api.TypeMeta{Kind: chaosv1.FailureKind, Version: chaosv1.FailureVersion},
+				Metadata: api.ObjectMeta{
+					ID: "failure1",
+					Labels: map[string]string{
+						api.LabelExperiment: "exp1",
+						api.LabelNode:       "node1",
+					},
+				},
+				Spec: chaosv1.FailureSpec{
+					ApplyTimeout:  test.applyTimeout,
+					RevertTimeout: test.revertTimeout,
+				},
+			}
+
+			errs := validator.NewObject().Validate(flr)
+			if test.expInvalid {
+				assert.NotEmpty(errs)
+			} else {
+				assert.Empty(errs)
+			}
+		})
+	}
+}
+
 func TestValidateExperiment(t *testing.T) {
 	tests := []struct {
 		name       string
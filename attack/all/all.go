@@ -0,0 +1,19 @@
+/*
+Package all registers all the attacks on the base attack registry, import it
+for its side effects.
+*/
+package all // import "github.com/slok/ragnarok/attack/all"
+
+import (
+	// Register all the attacks.
+	_ "github.com/slok/ragnarok/attack/command"
+	_ "github.com/slok/ragnarok/attack/cpu"
+	_ "github.com/slok/ragnarok/attack/disk"
+	_ "github.com/slok/ragnarok/attack/dummy"
+	_ "github.com/slok/ragnarok/attack/fd"
+	_ "github.com/slok/ragnarok/attack/memory"
+	_ "github.com/slok/ragnarok/attack/network"
+	_ "github.com/slok/ragnarok/attack/process"
+	_ "github.com/slok/ragnarok/attack/scheduler"
+	_ "github.com/slok/ragnarok/attack/timeskew"
+)
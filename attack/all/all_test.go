@@ -0,0 +1,37 @@
+package all_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/ragnarok/attack"
+	_ "github.com/slok/ragnarok/attack/all"
+)
+
+func TestAllAttacksHaveSchema(t *testing.T) {
+	reg, ok := attack.BaseReg().(attack.SimpleRegistry)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NotEmpty(t, reg)
+
+	for id, c := range reg {
+		sc, ok := c.(attack.SchemaCreater)
+		if assert.True(t, ok, "%s attack should have a schema", id) {
+			assert.NotEmpty(t, sc.Description(), "%s attack should have description", id)
+			names := map[string]bool{}
+			for _, spec := range sc.Schema() {
+				assert.False(t, names[spec.Name], "%s attack '%s' option is repeated", id, spec.Name)
+				names[spec.Name] = true
+				assert.NotEmpty(t, spec.Description, "%s attack '%s' option should have description", id, spec.Name)
+
+				// The defaults should be valid.
+				if spec.Default != nil {
+					_, err := attack.Schema{spec}.Decode(attack.Opts{})
+					assert.NoError(t, err, "%s attack '%s' option default should be valid", id, spec.Name)
+				}
+			}
+		}
+	}
+}
@@ -10,4 +10,32 @@ type Attacker interface {
 
 	// Revert reverts an attack or fault from the system
 	Revert() error
-}
\ No newline at end of file
+}
+
+// ContextReverter is implemented by the attacks that can stop reverting when the
+// context is done, the attacks that don't implement it are adapted by RevertContext.
+type ContextReverter interface {
+
+	// RevertContext reverts an attack or fault from the system, it should return
+	// when the context is done
+	RevertContext(ctx context.Context) error
+}
+
+// Verifier is implemented by the attacks that can check if they are in effect.
+type Verifier interface {
+
+	// Verify returns an error if the applied attack or fault is not in effect
+	Verify(ctx context.Context) error
+}
+
+// Recoverer is implemented by the attacks that leave state out of the process when
+// applied, the state can be reverted by a new attacker after the process has died.
+type Recoverer interface {
+
+	// RecoveryState returns the state required to revert the applied attack or fault
+	RecoveryState() Opts
+
+	// Recover reverts an applied attack or fault using its recovery state, it's called
+	// on a new attacker created with the same options
+	Recover(state Opts) error
+}
@@ -0,0 +1,301 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// ExecID is the identifier of the attack
+	ExecID = "exec"
+
+	// Options
+	applyKey   = "apply"
+	revertKey  = "revert"
+	envKey     = "env"
+	dirKey     = "dir"
+	timeoutKey = "timeout"
+
+	defaultExecTimeout = time.Minute
+)
+
+// shell is the shell used to run the commands.
+var shell = "/bin/sh"
+
+// execSchema is the schema of the attack options.
+var execSchema = attack.Schema{
+	{Name: applyKey, Type: attack.StringOptType, Required: true, Description: "The shell command run to apply the attack."},
+	{Name: revertKey, Type: attack.StringOptType, Description: "The shell command run to revert the attack."},
+	{Name: envKey, Type: attack.StringListOptType, Description: "The environment variables of the commands in KEY=VALUE format, added to the node ones."},
+	{Name: dirKey, Type: attack.StringOptType, Description: "The working directory of the commands, by default the node one."},
+	{Name: timeoutKey, Type: attack.DurationOptType, Default: defaultExecTimeout.String(), Description: "The time the commands have to finish before being killed."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(ExecID, attack.NewSchemaCreator("Runs user supplied shell commands to apply and revert custom failures.", execSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewExecOpts(o)
+	})))
+}
+
+// ExecConfig is the configuration of the exec attack.
+type ExecConfig struct {
+	ApplyCommand  string        // The shell command run to apply the attack.
+	RevertCommand string        // The shell command run to revert the attack, empty does nothing.
+	Env           []string      // The environment variables added to the commands in KEY=VALUE format.
+	Dir           string        // The working directory of the commands, empty uses the node one.
+	Timeout       time.Duration // The time the commands have to finish before being killed.
+}
+
+// Exec failer will apply a failure running user supplied shell commands. The
+// commands run on their own process group so all the processes they start are
+// killed when the command times out or the context is cancelled.
+type Exec struct {
+	Config ExecConfig
+
+	applied bool // Flag that marks the apply command has succeeded.
+	mu      sync.Mutex
+	log     log.Logger // Logger.
+}
+
+// NewExecOpts returns a new exec failer using options.
+func NewExecOpts(opts attack.Opts) (*Exec, error) {
+	cfg := ExecConfig{
+		Timeout: defaultExecTimeout,
+	}
+	var ok bool
+	if cfg.ApplyCommand, ok = opts[applyKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", applyKey, opts[applyKey])
+	}
+	if v, ok := opts[revertKey]; ok {
+		if cfg.RevertCommand, ok = v.(string); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", revertKey, v)
+		}
+	}
+	if v, ok := opts[envKey]; ok {
+		switch l := v.(type) {
+		case []string:
+			cfg.Env = l
+		case []interface{}:
+			for _, e := range l {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid '%s' option with '%v' value", envKey, v)
+				}
+				cfg.Env = append(cfg.Env, s)
+			}
+		default:
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", envKey, v)
+		}
+	}
+	if v, ok := opts[dirKey]; ok {
+		if cfg.Dir, ok = v.(string); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", dirKey, v)
+		}
+	}
+	if v, ok := opts[timeoutKey]; ok {
+		s, _ := v.(string)
+		var err error
+		if cfg.Timeout, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", timeoutKey, v)
+		}
+	}
+
+	return NewExec(cfg)
+}
+
+// NewExec returns a new exec failer.
+func NewExec(cfg ExecConfig) (*Exec, error) {
+	if strings.TrimSpace(cfg.ApplyCommand) == "" {
+		return nil, fmt.Errorf("apply command is required")
+	}
+
+	for _, e := range cfg.Env {
+		if i := strings.Index(e, "="); i <= 0 {
+			return nil, fmt.Errorf("invalid %s environment variable, the format is KEY=VALUE", e)
+		}
+	}
+
+	if cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("timeout must be positive")
+	}
+
+	return &Exec{
+		Config: cfg,
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will run the apply command and wait until it finishes, the attack will
+// fail if the command exits with an error. If the context is cancelled while the
+// command is running, its process group will be killed.
+func (e *Exec) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.applied {
+		return errors.New("exec already applied")
+	}
+
+	if err := e.run(ctx, applyKey, e.Config.ApplyCommand); err != nil {
+		return err
+	}
+	e.applied = true
+	e.log.Infof("exec apply command succeeded")
+	return nil
+}
+
+// Revert will run the revert command and wait until it finishes.
+func (e *Exec) Revert() error {
+	return e.RevertContext(context.Background())
+}
+
+// RevertContext satisfies attack.ContextReverter interface. The revert command will
+// be killed if the context is cancelled.
+func (e *Exec) RevertContext(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.applied {
+		return nil
+	}
+
+	if e.Config.RevertCommand != "" {
+		if err := e.run(ctx, revertKey, e.Config.RevertCommand); err != nil {
+			return err
+		}
+		e.log.Infof("exec revert command succeeded")
+	}
+	e.applied = false
+	return nil
+}
+
+// run runs a command on its own process group logging its output, the process
+// group is killed on timeout or when the context is cancelled.
+func (e *Exec) run(ctx context.Context, name, command string) error {
+	logger := e.log.With("command", name)
+	stdout, err := logOutput(logger.With("output", "stdout"))
+	if err != nil {
+		return fmt.Errorf("error creating %s command output: %s", name, err)
+	}
+	defer stdout.Close()
+	stderr, err := logOutput(logger.With("output", "stderr"))
+	if err != nil {
+		return fmt.Errorf("error creating %s command output: %s", name, err)
+	}
+	defer stderr.Close()
+
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Env = append(os.Environ(), e.Config.Env...)
+	cmd.Dir = e.Config.Dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting %s command: %s", name, err)
+	}
+
+	doneC := make(chan error, 1)
+	go func() {
+		doneC <- cmd.Wait()
+	}()
+
+	t := time.NewTimer(e.Config.Timeout)
+	defer t.Stop()
+
+	var reason string
+	select {
+	case err := <-doneC:
+		if err != nil {
+			return fmt.Errorf("%s command failed: %s", name, err)
+		}
+		return nil
+	case <-t.C:
+		reason = fmt.Sprintf("timed out after %s", e.Config.Timeout)
+	case <-ctx.Done():
+		reason = "cancelled"
+	}
+
+	// Kill the whole process group, the command could have started other processes.
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		logger.Errorf("error killing the process group: %s", err)
+	}
+	<-doneC
+	return fmt.Errorf("%s command %s", name, reason)
+}
+
+// logOutput returns the write end of a pipe whose lines are logged until all the
+// processes writing on it close it. Being a file, waiting for the command doesn't
+// wait for the processes it left on background holding its output.
+func logOutput(logger log.Logger) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer r.Close()
+		l := &lineLogger{log: logger}
+		io.Copy(l, r)
+		l.flush()
+	}()
+	return w, nil
+}
+
+// lineLogger is a writer that logs every line written on it.
+type lineLogger struct {
+	buf bytes.Buffer
+	log log.Logger
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.buf.Write(p)
+	for {
+		i := bytes.IndexByte(l.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := l.buf.Next(i + 1)
+		l.log.Info(string(line[:len(line)-1]))
+	}
+}
+
+// flush logs the last line if it didn't end with a new line.
+func (l *lineLogger) flush() {
+	if l.buf.Len() > 0 {
+		l.log.Info(l.buf.String())
+		l.buf.Reset()
+	}
+}
+
+// RecoveryState satisfies attack.Recoverer interface.
+func (e *Exec) RecoveryState() attack.Opts {
+	return nil
+}
+
+// Recover satisfies attack.Recoverer interface. The revert command is run even if
+// the apply command didn't finish, the commands need to handle it.
+func (e *Exec) Recover(state attack.Opts) error {
+	e.mu.Lock()
+	e.applied = true
+	e.mu.Unlock()
+	return e.Revert()
+}
@@ -0,0 +1,321 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+// testLogger is a logger that saves the info lines.
+type testLogger struct {
+	log.DummyLogger
+	lines *[]string
+}
+
+func (t *testLogger) Info(args ...interface{}) {
+	*t.lines = append(*t.lines, fmt.Sprint(args...))
+}
+
+func TestExecCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg ExecConfig
+		expErr bool
+	}{
+		{
+			name: "Creating an exec with all the options should use them.",
+			opts: attack.Opts{
+				"apply":   "touch /tmp/fault",
+				"revert":  "rm /tmp/fault",
+				"env":     []interface{}{"KEY=value", "OTHER="},
+				"dir":     "/tmp",
+				"timeout": "10s",
+			},
+			expCfg: ExecConfig{
+				ApplyCommand:  "touch /tmp/fault",
+				RevertCommand: "rm /tmp/fault",
+				Env:           []string{"KEY=value", "OTHER="},
+				Dir:           "/tmp",
+				Timeout:       10 * time.Second,
+			},
+		},
+		{
+			name: "Creating an exec only with the apply command should use the defaults.",
+			opts: attack.Opts{"apply": "true"},
+			expCfg: ExecConfig{
+				ApplyCommand: "true",
+				Timeout:      defaultExecTimeout,
+			},
+		},
+		{
+			name:   "Creating an exec without apply command should error.",
+			opts:   attack.Opts{"revert": "true"},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exec with an empty apply command should error.",
+			opts:   attack.Opts{"apply": " "},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exec with an invalid environment variable should error.",
+			opts:   attack.Opts{"apply": "true", "env": []string{"=value"}},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exec with an invalid timeout should error.",
+			opts:   attack.Opts{"apply": "true", "timeout": "soon"},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exec with a 0 timeout should error.",
+			opts:   attack.Opts{"apply": "true", "timeout": "0s"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			e, err := NewExecOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, e.Config)
+			}
+		})
+	}
+}
+
+func TestExecApplyRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-exec-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	e, err := NewExec(ExecConfig{
+		ApplyCommand:  `echo "$VALUE" > fault`,
+		RevertCommand: "rm fault",
+		Env:           []string{"VALUE=applied"},
+		Dir:           dir,
+		Timeout:       5 * time.Second,
+	})
+	require.NoError(err)
+
+	path := filepath.Join(dir, "fault")
+	require.NoError(e.Apply(context.Background()))
+	b, err := ioutil.ReadFile(path)
+	require.NoError(err)
+	assert.Equal("applied\n", string(b))
+	assert.Error(e.Apply(context.Background()), "applying twice should error")
+
+	require.NoError(e.Revert())
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+
+	// Reverting without being applied shouldn't run the revert command again.
+	assert.NoError(e.Revert())
+}
+
+func TestExecRevertContextCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	e, err := NewExec(ExecConfig{
+		ApplyCommand:  "true",
+		RevertCommand: "sleep 30",
+		Timeout:       time.Minute,
+	})
+	require.NoError(err)
+	require.NoError(e.Apply(context.Background()))
+
+	// The revert command should be killed when the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errC := make(chan error)
+	go func() {
+		errC <- e.RevertContext(ctx)
+	}()
+	select {
+	case err := <-errC:
+		assert.Error(err)
+	case <-time.After(5 * time.Second):
+		require.Fail("revert didn't return after the context cancellation")
+	}
+
+	// The attack is still applied so the revert can be retried.
+	e.mu.Lock()
+	assert.True(e.applied)
+	e.mu.Unlock()
+}
+
+func TestExecFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ExecConfig
+		expErr  bool
+		maxTime time.Duration
+	}{
+		{
+			name:   "A command exiting with an error should fail.",
+			cfg:    ExecConfig{ApplyCommand: "exit 3", Timeout: 5 * time.Second},
+			expErr: true,
+		},
+		{
+			name:   "A command on a missing directory should fail.",
+			cfg:    ExecConfig{ApplyCommand: "true", Dir: "/ragnarok/missing", Timeout: 5 * time.Second},
+			expErr: true,
+		},
+		{
+			name:    "A command that doesn't finish in time should be killed and fail.",
+			cfg:     ExecConfig{ApplyCommand: "sleep 10", Timeout: 100 * time.Millisecond},
+			expErr:  true,
+			maxTime: 5 * time.Second,
+		},
+		{
+			name:    "A command leaving a process on background with its output shouldn't wait for it.",
+			cfg:     ExecConfig{ApplyCommand: "sleep 3 &", Timeout: 10 * time.Second},
+			expErr:  false,
+			maxTime: 2 * time.Second,
+		},
+		{
+			name:    "A command with a process out of its process group should be killed on time.",
+			cfg:     ExecConfig{ApplyCommand: "setsid sleep 3 & sleep 10", Timeout: 100 * time.Millisecond},
+			expErr:  true,
+			maxTime: 2 * time.Second,
+		},
+		{
+			name:   "A command that succeeds shouldn't fail.",
+			cfg:    ExecConfig{ApplyCommand: "echo ok; echo warning >&2", Timeout: 5 * time.Second},
+			expErr: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			e, err := NewExec(test.cfg)
+			require.NoError(t, err)
+
+			start := time.Now()
+			err = e.Apply(context.Background())
+			assert.Equal(test.expErr, err != nil)
+			if test.maxTime > 0 {
+				assert.True(time.Since(start) < test.maxTime, "the command should finish on time")
+			}
+		})
+	}
+}
+
+func TestExecContextCancelKillsProcessGroup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-exec-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// The command starts a child process and waits for it.
+	e, err := NewExec(ExecConfig{
+		ApplyCommand: "sleep 30 & echo $! > child.pid; wait",
+		Dir:          dir,
+		Timeout:      time.Minute,
+	})
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error)
+	go func() {
+		errC <- e.Apply(ctx)
+	}()
+
+	// Wait until the child has started.
+	var pid int
+	for i := 0; pid == 0; i++ {
+		require.True(i < 500, "child process didn't start")
+		time.Sleep(10 * time.Millisecond)
+		b, _ := ioutil.ReadFile(filepath.Join(dir, "child.pid"))
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(b)))
+	}
+
+	cancel()
+	select {
+	case err := <-errC:
+		assert.Error(err)
+	case <-time.After(5 * time.Second):
+		require.Fail("apply didn't return after the context cancellation")
+	}
+
+	// The child should be killed with its parent.
+	for i := 0; alive(pid); i++ {
+		require.True(i < 500, "child process wasn't killed")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// alive checks if a process is running, zombies are not running.
+func alive(pid int) bool {
+	b, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// The state is after the command name: "pid (comm) state ...".
+	s := string(b)
+	i := strings.LastIndex(s, ")")
+	return i < 0 || !strings.HasPrefix(s[i+1:], " Z")
+}
+
+func TestLineLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	var lines []string
+	l := &lineLogger{log: &testLogger{lines: &lines}}
+	l.Write([]byte("first\nsec"))
+	l.Write([]byte("ond\n\nlast"))
+	assert.Equal([]string{"first", "second", ""}, lines)
+	l.flush()
+	assert.Equal([]string{"first", "second", "", "last"}, lines)
+}
+
+func TestExecRecover(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-exec-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cfg := ExecConfig{
+		ApplyCommand:  "touch fault",
+		RevertCommand: "rm fault",
+		Dir:           dir,
+		Timeout:       5 * time.Second,
+	}
+	e, err := NewExec(cfg)
+	require.NoError(err)
+	require.NoError(e.Apply(context.Background()))
+
+	// A new attacker should run the revert command.
+	re, err := NewExec(cfg)
+	require.NoError(err)
+	require.NoError(re.Recover(e.RecoveryState()))
+	_, err = os.Stat(filepath.Join(dir, "fault"))
+	assert.True(os.IsNotExist(err))
+}
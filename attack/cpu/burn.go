@@ -0,0 +1,168 @@
+package cpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// BurnID is the identifier of the attack
+	BurnID = "cpu_burn"
+
+	// Options
+	workersKey = "workers"
+	percentKey = "percent"
+
+	// burnPeriod is the duty cycle period, on each period a worker will be busy
+	// the target percent of the period and idle the rest of it.
+	burnPeriod = 100 * time.Millisecond
+)
+
+// burnSchema is the schema of the attack options.
+var burnSchema = attack.Schema{
+	{Name: workersKey, Type: attack.IntOptType, Description: "The number of goroutines burning CPU, by default one per CPU."},
+	{Name: percentKey, Type: attack.IntOptType, Default: 100, Description: "The target utilization percentage of each worker."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(BurnID, attack.NewSchemaCreator("Burns CPU with a constant load.", burnSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewBurnOpts(o)
+	})))
+}
+
+// Burn failer will apply a failure consuming CPU.
+type Burn struct {
+	Workers int // The number of goroutines burning CPU.
+	Percent int // The target utilization percentage of each worker.
+
+	stopC   chan struct{}  // Channel used to stop the workers.
+	wg      sync.WaitGroup // Used to wait until all the workers have finished.
+	running bool           // Flag that marks the workers are running.
+	mu      sync.Mutex
+	log     log.Logger // Logger.
+}
+
+// NewBurnOpts returns a new CPU burn failer using options. If the number of workers
+// is missing it will use one worker per CPU, if the percent is missing it will
+// use 100%.
+func NewBurnOpts(opts attack.Opts) (*Burn, error) {
+	workers := runtime.NumCPU()
+	if w, ok := opts[workersKey]; ok {
+		if workers, ok = w.(int); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", workersKey, w)
+		}
+	}
+
+	percent := 100
+	if p, ok := opts[percentKey]; ok {
+		if percent, ok = p.(int); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", percentKey, p)
+		}
+	}
+
+	return NewBurn(workers, percent)
+}
+
+// NewBurn returns a new CPU burn failer.
+func NewBurn(workers, percent int) (*Burn, error) {
+	if workers <= 0 {
+		return nil, fmt.Errorf("workers can't be 0 or less")
+	}
+
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("percent needs to be between 1 and 100")
+	}
+
+	return &Burn{
+		Workers: workers,
+		Percent: percent,
+		log:     log.Base(),
+	}, nil
+}
+
+// Apply will start the workers that burn the CPU, the workers will run until
+// the context is cancelled or the attack is reverted.
+func (b *Burn) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return errors.New("cpu burn already applied")
+	}
+
+	b.stopC = make(chan struct{})
+	for i := 0; i < b.Workers; i++ {
+		b.wg.Add(1)
+		go b.burn(ctx, b.stopC)
+	}
+	b.running = true
+	b.log.With("workers", b.Workers).With("percent", b.Percent).Infof("cpu burn started")
+	return nil
+}
+
+// burn will consume CPU based on the duty cycle until stopped.
+func (b *Burn) burn(ctx context.Context, stopC chan struct{}) {
+	defer b.wg.Done()
+	burnCycles(ctx, stopC, func() int { return b.Percent })
+}
+
+// burnCycles will consume CPU running duty cycles until stopped, on every cycle
+// the target percent will be obtained from the percent function.
+func burnCycles(ctx context.Context, stopC chan struct{}, percent func() int) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopC:
+			return
+		default:
+		}
+
+		busy := burnPeriod * time.Duration(percent()) / 100
+		idle := burnPeriod - busy
+
+		// Busy part of the cycle.
+		for start := time.Now(); time.Since(start) < busy; {
+		}
+
+		// Idle part of the cycle.
+		if idle > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stopC:
+				return
+			case <-time.After(idle):
+			}
+		}
+	}
+}
+
+// Revert will stop all the workers and wait until they have finished.
+func (b *Burn) Revert() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.running {
+		return nil
+	}
+
+	close(b.stopC)
+	b.wg.Wait()
+	b.running = false
+	b.log.With("workers", b.Workers).Infof("reverted cpu burn")
+	return nil
+}
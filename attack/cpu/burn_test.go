@@ -0,0 +1,118 @@
+package cpu
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+func TestBurnCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       attack.Opts
+		expWorkers int
+		expPercent int
+		expErr     bool
+	}{
+		{
+			name:       "Creating a burn with workers and percent should use them.",
+			opts:       attack.Opts{"workers": 4, "percent": 50},
+			expWorkers: 4,
+			expPercent: 50,
+		},
+		{
+			name:       "Creating a burn without options should use defaults.",
+			opts:       attack.Opts{},
+			expWorkers: runtime.NumCPU(),
+			expPercent: 100,
+		},
+		{
+			name:   "Creating a burn with invalid workers type should error.",
+			opts:   attack.Opts{"workers": "4"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a burn with 0 workers should error.",
+			opts:   attack.Opts{"workers": 0},
+			expErr: true,
+		},
+		{
+			name:   "Creating a burn with invalid percent type should error.",
+			opts:   attack.Opts{"percent": nil},
+			expErr: true,
+		},
+		{
+			name:   "Creating a burn with a percent greater than 100 should error.",
+			opts:   attack.Opts{"percent": 101},
+			expErr: true,
+		},
+		{
+			name:   "Creating a burn with a percent of 0 should error.",
+			opts:   attack.Opts{"percent": 0},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			b, err := NewBurnOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expWorkers, b.Workers)
+				assert.Equal(test.expPercent, b.Percent)
+			}
+		})
+	}
+}
+
+func TestBurnApplyRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	b, err := NewBurn(2, 10)
+	require.NoError(err)
+
+	require.NoError(b.Apply(context.Background()))
+	assert.True(b.running)
+	assert.Error(b.Apply(context.Background()), "applying twice should error")
+
+	require.NoError(b.Revert())
+	assert.False(b.running)
+
+	// Should be able to be applied again after a revert.
+	require.NoError(b.Apply(context.Background()))
+	assert.NoError(b.Revert())
+}
+
+func TestBurnStopsOnContextCancel(t *testing.T) {
+	require := require.New(t)
+
+	b, err := NewBurn(2, 50)
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(b.Apply(ctx))
+	cancel()
+
+	// Workers should finish by themselves.
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-time.After(1 * time.Second):
+		require.Fail("workers didn't stop after the context cancellation")
+	case <-done:
+	}
+	require.NoError(b.Revert())
+}
@@ -0,0 +1,352 @@
+package cpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/clock"
+	"github.com/slok/ragnarok/log"
+)
+
+// ProfileShape is the shape of the load a CPU profile will follow.
+type ProfileShape string
+
+const (
+	// SquareProfileShape alternates the max and min load every half period.
+	SquareProfileShape ProfileShape = "square"
+	// SineProfileShape follows a sine wave between the min and max load.
+	SineProfileShape ProfileShape = "sine"
+	// SpikesProfileShape on every period randomly spikes to the max load, the rest
+	// of the periods stays at the min load.
+	SpikesProfileShape ProfileShape = "spikes"
+	// StepsProfileShape follows an explicit list of steps repeating them.
+	StepsProfileShape ProfileShape = "steps"
+)
+
+const (
+	// ProfileID is the identifier of the attack
+	ProfileID = "cpu_profile"
+
+	// Options
+	profileKey      = "profile"
+	periodKey       = "period"
+	minPercentKey   = "min_percent"
+	maxPercentKey   = "max_percent"
+	spikePercentKey = "spike_percent"
+	stepsKey        = "steps"
+	durationKey     = "duration"
+
+	defaultProfilePeriod       = 10 * time.Second
+	defaultProfileSpikePercent = 20
+
+	// profileUpdateInterval is the interval the target load is updated.
+	profileUpdateInterval = 100 * time.Millisecond
+)
+
+// profileSchema is the schema of the attack options.
+var profileSchema = attack.Schema{
+	{Name: profileKey, Type: attack.StringOptType, Required: true, Description: "The shape of the load: square, sine, spikes or steps."},
+	{Name: workersKey, Type: attack.IntOptType, Description: "The number of goroutines burning CPU, by default one per CPU."},
+	{Name: periodKey, Type: attack.DurationOptType, Default: defaultProfilePeriod.String(), Description: "The period of the square, sine and spikes shapes."},
+	{Name: minPercentKey, Type: attack.IntOptType, Default: 0, Description: "The min target utilization of the square, sine and spikes shapes."},
+	{Name: maxPercentKey, Type: attack.IntOptType, Default: 100, Description: "The max target utilization of the square, sine and spikes shapes."},
+	{Name: spikePercentKey, Type: attack.IntOptType, Default: defaultProfileSpikePercent, Description: "The probability percent of a period being a spike on the spikes shape."},
+	{Name: stepsKey, Type: attack.ListOptType, Description: "The steps of the steps shape, objects with duration and percent."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(ProfileID, attack.NewSchemaCreator("Burns CPU with a load that changes over time.", profileSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewProfileOpts(o)
+	})))
+}
+
+// ProfileStep is a step of the steps profile.
+type ProfileStep struct {
+	Duration time.Duration // The duration of the step.
+	Percent  int           // The target utilization percentage of the step.
+}
+
+// ProfileConfig is the configuration of the CPU profile attack.
+type ProfileConfig struct {
+	Workers      int           // The number of goroutines burning CPU.
+	Shape        ProfileShape  // The shape of the load.
+	Period       time.Duration // The period of the square, sine and spikes shapes.
+	MinPercent   int           // The min target utilization of the square, sine and spikes shapes.
+	MaxPercent   int           // The max target utilization of the square, sine and spikes shapes.
+	SpikePercent int           // The probability percent of a period being a spike on the spikes shape.
+	Steps        []ProfileStep // The steps of the steps shape.
+}
+
+// Profile failer will apply a failure consuming CPU following a load profile
+// that changes over time.
+type Profile struct {
+	Config ProfileConfig
+
+	percent int32     // The current target utilization of the workers.
+	start   time.Time // The time the profile started.
+	spike   int64     // The last period the spike was decided.
+	spiking bool      // Flag that marks the current period is a spike.
+	rnd     *rand.Rand
+	stopC   chan struct{}  // Channel used to stop the workers.
+	wg      sync.WaitGroup // Used to wait until all the workers have finished.
+	running bool           // Flag that marks the workers are running.
+	mu      sync.Mutex
+	clock   clock.Clock // Clock.
+	log     log.Logger  // Logger.
+}
+
+// NewProfileOpts returns a new CPU profile failer using options. If the number
+// of workers is missing it will use one worker per CPU, if the period is missing
+// it will use 10s and if the min and max percents are missing they will be 0 and
+// 100.
+func NewProfileOpts(opts attack.Opts) (*Profile, error) {
+	cfg := ProfileConfig{
+		Workers:      runtime.NumCPU(),
+		Period:       defaultProfilePeriod,
+		MaxPercent:   100,
+		SpikePercent: defaultProfileSpikePercent,
+	}
+
+	s, ok := opts[profileKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", profileKey, opts[profileKey])
+	}
+	cfg.Shape = ProfileShape(s)
+
+	ints := map[string]*int{
+		workersKey:      &cfg.Workers,
+		minPercentKey:   &cfg.MinPercent,
+		maxPercentKey:   &cfg.MaxPercent,
+		spikePercentKey: &cfg.SpikePercent,
+	}
+	for k, dst := range ints {
+		if v, ok := opts[k]; ok {
+			if *dst, ok = v.(int); !ok {
+				return nil, fmt.Errorf("invalid '%s' option with '%v' value", k, v)
+			}
+		}
+	}
+
+	if v, ok := opts[periodKey]; ok {
+		var err error
+		if cfg.Period, err = parseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", periodKey, v)
+		}
+	}
+
+	if v, ok := opts[stepsKey]; ok {
+		steps, err := parseSteps(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value: %s", stepsKey, v, err)
+		}
+		cfg.Steps = steps
+	}
+
+	return NewProfile(cfg)
+}
+
+// parseDuration parses a duration string.
+func parseDuration(v interface{}) (time.Duration, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("duration needs to be a string")
+	}
+	return time.ParseDuration(s)
+}
+
+// parseSteps parses a list of steps, each step has a duration string and a percent.
+func parseSteps(v interface{}) ([]ProfileStep, error) {
+	var elems []map[string]interface{}
+	switch l := v.(type) {
+	case []map[string]interface{}:
+		elems = l
+	case []interface{}:
+		for _, e := range l {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("step needs to be an object")
+			}
+			elems = append(elems, m)
+		}
+	default:
+		return nil, fmt.Errorf("steps need to be a list")
+	}
+
+	steps := make([]ProfileStep, len(elems))
+	for i, e := range elems {
+		d, err := parseDuration(e[durationKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid step %d %s: %s", i, durationKey, err)
+		}
+		p, ok := e[percentKey].(int)
+		if !ok {
+			return nil, fmt.Errorf("invalid step %d %s", i, percentKey)
+		}
+		steps[i] = ProfileStep{Duration: d, Percent: p}
+	}
+	return steps, nil
+}
+
+// NewProfile returns a new CPU profile failer.
+func NewProfile(cfg ProfileConfig) (*Profile, error) {
+	if cfg.Workers <= 0 {
+		return nil, fmt.Errorf("workers can't be 0 or less")
+	}
+
+	switch cfg.Shape {
+	case SquareProfileShape, SineProfileShape, SpikesProfileShape:
+		if cfg.Period <= 0 {
+			return nil, fmt.Errorf("period can't be 0 or less")
+		}
+		if cfg.MinPercent < 0 || cfg.MaxPercent > 100 || cfg.MinPercent > cfg.MaxPercent {
+			return nil, fmt.Errorf("min and max percents need to be between 0 and 100 and min can't be greater than max")
+		}
+		if cfg.SpikePercent < 0 || cfg.SpikePercent > 100 {
+			return nil, fmt.Errorf("spike percent needs to be between 0 and 100")
+		}
+	case StepsProfileShape:
+		if len(cfg.Steps) == 0 {
+			return nil, fmt.Errorf("steps can't be empty")
+		}
+		for _, s := range cfg.Steps {
+			if s.Duration <= 0 {
+				return nil, fmt.Errorf("step duration can't be 0 or less")
+			}
+			if s.Percent < 0 || s.Percent > 100 {
+				return nil, fmt.Errorf("step percent needs to be between 0 and 100")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("profile needs to be %s, %s, %s or %s", SquareProfileShape, SineProfileShape, SpikesProfileShape, StepsProfileShape)
+	}
+
+	return &Profile{
+		Config: cfg,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:  clock.Base(),
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will start the workers that burn the CPU and the controller that
+// updates their load following the profile, they will run until the context is
+// cancelled or the attack is reverted.
+func (p *Profile) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return errors.New("cpu profile already applied")
+	}
+
+	p.start = p.clock.Now()
+	p.spike = -1
+	atomic.StoreInt32(&p.percent, int32(p.percentAt(0)))
+
+	p.stopC = make(chan struct{})
+	p.wg.Add(1)
+	go p.control(ctx, p.stopC)
+	for i := 0; i < p.Config.Workers; i++ {
+		p.wg.Add(1)
+		go func(stopC chan struct{}) {
+			defer p.wg.Done()
+			burnCycles(ctx, stopC, p.Percent)
+		}(p.stopC)
+	}
+	p.running = true
+	p.log.With("workers", p.Config.Workers).With("profile", p.Config.Shape).Infof("cpu profile started")
+	return nil
+}
+
+// Percent returns the current target utilization percentage of the workers.
+func (p *Profile) Percent() int {
+	return int(atomic.LoadInt32(&p.percent))
+}
+
+// control will update the target load of the workers on every interval until stopped.
+func (p *Profile) control(ctx context.Context, stopC chan struct{}) {
+	defer p.wg.Done()
+
+	t := p.clock.NewTicker(profileUpdateInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopC:
+			return
+		case now := <-t.C:
+			atomic.StoreInt32(&p.percent, int32(p.percentAt(now.Sub(p.start))))
+		}
+	}
+}
+
+// percentAt returns the target utilization percentage of the profile after the
+// elapsed time since the start.
+func (p *Profile) percentAt(elapsed time.Duration) int {
+	cfg := p.Config
+	switch cfg.Shape {
+	case SquareProfileShape:
+		if elapsed%cfg.Period < cfg.Period/2 {
+			return cfg.MaxPercent
+		}
+		return cfg.MinPercent
+	case SineProfileShape:
+		phase := 2 * math.Pi * float64(elapsed%cfg.Period) / float64(cfg.Period)
+		amplitude := float64(cfg.MaxPercent - cfg.MinPercent)
+		return cfg.MinPercent + int(math.Round(amplitude*(1+math.Sin(phase))/2))
+	case SpikesProfileShape:
+		// Decide only once per period if it's a spike.
+		if period := int64(elapsed / cfg.Period); period != p.spike {
+			p.spike = period
+			p.spiking = p.rnd.Intn(100) < cfg.SpikePercent
+		}
+		if p.spiking {
+			return cfg.MaxPercent
+		}
+		return cfg.MinPercent
+	case StepsProfileShape:
+		var total time.Duration
+		for _, s := range cfg.Steps {
+			total += s.Duration
+		}
+		elapsed %= total
+		for _, s := range cfg.Steps {
+			if elapsed < s.Duration {
+				return s.Percent
+			}
+			elapsed -= s.Duration
+		}
+	}
+	return 0
+}
+
+// Revert will stop all the workers and wait until they have finished.
+func (p *Profile) Revert() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running {
+		return nil
+	}
+
+	close(p.stopC)
+	p.wg.Wait()
+	p.running = false
+	p.log.With("workers", p.Config.Workers).Infof("reverted cpu profile")
+	return nil
+}
@@ -0,0 +1,276 @@
+package cpu
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+	mclock "github.com/slok/ragnarok/mocks/clock"
+)
+
+func TestProfileCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg ProfileConfig
+		expErr bool
+	}{
+		{
+			name: "Creating a square profile with all the options should use them.",
+			opts: attack.Opts{
+				"profile":       "square",
+				"workers":       2,
+				"period":        "1m",
+				"min_percent":   10,
+				"max_percent":   90,
+				"spike_percent": 50,
+			},
+			expCfg: ProfileConfig{
+				Workers:      2,
+				Shape:        SquareProfileShape,
+				Period:       time.Minute,
+				MinPercent:   10,
+				MaxPercent:   90,
+				SpikePercent: 50,
+			},
+		},
+		{
+			name: "Creating a sine profile without options should use defaults.",
+			opts: attack.Opts{
+				"profile": "sine",
+			},
+			expCfg: ProfileConfig{
+				Workers:      runtime.NumCPU(),
+				Shape:        SineProfileShape,
+				Period:       10 * time.Second,
+				MinPercent:   0,
+				MaxPercent:   100,
+				SpikePercent: 20,
+			},
+		},
+		{
+			name: "Creating a steps profile should use the steps.",
+			opts: attack.Opts{
+				"profile": "steps",
+				"workers": 1,
+				"steps": []interface{}{
+					map[string]interface{}{"duration": "10s", "percent": 20},
+					map[string]interface{}{"duration": "5s", "percent": 80},
+				},
+			},
+			expCfg: ProfileConfig{
+				Workers:      1,
+				Shape:        StepsProfileShape,
+				Period:       10 * time.Second,
+				MaxPercent:   100,
+				SpikePercent: 20,
+				Steps: []ProfileStep{
+					{Duration: 10 * time.Second, Percent: 20},
+					{Duration: 5 * time.Second, Percent: 80},
+				},
+			},
+		},
+		{
+			name:   "Creating a profile without profile should error.",
+			opts:   attack.Opts{},
+			expErr: true,
+		},
+		{
+			name:   "Creating a profile with an invalid profile should error.",
+			opts:   attack.Opts{"profile": "triangle"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a profile with an invalid period should error.",
+			opts:   attack.Opts{"profile": "square", "period": 10},
+			expErr: true,
+		},
+		{
+			name:   "Creating a profile with min percent greater than max should error.",
+			opts:   attack.Opts{"profile": "sine", "min_percent": 80, "max_percent": 20},
+			expErr: true,
+		},
+		{
+			name:   "Creating a steps profile without steps should error.",
+			opts:   attack.Opts{"profile": "steps"},
+			expErr: true,
+		},
+		{
+			name: "Creating a steps profile with an invalid step should error.",
+			opts: attack.Opts{
+				"profile": "steps",
+				"steps":   []interface{}{map[string]interface{}{"duration": "10s", "percent": 101}},
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a steps profile with a step without duration should error.",
+			opts: attack.Opts{
+				"profile": "steps",
+				"steps":   []interface{}{map[string]interface{}{"percent": 10}},
+			},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := NewProfileOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, p.Config)
+			}
+		})
+	}
+}
+
+func TestProfilePercentAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      ProfileConfig
+		expLoads map[time.Duration]int
+	}{
+		{
+			name: "Square profile should alternate the max and min every half period.",
+			cfg:  ProfileConfig{Shape: SquareProfileShape, Period: 10 * time.Second, MinPercent: 10, MaxPercent: 90},
+			expLoads: map[time.Duration]int{
+				0:                90,
+				4 * time.Second:  90,
+				5 * time.Second:  10,
+				9 * time.Second:  10,
+				10 * time.Second: 90,
+				16 * time.Second: 10,
+			},
+		},
+		{
+			name: "Sine profile should follow a sine wave between the min and max.",
+			cfg:  ProfileConfig{Shape: SineProfileShape, Period: 4 * time.Second, MinPercent: 20, MaxPercent: 80},
+			expLoads: map[time.Duration]int{
+				0:               50,
+				1 * time.Second: 80,
+				2 * time.Second: 50,
+				3 * time.Second: 20,
+				4 * time.Second: 50,
+			},
+		},
+		{
+			name: "Spikes profile always spiking should stay at the max.",
+			cfg:  ProfileConfig{Shape: SpikesProfileShape, Period: time.Second, MinPercent: 5, MaxPercent: 95, SpikePercent: 100},
+			expLoads: map[time.Duration]int{
+				0:               95,
+				3 * time.Second: 95,
+			},
+		},
+		{
+			name: "Spikes profile never spiking should stay at the min.",
+			cfg:  ProfileConfig{Shape: SpikesProfileShape, Period: time.Second, MinPercent: 5, MaxPercent: 95, SpikePercent: 0},
+			expLoads: map[time.Duration]int{
+				0:               5,
+				3 * time.Second: 5,
+			},
+		},
+		{
+			name: "Steps profile should follow the steps and repeat them.",
+			cfg: ProfileConfig{Shape: StepsProfileShape, Steps: []ProfileStep{
+				{Duration: 2 * time.Second, Percent: 30},
+				{Duration: 1 * time.Second, Percent: 70},
+			}},
+			expLoads: map[time.Duration]int{
+				0:                       30,
+				1999 * time.Millisecond: 30,
+				2 * time.Second:         70,
+				3 * time.Second:         30,
+				5 * time.Second:         70,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			test.cfg.Workers = 1
+			p, err := NewProfile(test.cfg)
+			require.NoError(t, err)
+			p.spike = -1
+
+			for elapsed, exp := range test.expLoads {
+				assert.Equal(exp, p.percentAt(elapsed), "elapsed %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestProfileFollowsTheClock(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	start := time.Now()
+	tC := make(chan time.Time)
+	mc := &mclock.Clock{}
+	mc.On("Now").Return(start)
+	mc.On("NewTicker", mock.Anything).Return(&time.Ticker{C: tC})
+
+	p, err := NewProfile(ProfileConfig{
+		Workers: 1,
+		Shape:   StepsProfileShape,
+		Steps: []ProfileStep{
+			{Duration: time.Second, Percent: 10},
+			{Duration: time.Second, Percent: 20},
+		},
+	})
+	require.NoError(err)
+	p.clock = mc
+
+	require.NoError(p.Apply(context.Background()))
+	assert.Error(p.Apply(context.Background()), "applying twice should error")
+	assert.Equal(10, p.Percent())
+
+	// Sending twice waits until the first one has been processed.
+	tC <- start.Add(1500 * time.Millisecond)
+	tC <- start.Add(1500 * time.Millisecond)
+	assert.Equal(20, p.Percent())
+	tC <- start.Add(2500 * time.Millisecond)
+	tC <- start.Add(2500 * time.Millisecond)
+	assert.Equal(10, p.Percent())
+
+	require.NoError(p.Revert())
+	assert.False(p.running)
+
+	// Should be able to be applied again after a revert.
+	require.NoError(p.Apply(context.Background()))
+	assert.NoError(p.Revert())
+}
+
+func TestProfileStopsOnContextCancel(t *testing.T) {
+	require := require.New(t)
+
+	p, err := NewProfile(ProfileConfig{Workers: 2, Shape: SquareProfileShape, Period: time.Second, MaxPercent: 50})
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(p.Apply(ctx))
+	cancel()
+
+	// Workers should finish by themselves.
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-time.After(1 * time.Second):
+		require.Fail("workers didn't stop after the context cancellation")
+	case <-done:
+	}
+	require.NoError(p.Revert())
+}
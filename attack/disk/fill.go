@@ -0,0 +1,299 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// FillID is the identifier of the attack
+	FillID = "disk_fill"
+
+	// Options
+	pathKey    = "path"
+	sizeKey    = "size"
+	percentKey = "percent"
+
+	fillFilePrefix = "ragnarok-disk-fill-"
+	filesStateKey  = "files"
+	fillChunkSize  = 1 << 20   // 1MiB, the size of each write.
+	fillFileSize   = 256 << 20 // 256MiB, the maximum size of each filler file.
+)
+
+// fillSchema is the schema of the attack options.
+var fillSchema = attack.Schema{
+	{Name: pathKey, Type: attack.StringOptType, Required: true, Description: "The directory where the files will be written."},
+	{Name: sizeKey, Type: attack.SizeOptType, Description: "The bytes that will be written, exclusive with percent."},
+	{Name: percentKey, Type: attack.IntOptType, Description: "The target usage percent of the filesystem, exclusive with size."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(FillID, attack.NewSchemaCreator("Consumes the free space of a filesystem.", fillSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewFillOpts(o)
+	})))
+}
+
+// Fill failer will apply a failure consuming the free space of a filesystem
+// writing filler files on a directory.
+type Fill struct {
+	Path    string // The directory where the filler files will be written.
+	Size    uint64 // The number of bytes to write, used when percent is 0.
+	Percent int    // The target usage percent of the filesystem.
+
+	files []string // The filler files created.
+	mu    sync.Mutex
+	log   log.Logger // Logger.
+}
+
+// NewFillOpts returns a new disk fill failer using options.
+func NewFillOpts(opts attack.Opts) (*Fill, error) {
+	path, ok := opts[pathKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", pathKey, opts[pathKey])
+	}
+
+	s, sOK := opts[sizeKey]
+	p, pOK := opts[percentKey]
+	if sOK == pOK {
+		return nil, fmt.Errorf("one of '%s' or '%s' options is required", sizeKey, percentKey)
+	}
+
+	if sOK {
+		size, ok := s.(int)
+		if !ok || size <= 0 {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", sizeKey, s)
+		}
+		return NewFill(path, uint64(size))
+	}
+
+	percent, ok := p.(int)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", percentKey, p)
+	}
+	return NewFillPercent(path, percent)
+}
+
+// NewFill returns a new disk fill failer that will write size bytes.
+func NewFill(path string, size uint64) (*Fill, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path can't be empty")
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("size can't be 0 or less")
+	}
+
+	return &Fill{
+		Path: path,
+		Size: size,
+		log:  log.Base(),
+	}, nil
+}
+
+// NewFillPercent returns a new disk fill failer that will write until the
+// filesystem reaches the usage percent.
+func NewFillPercent(path string, percent int) (*Fill, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path can't be empty")
+	}
+
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("percent needs to be between 1 and 100")
+	}
+
+	return &Fill{
+		Path:    path,
+		Percent: percent,
+		log:     log.Base(),
+	}, nil
+}
+
+// bytesToFill returns the number of bytes that need to be written on the path.
+func (f *Fill) bytesToFill() (uint64, error) {
+	if f.Percent == 0 {
+		return f.Size, nil
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(f.Path, &st); err != nil {
+		return 0, err
+	}
+	total := uint64(st.Blocks) * uint64(st.Bsize)
+	free := uint64(st.Bavail) * uint64(st.Bsize)
+	return bytesToPercent(total, free, f.Percent), nil
+}
+
+// bytesToPercent returns the number of bytes that need to be used on a filesystem
+// to reach the target percent of usage.
+func bytesToPercent(total, free uint64, percent int) uint64 {
+	target := total / 100 * uint64(percent)
+	used := total - free
+	if used >= target {
+		return 0
+	}
+	return target - used
+}
+
+// Apply will write the filler files. The filling will stop if the context is cancelled
+// or there is no space left on the device, in both cases the already written files
+// will be kept until the attack is reverted.
+func (f *Fill) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.files) > 0 {
+		return errors.New("disk fill already applied")
+	}
+
+	size, err := f.bytesToFill()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, fillChunkSize)
+	rand.Read(buf)
+
+	var written uint64
+	for written < size {
+		n, err := f.writeFile(ctx, buf, size-written)
+		written += n
+		if err == context.Canceled || err == context.DeadlineExceeded {
+			f.log.With("bytes", written).Warnf("disk fill cancelled")
+			return nil
+		}
+		if isNoSpaceErr(err) {
+			f.log.With("bytes", written).Warnf("disk fill reached the end of free space")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	f.log.With("path", f.Path).With("bytes", written).Infof("disk filled")
+	return nil
+}
+
+// writeFile creates a new filler file and writes up to max bytes on it.
+func (f *Fill) writeFile(ctx context.Context, buf []byte, max uint64) (uint64, error) {
+	if max > fillFileSize {
+		max = fillFileSize
+	}
+
+	fl, err := ioutil.TempFile(f.Path, fillFilePrefix)
+	if err != nil {
+		return 0, err
+	}
+	// Track the file before writing, so it's removed on revert whatever happens.
+	f.files = append(f.files, fl.Name())
+	defer fl.Close()
+
+	var written uint64
+	for written < max {
+		select {
+		case <-ctx.Done():
+			return written, ctx.Err()
+		default:
+		}
+
+		chunk := buf
+		if max-written < uint64(len(chunk)) {
+			chunk = chunk[:max-written]
+		}
+		n, err := fl.Write(chunk)
+		written += uint64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	// Make sure the space is allocated on the disk.
+	return written, fl.Sync()
+}
+
+func isNoSpaceErr(err error) bool {
+	if pErr, ok := err.(*os.PathError); ok {
+		err = pErr.Err
+	}
+	return err == syscall.ENOSPC
+}
+
+// Revert will remove all the filler files created.
+func (f *Fill) Revert() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Keep track of the files that couldn't be removed so a new revert can retry.
+	var errs []string
+	var remaining []string
+	for _, fl := range f.files {
+		if err := os.Remove(fl); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err.Error())
+			remaining = append(remaining, fl)
+		}
+	}
+	f.files = remaining
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error removing filler files: %v", errs)
+	}
+	f.log.With("path", f.Path).Infof("reverted disk fill")
+	return nil
+}
+
+// RecoveryState satisfies attack.Recoverer interface.
+func (f *Fill) RecoveryState() attack.Opts {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	files := make([]string, len(f.files))
+	copy(files, f.files)
+	return attack.Opts{filesStateKey: files}
+}
+
+// Recover satisfies attack.Recoverer interface. If the filler files are not known
+// (the process died while filling) all the filler files of the path are removed,
+// an empty list of known files doesn't remove any file.
+func (f *Fill) Recover(state attack.Opts) error {
+	var files []string
+	_, known := state[filesStateKey]
+	if fs, ok := state[filesStateKey].([]interface{}); ok {
+		for _, fl := range fs {
+			if s, ok := fl.(string); ok {
+				files = append(files, s)
+			}
+		}
+	}
+	if fs, ok := state[filesStateKey].([]string); ok {
+		files = fs
+	}
+
+	if !known {
+		var err error
+		if files, err = filepath.Glob(filepath.Join(f.Path, fillFilePrefix+"*")); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	f.files = files
+	f.mu.Unlock()
+	return f.Revert()
+}
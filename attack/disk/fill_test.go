@@ -0,0 +1,227 @@
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+func dirSize(t *testing.T, path string) (int, int64) {
+	fs, err := ioutil.ReadDir(path)
+	require.NoError(t, err)
+	var size int64
+	for _, f := range fs {
+		size += f.Size()
+	}
+	return len(fs), size
+}
+
+func TestFillCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expErr bool
+	}{
+		{
+			name: "Creating a fill with path and size should be valid.",
+			opts: attack.Opts{"path": "/tmp", "size": 1024},
+		},
+		{
+			name: "Creating a fill with path and percent should be valid.",
+			opts: attack.Opts{"path": "/tmp", "percent": 90},
+		},
+		{
+			name:   "Creating a fill without path should error.",
+			opts:   attack.Opts{"size": 1024},
+			expErr: true,
+		},
+		{
+			name:   "Creating a fill with size and percent should error.",
+			opts:   attack.Opts{"path": "/tmp", "size": 1024, "percent": 90},
+			expErr: true,
+		},
+		{
+			name:   "Creating a fill without size and percent should error.",
+			opts:   attack.Opts{"path": "/tmp"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a fill with invalid size should error.",
+			opts:   attack.Opts{"path": "/tmp", "size": "1024"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a fill with a percent greater than 100 should error.",
+			opts:   attack.Opts{"path": "/tmp", "percent": 101},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			_, err := NewFillOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestBytesToPercent(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    uint64
+		free     uint64
+		percent  int
+		expBytes uint64
+	}{
+		{
+			name:     "An empty filesystem should be filled until the percent.",
+			total:    1000,
+			free:     1000,
+			percent:  50,
+			expBytes: 500,
+		},
+		{
+			name:     "A used filesystem should be filled until the percent.",
+			total:    1000,
+			free:     700,
+			percent:  90,
+			expBytes: 600,
+		},
+		{
+			name:     "A filesystem used over the percent shouldn't be filled.",
+			total:    1000,
+			free:     100,
+			percent:  50,
+			expBytes: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expBytes, bytesToPercent(test.total, test.free, test.percent))
+		})
+	}
+}
+
+func TestFillApplyRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	size := uint64(3*fillChunkSize + 100)
+	f, err := NewFill(dir, size)
+	require.NoError(err)
+
+	require.NoError(f.Apply(context.Background()))
+	n, s := dirSize(t, dir)
+	assert.Equal(1, n)
+	assert.EqualValues(size, s)
+	assert.Error(f.Apply(context.Background()), "applying twice should error")
+
+	require.NoError(f.Revert())
+	n, _ = dirSize(t, dir)
+	assert.Equal(0, n)
+}
+
+func TestFillRevertAfterCancelledApply(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	f, err := NewFill(dir, 3*fillChunkSize)
+	require.NoError(err)
+
+	// Simulate a partial apply.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = f.writeFile(ctx, make([]byte, fillChunkSize), fillChunkSize)
+	require.Error(err)
+	n, _ := dirSize(t, dir)
+	require.Equal(1, n)
+
+	require.NoError(f.Revert())
+	n, _ = dirSize(t, dir)
+	assert.Equal(0, n)
+}
+
+func TestFillRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		withState  bool
+		emptyState bool // The state doesn't have filler files.
+		expFiles   int
+	}{
+		{
+			name:      "Recovering with the recovery state should remove the filler files of the state.",
+			withState: true,
+			expFiles:  1,
+		},
+		{
+			name:      "Recovering without the recovery state should remove the filler files of the path.",
+			withState: false,
+			expFiles:  1,
+		},
+		{
+			name:       "Recovering with a recovery state without files shouldn't remove the filler files of the path.",
+			withState:  true,
+			emptyState: true,
+			expFiles:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			dir, err := ioutil.TempDir("", "ragnarok-test")
+			require.NoError(err)
+			defer os.RemoveAll(dir)
+			other := filepath.Join(dir, "other")
+			require.NoError(ioutil.WriteFile(other, []byte("other"), 0644))
+
+			f, err := NewFill(dir, 2*fillChunkSize)
+			require.NoError(err)
+			require.NoError(f.Apply(context.Background()))
+
+			// The state is recovered from JSON by a new attacker.
+			var state attack.Opts
+			if test.withState {
+				st := f.RecoveryState()
+				if test.emptyState {
+					st = (&Fill{}).RecoveryState()
+				}
+				b, err := json.Marshal(st)
+				require.NoError(err)
+				require.NoError(json.Unmarshal(b, &state))
+			}
+			rf, err := NewFill(dir, 2*fillChunkSize)
+			require.NoError(err)
+
+			require.NoError(rf.Recover(state))
+			n, _ := dirSize(t, dir)
+			assert.Equal(test.expFiles, n)
+			_, err = os.Stat(other)
+			assert.NoError(err, "other files should be kept")
+		})
+	}
+}
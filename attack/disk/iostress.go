@@ -0,0 +1,379 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// IOStressID is the identifier of the attack
+	IOStressID = "io_stress"
+
+	// Options
+	workersKey   = "workers"
+	modeKey      = "mode"
+	operationKey = "operation"
+	fileSizeKey  = "file_size"
+	blockSizeKey = "block_size"
+	fsyncKey     = "fsync"
+	readBPSKey   = "read_bps"
+	writeBPSKey  = "write_bps"
+
+	ioStressFilePrefix = "ragnarok-io-stress-"
+
+	defaultIOStressWorkers   = 1
+	defaultIOStressFileSize  = 64 << 20 // 64MiB
+	defaultIOStressBlockSize = 64 << 10 // 64KiB
+)
+
+// IOMode is the way the scratch files are accessed.
+type IOMode string
+
+const (
+	// SequentialIOMode will access the scratch file block after block.
+	SequentialIOMode IOMode = "sequential"
+	// RandomIOMode will access the scratch file blocks in random order.
+	RandomIOMode IOMode = "random"
+)
+
+// IOOperation is the kind of operations made on the scratch files.
+type IOOperation string
+
+const (
+	// ReadIOOperation will only read from the scratch files.
+	ReadIOOperation IOOperation = "read"
+	// WriteIOOperation will only write on the scratch files.
+	WriteIOOperation IOOperation = "write"
+	// ReadWriteIOOperation will write and read on the scratch files.
+	ReadWriteIOOperation IOOperation = "readwrite"
+)
+
+// ioStressSchema is the schema of the attack options.
+var ioStressSchema = attack.Schema{
+	{Name: pathKey, Type: attack.StringOptType, Required: true, Description: "The directory where the scratch files will be created."},
+	{Name: workersKey, Type: attack.IntOptType, Default: defaultIOStressWorkers, Description: "The number of workers, each worker will have its own scratch file."},
+	{Name: modeKey, Type: attack.StringOptType, Default: string(SequentialIOMode), Description: "The access mode to the scratch files: sequential or random."},
+	{Name: operationKey, Type: attack.StringOptType, Default: string(ReadWriteIOOperation), Description: "The operations made on the scratch files: read, write or readwrite."},
+	{Name: fileSizeKey, Type: attack.SizeOptType, Default: defaultIOStressFileSize, Description: "The size of each scratch file."},
+	{Name: blockSizeKey, Type: attack.SizeOptType, Default: defaultIOStressBlockSize, Description: "The size of each read or write."},
+	{Name: fsyncKey, Type: attack.BoolOptType, Default: false, Description: "Fsync after each write."},
+	{Name: readBPSKey, Type: attack.SizeOptType, Description: "Read throughput cap of each worker in bytes per second."},
+	{Name: writeBPSKey, Type: attack.SizeOptType, Description: "Write throughput cap of each worker in bytes per second."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(IOStressID, attack.NewSchemaCreator("Stresses the disk with read and write operations.", ioStressSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewIOStressOpts(o)
+	})))
+}
+
+// IOStressConfig is the configuration of the I/O stress attack.
+type IOStressConfig struct {
+	Path      string      // The directory where the scratch files will be created.
+	Workers   int         // The number of workers, each worker will have its own scratch file.
+	Mode      IOMode      // The access mode to the scratch files.
+	Operation IOOperation // The operations made on the scratch files.
+	FileSize  int64       // The size of each scratch file.
+	BlockSize int         // The size of each read or write.
+	Fsync     bool        // Fsync after each write.
+	ReadBPS   uint64      // Read throughput cap of each worker in bytes per second, 0 is unlimited.
+	WriteBPS  uint64      // Write throughput cap of each worker in bytes per second, 0 is unlimited.
+}
+
+// IOStress failer will apply a failure stressing the disk with reads and writes
+// on scratch files.
+type IOStress struct {
+	Config IOStressConfig
+
+	files   []*os.File     // The scratch files.
+	stopC   chan struct{}  // Channel used to stop the workers.
+	wg      sync.WaitGroup // Used to wait until all the workers have finished.
+	running bool           // Flag that marks the workers are running.
+	mu      sync.Mutex
+	log     log.Logger // Logger.
+}
+
+// NewIOStressOpts returns a new I/O stress failer using options.
+func NewIOStressOpts(opts attack.Opts) (*IOStress, error) {
+	cfg := IOStressConfig{
+		Workers:   defaultIOStressWorkers,
+		Mode:      SequentialIOMode,
+		Operation: ReadWriteIOOperation,
+		FileSize:  defaultIOStressFileSize,
+		BlockSize: defaultIOStressBlockSize,
+	}
+
+	var ok bool
+	if cfg.Path, ok = opts[pathKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", pathKey, opts[pathKey])
+	}
+
+	for k, v := range opts {
+		switch k {
+		case workersKey:
+			cfg.Workers, ok = v.(int)
+		case modeKey:
+			var m string
+			m, ok = v.(string)
+			cfg.Mode = IOMode(m)
+		case operationKey:
+			var o string
+			o, ok = v.(string)
+			cfg.Operation = IOOperation(o)
+		case fileSizeKey:
+			var s int
+			s, ok = v.(int)
+			cfg.FileSize = int64(s)
+		case blockSizeKey:
+			cfg.BlockSize, ok = v.(int)
+		case fsyncKey:
+			cfg.Fsync, ok = v.(bool)
+		case readBPSKey:
+			var r int
+			r, ok = v.(int)
+			cfg.ReadBPS = uint64(r)
+			ok = ok && r >= 0
+		case writeBPSKey:
+			var w int
+			w, ok = v.(int)
+			cfg.WriteBPS = uint64(w)
+			ok = ok && w >= 0
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", k, v)
+		}
+	}
+
+	return NewIOStress(cfg)
+}
+
+// NewIOStress returns a new I/O stress failer.
+func NewIOStress(cfg IOStressConfig) (*IOStress, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("path can't be empty")
+	}
+	if cfg.Workers <= 0 {
+		return nil, fmt.Errorf("workers can't be 0 or less")
+	}
+	if cfg.Mode != SequentialIOMode && cfg.Mode != RandomIOMode {
+		return nil, fmt.Errorf("invalid mode '%s'", cfg.Mode)
+	}
+	if cfg.Operation != ReadIOOperation && cfg.Operation != WriteIOOperation && cfg.Operation != ReadWriteIOOperation {
+		return nil, fmt.Errorf("invalid operation '%s'", cfg.Operation)
+	}
+	if cfg.BlockSize <= 0 {
+		return nil, fmt.Errorf("block size can't be 0 or less")
+	}
+	if cfg.FileSize < int64(cfg.BlockSize) {
+		return nil, fmt.Errorf("file size can't be less than the block size")
+	}
+
+	return &IOStress{
+		Config: cfg,
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will create the scratch files and start the workers, the workers will run
+// until the context is cancelled or the attack is reverted.
+func (i *IOStress) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.running {
+		return errors.New("io stress already applied")
+	}
+
+	// Create the scratch files.
+	for j := 0; j < i.Config.Workers; j++ {
+		f, err := ioutil.TempFile(i.Config.Path, ioStressFilePrefix)
+		if err != nil {
+			i.cleanFiles()
+			return err
+		}
+		i.files = append(i.files, f)
+
+		// If we are going to read we need data on the file.
+		if i.Config.Operation != WriteIOOperation {
+			if err := i.prefill(ctx, f); err != nil {
+				i.cleanFiles()
+				return err
+			}
+		}
+	}
+
+	// Start the workers.
+	i.stopC = make(chan struct{})
+	for _, f := range i.files {
+		i.wg.Add(1)
+		go i.stress(ctx, i.stopC, f)
+	}
+	i.running = true
+
+	i.log.With("workers", i.Config.Workers).With("mode", i.Config.Mode).Infof("io stress started")
+	return nil
+}
+
+// prefill will write the scratch file so it can be read.
+func (i *IOStress) prefill(ctx context.Context, f *os.File) error {
+	buf := make([]byte, i.Config.BlockSize)
+	rand.Read(buf)
+	for off := int64(0); off < i.Config.FileSize; off += int64(len(buf)) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if _, err := f.WriteAt(buf, off); err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
+
+// stress will make the I/O operations on the file until stopped.
+func (i *IOStress) stress(ctx context.Context, stopC chan struct{}, f *os.File) {
+	defer i.wg.Done()
+
+	buf := make([]byte, i.Config.BlockSize)
+	rand.Read(buf)
+	blocks := i.Config.FileSize / int64(i.Config.BlockSize)
+	rt := newThrottle(i.Config.ReadBPS)
+	wt := newThrottle(i.Config.WriteBPS)
+	doRead := i.Config.Operation != WriteIOOperation
+	doWrite := i.Config.Operation != ReadIOOperation
+
+	var block int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopC:
+			return
+		default:
+		}
+
+		// Get the block to operate with.
+		if i.Config.Mode == RandomIOMode {
+			block = rand.Int63n(blocks)
+		} else {
+			block = (block + 1) % blocks
+		}
+		off := block * int64(i.Config.BlockSize)
+
+		if doWrite {
+			n, err := f.WriteAt(buf, off)
+			if err == nil && i.Config.Fsync {
+				err = f.Sync()
+			}
+			if err != nil {
+				i.log.Errorf("io stress worker stopped, error writing: %s", err)
+				return
+			}
+			if !wt.wait(ctx, stopC, n) {
+				return
+			}
+		}
+
+		if doRead {
+			n, err := f.ReadAt(buf, off)
+			if err != nil && err != io.EOF {
+				i.log.Errorf("io stress worker stopped, error reading: %s", err)
+				return
+			}
+			if !rt.wait(ctx, stopC, n) {
+				return
+			}
+		}
+	}
+}
+
+// cleanFiles closes and removes all the scratch files.
+func (i *IOStress) cleanFiles() error {
+	var errs []string
+	for _, f := range i.files {
+		f.Close()
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err.Error())
+		}
+	}
+	i.files = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error removing scratch files: %v", errs)
+	}
+	return nil
+}
+
+// Revert will stop all the workers and remove the scratch files.
+func (i *IOStress) Revert() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.running {
+		close(i.stopC)
+		i.wg.Wait()
+		i.running = false
+	}
+
+	if err := i.cleanFiles(); err != nil {
+		return err
+	}
+	i.log.With("workers", i.Config.Workers).Infof("reverted io stress")
+	return nil
+}
+
+// throttle limits the throughput of an operation to a rate of bytes per second.
+type throttle struct {
+	rate  uint64
+	start time.Time
+	bytes uint64
+}
+
+func newThrottle(rate uint64) *throttle {
+	return &throttle{
+		rate:  rate,
+		start: time.Now(),
+	}
+}
+
+// wait will register n bytes and will wait until the throughput is under the
+// rate, returns false if it was stopped while waiting.
+func (t *throttle) wait(ctx context.Context, stopC chan struct{}, n int) bool {
+	if t.rate == 0 {
+		return true
+	}
+
+	t.bytes += uint64(n)
+	expected := time.Duration(float64(t.bytes) / float64(t.rate) * float64(time.Second))
+	elapsed := time.Since(t.start)
+	if elapsed >= expected {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stopC:
+		return false
+	case <-time.After(expected - elapsed):
+		return true
+	}
+}
@@ -0,0 +1,151 @@
+package disk
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+func TestIOStressCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg IOStressConfig
+		expErr bool
+	}{
+		{
+			name: "Creating an io stress only with the path should use defaults.",
+			opts: attack.Opts{"path": "/tmp"},
+			expCfg: IOStressConfig{
+				Path:      "/tmp",
+				Workers:   defaultIOStressWorkers,
+				Mode:      SequentialIOMode,
+				Operation: ReadWriteIOOperation,
+				FileSize:  defaultIOStressFileSize,
+				BlockSize: defaultIOStressBlockSize,
+			},
+		},
+		{
+			name: "Creating an io stress with all the options should use them.",
+			opts: attack.Opts{
+				"path":       "/tmp",
+				"workers":    4,
+				"mode":       "random",
+				"operation":  "write",
+				"file_size":  1024,
+				"block_size": 512,
+				"fsync":      true,
+				"read_bps":   100,
+				"write_bps":  200,
+			},
+			expCfg: IOStressConfig{
+				Path:      "/tmp",
+				Workers:   4,
+				Mode:      RandomIOMode,
+				Operation: WriteIOOperation,
+				FileSize:  1024,
+				BlockSize: 512,
+				Fsync:     true,
+				ReadBPS:   100,
+				WriteBPS:  200,
+			},
+		},
+		{
+			name:   "Creating an io stress without path should error.",
+			opts:   attack.Opts{"workers": 4},
+			expErr: true,
+		},
+		{
+			name:   "Creating an io stress with an invalid mode should error.",
+			opts:   attack.Opts{"path": "/tmp", "mode": "spiral"},
+			expErr: true,
+		},
+		{
+			name:   "Creating an io stress with an invalid operation should error.",
+			opts:   attack.Opts{"path": "/tmp", "operation": "delete"},
+			expErr: true,
+		},
+		{
+			name:   "Creating an io stress with a file smaller than the block should error.",
+			opts:   attack.Opts{"path": "/tmp", "file_size": 10, "block_size": 512},
+			expErr: true,
+		},
+		{
+			name:   "Creating an io stress with a negative throughput should error.",
+			opts:   attack.Opts{"path": "/tmp", "write_bps": -1},
+			expErr: true,
+		},
+		{
+			name:   "Creating an io stress with an invalid fsync should error.",
+			opts:   attack.Opts{"path": "/tmp", "fsync": "yes"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			i, err := NewIOStressOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, i.Config)
+			}
+		})
+	}
+}
+
+func TestIOStressApplyRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	i, err := NewIOStress(IOStressConfig{
+		Path:      dir,
+		Workers:   2,
+		Mode:      RandomIOMode,
+		Operation: ReadWriteIOOperation,
+		FileSize:  256 << 10,
+		BlockSize: 4 << 10,
+		Fsync:     true,
+	})
+	require.NoError(err)
+
+	require.NoError(i.Apply(context.Background()))
+	n, _ := dirSize(t, dir)
+	assert.Equal(2, n)
+	assert.Error(i.Apply(context.Background()), "applying twice should error")
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(i.Revert())
+	n, _ = dirSize(t, dir)
+	assert.Equal(0, n)
+}
+
+func TestThrottle(t *testing.T) {
+	assert := assert.New(t)
+
+	// Unlimited.
+	th := newThrottle(0)
+	assert.True(th.wait(context.Background(), nil, 1<<30))
+
+	// Under the rate.
+	th = newThrottle(1 << 30)
+	assert.True(th.wait(context.Background(), nil, 1))
+
+	// Over the rate should wait and stop if stopped.
+	stopC := make(chan struct{})
+	close(stopC)
+	th = newThrottle(1)
+	assert.False(th.wait(context.Background(), stopC, 1000))
+}
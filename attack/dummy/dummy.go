@@ -11,11 +11,14 @@ const (
 	DummyID = "dummy"
 )
 
+// dummySchema is the schema of the attack options, it doesn't have options.
+var dummySchema = attack.Schema{}
+
 // Register the creator of the attack
 func init() {
-	attack.Register(DummyID, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+	attack.Register(DummyID, attack.NewSchemaCreator("Does nothing, useful for testing.", dummySchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
 		return NewDummy(o)
-	}))
+	})))
 }
 
 // Dummy failer will do nothing.
@@ -0,0 +1,253 @@
+package fd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// ExhaustionID is the identifier of the attack
+	ExhaustionID = "fd_exhaustion"
+
+	// Options
+	countKey   = "count"
+	percentKey = "percent"
+	kindKey    = "kind"
+)
+
+// fdPath is the path where the open file descriptors of the process are listed.
+var fdPath = "/proc/self/fd"
+
+// Kind is the kind of file descriptor that will be opened.
+type Kind string
+
+const (
+	// FileKind will open files.
+	FileKind Kind = "file"
+	// SocketKind will open sockets.
+	SocketKind Kind = "socket"
+)
+
+// exhaustionSchema is the schema of the attack options.
+var exhaustionSchema = attack.Schema{
+	{Name: countKey, Type: attack.IntOptType, Description: "The target number of open file descriptors, exclusive with percent."},
+	{Name: percentKey, Type: attack.IntOptType, Description: "The target percent of open file descriptors from the process limit, exclusive with count."},
+	{Name: kindKey, Type: attack.StringOptType, Default: string(FileKind), Description: "The kind of file descriptors opened: file or socket."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(ExhaustionID, attack.NewSchemaCreator("Opens file descriptors until the process reaches a target.", exhaustionSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewExhaustionOpts(o)
+	})))
+}
+
+// Exhaustion failer will apply a failure opening file descriptors until the
+// process reaches a number of open file descriptors.
+type Exhaustion struct {
+	Count   int  // The target number of open file descriptors, used when percent is 0.
+	Percent int  // The target percent of open file descriptors from the process limit.
+	Kind    Kind // The kind of file descriptors opened.
+
+	fds []*os.File // The opened file descriptors.
+	mu  sync.Mutex
+	log log.Logger // Logger.
+}
+
+// NewExhaustionOpts returns a new file descriptor exhaustion failer using options.
+func NewExhaustionOpts(opts attack.Opts) (*Exhaustion, error) {
+	kind := FileKind
+	if v, ok := opts[kindKey]; ok {
+		k, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", kindKey, v)
+		}
+		kind = Kind(k)
+	}
+
+	c, cOK := opts[countKey]
+	p, pOK := opts[percentKey]
+	if cOK == pOK {
+		return nil, fmt.Errorf("one of '%s' or '%s' options is required", countKey, percentKey)
+	}
+
+	if cOK {
+		count, ok := c.(int)
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", countKey, c)
+		}
+		return NewExhaustion(count, kind)
+	}
+
+	percent, ok := p.(int)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", percentKey, p)
+	}
+	return NewExhaustionPercent(percent, kind)
+}
+
+// NewExhaustion returns a new file descriptor exhaustion failer that will open
+// file descriptors until the process has count open file descriptors.
+func NewExhaustion(count int, kind Kind) (*Exhaustion, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("count can't be 0 or less")
+	}
+
+	if err := validKind(kind); err != nil {
+		return nil, err
+	}
+
+	return &Exhaustion{
+		Count: count,
+		Kind:  kind,
+		log:   log.Base(),
+	}, nil
+}
+
+// NewExhaustionPercent returns a new file descriptor exhaustion failer that will open
+// file descriptors until the process reaches the percent of its open file limit.
+func NewExhaustionPercent(percent int, kind Kind) (*Exhaustion, error) {
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("percent needs to be between 1 and 100")
+	}
+
+	if err := validKind(kind); err != nil {
+		return nil, err
+	}
+
+	return &Exhaustion{
+		Percent: percent,
+		Kind:    kind,
+		log:     log.Base(),
+	}, nil
+}
+
+func validKind(kind Kind) error {
+	if kind != FileKind && kind != SocketKind {
+		return fmt.Errorf("invalid kind '%s'", kind)
+	}
+	return nil
+}
+
+// target returns the target number of open file descriptors.
+func (e *Exhaustion) target() (int, error) {
+	if e.Percent == 0 {
+		return e.Count, nil
+	}
+
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, err
+	}
+	return int(rl.Cur * uint64(e.Percent) / 100), nil
+}
+
+// openFDs returns the number of open file descriptors of the process.
+func openFDs() (int, error) {
+	fs, err := ioutil.ReadDir(fdPath)
+	if err != nil {
+		return 0, err
+	}
+	// Don't count the file descriptor used to read the directory.
+	return len(fs) - 1, nil
+}
+
+// open opens a new file descriptor.
+func (e *Exhaustion) open() (*os.File, error) {
+	if e.Kind == SocketKind {
+		fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, 0)
+		if err != nil {
+			return nil, os.NewSyscallError("socket", err)
+		}
+		return os.NewFile(uintptr(fd), "socket"), nil
+	}
+	return os.Open(os.DevNull)
+}
+
+func isLimitErr(err error) bool {
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.SyscallError:
+		err = e.Err
+	}
+	return err == syscall.EMFILE || err == syscall.ENFILE
+}
+
+// Apply will open file descriptors until the target is reached.
+func (e *Exhaustion) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.fds) > 0 {
+		return errors.New("fd exhaustion already applied")
+	}
+
+	target, err := e.target()
+	if err != nil {
+		return err
+	}
+	current, err := openFDs()
+	if err != nil {
+		return err
+	}
+
+	for i := current; i < target; i++ {
+		f, err := e.open()
+		if isLimitErr(err) {
+			e.log.With("fds", len(e.fds)).Warnf("fd exhaustion reached the limit of open files")
+			break
+		}
+		if err != nil {
+			e.close()
+			return err
+		}
+		e.fds = append(e.fds, f)
+	}
+
+	e.log.With("fds", len(e.fds)).With("kind", e.Kind).Infof("opened file descriptors")
+	return nil
+}
+
+// close closes all the opened file descriptors.
+func (e *Exhaustion) close() error {
+	var errs []string
+	for _, f := range e.fds {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	e.fds = nil
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error closing file descriptors: %v", errs)
+	}
+	return nil
+}
+
+// Revert will close all the opened file descriptors.
+func (e *Exhaustion) Revert() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := len(e.fds)
+	if err := e.close(); err != nil {
+		return err
+	}
+	e.log.With("fds", n).Infof("reverted fd exhaustion")
+	return nil
+}
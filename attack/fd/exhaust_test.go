@@ -0,0 +1,111 @@
+package fd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+func TestExhaustionCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       attack.Opts
+		expCount   int
+		expPercent int
+		expKind    Kind
+		expErr     bool
+	}{
+		{
+			name:     "Creating an exhaustion with count should open files by default.",
+			opts:     attack.Opts{"count": 1000},
+			expCount: 1000,
+			expKind:  FileKind,
+		},
+		{
+			name:       "Creating an exhaustion with percent and kind should use them.",
+			opts:       attack.Opts{"percent": 90, "kind": "socket"},
+			expPercent: 90,
+			expKind:    SocketKind,
+		},
+		{
+			name:   "Creating an exhaustion without count and percent should error.",
+			opts:   attack.Opts{"kind": "socket"},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exhaustion with count and percent should error.",
+			opts:   attack.Opts{"count": 1000, "percent": 90},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exhaustion with an invalid count should error.",
+			opts:   attack.Opts{"count": "1000"},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exhaustion with a percent greater than 100 should error.",
+			opts:   attack.Opts{"percent": 101},
+			expErr: true,
+		},
+		{
+			name:   "Creating an exhaustion with an invalid kind should error.",
+			opts:   attack.Opts{"count": 1000, "kind": "pipe"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			e, err := NewExhaustionOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCount, e.Count)
+				assert.Equal(test.expPercent, e.Percent)
+				assert.Equal(test.expKind, e.Kind)
+			}
+		})
+	}
+}
+
+func TestExhaustionApplyRevert(t *testing.T) {
+	for _, kind := range []Kind{FileKind, SocketKind} {
+		t.Run(string(kind), func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			start, err := openFDs()
+			require.NoError(err)
+
+			e, err := NewExhaustion(start+50, kind)
+			require.NoError(err)
+			require.NoError(e.Apply(context.Background()))
+
+			current, err := openFDs()
+			require.NoError(err)
+			assert.Equal(start+50, current)
+			assert.Error(e.Apply(context.Background()), "applying twice should error")
+
+			require.NoError(e.Revert())
+			current, err = openFDs()
+			require.NoError(err)
+			assert.Equal(start, current)
+		})
+	}
+}
+
+func TestExhaustionAlreadyOverTarget(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	e, err := NewExhaustion(1, FileKind)
+	require.NoError(err)
+	require.NoError(e.Apply(context.Background()))
+	assert.Len(e.fds, 0)
+	assert.NoError(e.Revert())
+}
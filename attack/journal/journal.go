@@ -0,0 +1,186 @@
+/*
+Package journal records the applied attacks on the node so they can be reverted
+after the node process dies.
+
+Most of the attacks live inside the node process and finish with it, but some of
+them leave state out of the process (filler files, stopped processes, published
+offsets...). These attacks implement attack.Recoverer, the journal registry records
+them before being applied with the state needed to revert them, and removes them
+once they have been reverted. On startup the node recovers the attacks left on the
+journal reverting them before registering on the master.
+*/
+package journal // import "github.com/slok/ragnarok/attack/journal"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const entryExt = ".json"
+
+// Entry is an attack recorded on the journal.
+type Entry struct {
+	ID       string      `json:"id"`              // ID is the identifier of the entry.
+	Kind     string      `json:"kind"`            // Kind is the attack registry identifier.
+	Opts     attack.Opts `json:"opts,omitempty"`  // Opts are the options the attack was created with.
+	State    attack.Opts `json:"state,omitempty"` // State is the recovery state of the applied attack.
+	Recorded time.Time   `json:"recorded"`        // Recorded is when the attack was recorded the first time.
+}
+
+// Journal records the attacks on a directory, one file per attack.
+type Journal struct {
+	dir string
+	mu  sync.Mutex
+	log log.Logger
+}
+
+// New returns a new journal on a directory, the directory will be created if missing.
+func New(dir string, logger log.Logger) (*Journal, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("journal directory can't be empty")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create journal directory: %s", err)
+	}
+
+	if logger == nil {
+		logger = log.Base()
+	}
+	return &Journal{
+		dir: dir,
+		log: logger.WithField("journal", dir),
+	}, nil
+}
+
+// Record writes the entry on the journal, replacing the previous one with the same ID.
+func (j *Journal) Record(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Write on a temporary file and rename it so an entry is never half written.
+	tmp, err := ioutil.TempFile(j.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), j.path(e.ID))
+}
+
+// Remove removes the entry from the journal.
+func (j *Journal) Remove(id string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.Remove(j.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Entries returns the entries of the journal, the last recorded first.
+func (j *Journal) Entries() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	fis, err := ioutil.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), entryExt) {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(j.dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var e Entry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, fmt.Errorf("invalid journal entry %s: %s", fi.Name(), err)
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].Recorded.After(entries[b].Recorded)
+	})
+	return entries, nil
+}
+
+// Recover reverts the attacks left on the journal using new attackers created by
+// the registry, the reverted attacks are removed from the journal. The attacks that
+// can't be reverted are kept so they are retried on the next recovery.
+func (j *Journal) Recover(reg attack.Registry) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+
+	errStr := ""
+	for _, e := range entries {
+		logger := j.log.WithField("attack", e.Kind).WithField("entry", e.ID)
+		if err := recoverEntry(e, reg); err != nil {
+			logger.Errorf("could not recover attack: %s", err)
+			errStr = fmt.Sprintf("%s; %s attack: %s", errStr, e.Kind, err)
+			continue
+		}
+		if err := j.Remove(e.ID); err != nil {
+			errStr = fmt.Sprintf("%s; %s", errStr, err)
+			continue
+		}
+		logger.Infof("attack recovered")
+	}
+
+	if errStr != "" {
+		return fmt.Errorf("error recovering journal attacks: %s", errStr)
+	}
+	return nil
+}
+
+// recoverEntry reverts the attack of an entry.
+func recoverEntry(e Entry, reg attack.Registry) error {
+	a, err := reg.New(e.Kind, e.Opts)
+	if err != nil {
+		return err
+	}
+	r, ok := a.(attack.Recoverer)
+	if !ok {
+		return fmt.Errorf("attack can't be recovered")
+	}
+	return r.Recover(e.State)
+}
+
+// path returns the file of an entry.
+func (j *Journal) path(id string) string {
+	return filepath.Join(j.dir, id+entryExt)
+}
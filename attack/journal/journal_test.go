@@ -0,0 +1,208 @@
+package journal_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/attack/journal"
+	"github.com/slok/ragnarok/log"
+	mattack "github.com/slok/ragnarok/mocks/attack"
+)
+
+// recoverableAttacker is an attacker that can be recovered.
+type recoverableAttacker struct {
+	*mattack.Attacker
+	*mattack.Recoverer
+}
+
+func newJournal(t *testing.T) (*journal.Journal, func()) {
+	dir, err := ioutil.TempDir("", "ragnarok-journal-test")
+	require.NoError(t, err)
+	j, err := journal.New(dir, log.Dummy)
+	require.NoError(t, err)
+	return j, func() { os.RemoveAll(dir) }
+}
+
+func TestJournalRecordAndRemove(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	j, clean := newJournal(t)
+	defer clean()
+
+	e1 := journal.Entry{ID: "e1", Kind: "attack1", Opts: attack.Opts{"size": "1MiB"}}
+	e2 := journal.Entry{ID: "e2", Kind: "attack2"}
+	require.NoError(j.Record(e1))
+	require.NoError(j.Record(e2))
+
+	// Replace the entry.
+	e1.State = attack.Opts{"files": []interface{}{"/tmp/f1"}}
+	require.NoError(j.Record(e1))
+
+	entries, err := j.Entries()
+	require.NoError(err)
+	assert.Len(entries, 2)
+	for _, e := range entries {
+		if e.ID == "e1" {
+			assert.Equal(e1, e)
+		}
+	}
+
+	require.NoError(j.Remove("e1"))
+	require.NoError(j.Remove("missing"))
+	entries, err = j.Entries()
+	require.NoError(err)
+	assert.Equal([]journal.Entry{e2}, entries)
+}
+
+func TestRegistryJournalsRecoverableAttacks(t *testing.T) {
+	tests := []struct {
+		name        string
+		recoverable bool
+		applyErr    bool
+		revertErr   bool
+		revertHangs bool // The revert doesn't finish before its context is cancelled.
+		expApplied  bool // Expect the attack on the journal after applying.
+		expReverted bool // Expect the attack on the journal after reverting.
+	}{
+		{
+			name:        "A recoverable attack should be on the journal while applied.",
+			recoverable: true,
+			expApplied:  true,
+			expReverted: false,
+		},
+		{
+			name:        "A recoverable attack that fails reverting should be kept on the journal.",
+			recoverable: true,
+			revertErr:   true,
+			expApplied:  true,
+			expReverted: true,
+		},
+		{
+			name:        "A recoverable attack with a cancelled revert should be kept on the journal.",
+			recoverable: true,
+			revertHangs: true,
+			expApplied:  true,
+			expReverted: true,
+		},
+		{
+			name:        "A recoverable attack that fails applying should be kept on the journal.",
+			recoverable: true,
+			applyErr:    true,
+			expApplied:  true,
+		},
+		{
+			name:        "An attack that can't be recovered shouldn't be on the journal.",
+			recoverable: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			j, clean := newJournal(t)
+			defer clean()
+
+			var applyErr, revertErr error
+			if test.applyErr {
+				applyErr = errors.New("wanted error")
+			}
+			if test.revertErr {
+				revertErr = errors.New("wanted error")
+			}
+
+			// Mocks.
+			matt := &mattack.Attacker{}
+			matt.On("Apply", mock.Anything).Return(applyErr)
+			releaseC := make(chan struct{})
+			defer close(releaseC)
+			if test.revertHangs {
+				matt.On("Revert").Return(nil).Run(func(mock.Arguments) { <-releaseC })
+			}
+			matt.On("Revert").Return(revertErr)
+			var att attack.Attacker = matt
+			if test.recoverable {
+				mrec := &mattack.Recoverer{}
+				mrec.On("RecoveryState").Return(attack.Opts{"pid": 1})
+				att = &recoverableAttacker{Attacker: matt, Recoverer: mrec}
+			}
+			mreg := &mattack.Registry{}
+			mreg.On("New", "attack1", attack.Opts{"size": 1}).Return(att, nil)
+
+			reg := journal.NewRegistry(mreg, j)
+			a, err := reg.New("attack1", attack.Opts{"size": 1})
+			require.NoError(err)
+
+			assert.Equal(test.applyErr, a.Apply(context.Background()) != nil)
+			entries, err := j.Entries()
+			require.NoError(err)
+			if test.expApplied {
+				require.Len(entries, 1)
+				assert.Equal("attack1", entries[0].Kind)
+				assert.Equal(attack.Opts{"size": float64(1)}, entries[0].Opts)
+				assert.Equal(attack.Opts{"pid": float64(1)}, entries[0].State)
+			} else {
+				assert.Empty(entries)
+			}
+
+			if test.applyErr {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			if test.revertHangs {
+				cancel()
+			}
+			assert.Equal(test.revertErr || test.revertHangs, attack.RevertContext(ctx, a) != nil)
+			cancel()
+			entries, err = j.Entries()
+			require.NoError(err)
+			assert.Equal(test.expReverted, len(entries) == 1)
+		})
+	}
+}
+
+func TestJournalRecover(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	j, clean := newJournal(t)
+	defer clean()
+
+	require.NoError(j.Record(journal.Entry{ID: "ok", Kind: "attack1", State: attack.Opts{"pid": 1}}))
+	require.NoError(j.Record(journal.Entry{ID: "error", Kind: "attack2"}))
+	require.NoError(j.Record(journal.Entry{ID: "missing", Kind: "attack3"}))
+
+	// Mocks.
+	mrec1, mrec2 := &mattack.Recoverer{}, &mattack.Recoverer{}
+	mrec1.On("Recover", attack.Opts{"pid": float64(1)}).Once().Return(nil)
+	mrec2.On("Recover", mock.Anything).Once().Return(errors.New("wanted error"))
+	mreg := &mattack.Registry{}
+	mreg.On("New", "attack1", mock.Anything).Return(&recoverableAttacker{Attacker: &mattack.Attacker{}, Recoverer: mrec1}, nil)
+	mreg.On("New", "attack2", mock.Anything).Return(&recoverableAttacker{Attacker: &mattack.Attacker{}, Recoverer: mrec2}, nil)
+	mreg.On("New", "attack3", mock.Anything).Return(nil, errors.New("wanted error"))
+
+	assert.Error(j.Recover(mreg))
+	mrec1.AssertExpectations(t)
+	mrec2.AssertExpectations(t)
+
+	// The attacks that couldn't be recovered are kept.
+	entries, err := j.Entries()
+	require.NoError(err)
+	ids := []string{}
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	assert.Len(ids, 2)
+	assert.Contains(ids, "error")
+	assert.Contains(ids, "missing")
+}
@@ -0,0 +1,118 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// Registry is an attack registry that records on a journal the attacks created by
+// it that can be recovered.
+type Registry struct {
+	attack.Registry
+	journal *Journal
+}
+
+// NewRegistry returns a new journal registry that creates the attacks using reg.
+func NewRegistry(reg attack.Registry, j *Journal) *Registry {
+	return &Registry{
+		Registry: reg,
+		journal:  j,
+	}
+}
+
+// New satisfies attack.Registry interface. The attacks that can't be recovered are
+// returned as they are, they don't leave anything to revert when the process dies.
+func (r *Registry) New(id string, opts attack.Opts) (attack.Attacker, error) {
+	a, err := r.Registry.New(id, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := a.(attack.Recoverer)
+	if !ok {
+		return a, nil
+	}
+
+	ja := &journaledAttack{
+		Attacker:  a,
+		recoverer: rec,
+		journal:   r.journal,
+		entry: Entry{
+			ID:   uuid.New().String(),
+			Kind: id,
+			Opts: opts,
+		},
+	}
+
+	// Don't hide the verification of the attack.
+	if v, ok := a.(attack.Verifier); ok {
+		return &verifierAttack{journaledAttack: ja, Verifier: v}, nil
+	}
+	return ja, nil
+}
+
+// journaledAttack is an attack that is recorded on the journal while applied.
+type journaledAttack struct {
+	attack.Attacker
+	recoverer attack.Recoverer
+	journal   *Journal
+	entry     Entry
+	mu        sync.Mutex
+}
+
+// Apply satisfies attack.Attacker interface. The attack is recorded before being
+// applied so an attack partially applied when the process dies is recovered too,
+// and it's kept if the apply fails because it could be partially applied.
+func (a *journaledAttack) Apply(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.entry.Recorded.IsZero() {
+		a.entry.Recorded = time.Now().UTC()
+	}
+	a.entry.State = nil
+	if err := a.journal.Record(a.entry); err != nil {
+		return fmt.Errorf("could not record attack on journal: %s", err)
+	}
+
+	err := a.Attacker.Apply(ctx)
+
+	a.entry.State = a.recoverer.RecoveryState()
+	if jErr := a.journal.Record(a.entry); jErr != nil {
+		a.journal.log.Errorf("could not record attack recovery state on journal: %s", jErr)
+	}
+	return err
+}
+
+// Revert satisfies attack.Attacker interface. The attack is kept on the journal if
+// it can't be reverted.
+func (a *journaledAttack) Revert() error {
+	return a.RevertContext(context.Background())
+}
+
+// RevertContext satisfies attack.ContextReverter interface. The attack is kept on
+// the journal if it can't be reverted.
+func (a *journaledAttack) RevertContext(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := attack.RevertContext(ctx, a.Attacker); err != nil {
+		return err
+	}
+	if err := a.journal.Remove(a.entry.ID); err != nil {
+		return fmt.Errorf("could not remove attack from journal: %s", err)
+	}
+	return nil
+}
+
+// verifierAttack is a journaled attack that can be verified.
+type verifierAttack struct {
+	*journaledAttack
+	attack.Verifier
+}
@@ -5,7 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/slok/ragnarok/attack"
 	"github.com/slok/ragnarok/log"
@@ -34,11 +38,19 @@ const (
 	sizeKey = "size"
 )
 
+// statmPath is the path of the memory usage of the process.
+var statmPath = "/proc/self/statm"
+
+// allocSchema is the schema of the attack options.
+var allocSchema = attack.Schema{
+	{Name: sizeKey, Type: attack.SizeOptType, Required: true, Description: "The bytes that will be allocated."},
+}
+
 // Register the creator of the attack
 func init() {
-	attack.Register(AllocID, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+	attack.Register(AllocID, attack.NewSchemaCreator("Allocates memory.", allocSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
 		return NewMemAllocationOpts(o)
-	}))
+	})))
 }
 
 // MemAllocation failer will apply a failure allocating memory.
@@ -102,3 +114,31 @@ func (m *MemAllocation) Revert() error {
 	m.done = false
 	return nil
 }
+
+// Verify will check the memory is allocated and resident.
+func (m *MemAllocation) Verify(ctx context.Context) error {
+	if !m.done {
+		return errors.New("memory allocation not applied")
+	}
+	if uint64(m.b.Len()) != m.Size {
+		return fmt.Errorf("%d bytes allocated, expected %d", m.b.Len(), m.Size)
+	}
+
+	// The second field of statm is the resident set size in pages.
+	b, err := ioutil.ReadFile(statmPath)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 2 {
+		return fmt.Errorf("invalid %s format", statmPath)
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s format: %s", statmPath, err)
+	}
+	if rss := pages * uint64(os.Getpagesize()); rss < m.Size {
+		return fmt.Errorf("%d bytes of resident memory, less than the %d bytes allocated", rss, m.Size)
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package memory
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/slok/ragnarok/attack"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreationWithOpts(t *testing.T) {
@@ -69,3 +73,29 @@ func TestCreationWithOpts(t *testing.T) {
 		}
 	}
 }
+
+func TestVerify(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m, err := NewMemAllocation(1 * MiB)
+	require.NoError(err)
+	assert.Error(m.Verify(context.Background()), "not applied allocation shouldn't be verified")
+
+	require.NoError(m.Apply(context.Background()))
+	assert.NoError(m.Verify(context.Background()))
+
+	// Fake a process with less resident memory than the allocated.
+	f, err := ioutil.TempFile("", "ragnarok-statm")
+	require.NoError(err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("4096 10 5 1 0 100 0\n")
+	require.NoError(err)
+	f.Close()
+	defer func(p string) { statmPath = p }(statmPath)
+	statmPath = f.Name()
+	assert.Error(m.Verify(context.Background()), "allocation not resident shouldn't be verified")
+
+	require.NoError(m.Revert())
+	assert.Error(m.Verify(context.Background()), "reverted allocation shouldn't be verified")
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/clock"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// LeakID is the identifier of the attack
+	LeakID = "memory_leak"
+
+	// Options
+	rateKey     = "rate"
+	intervalKey = "interval"
+
+	defaultLeakInterval = 1 * time.Second
+)
+
+// leakSchema is the schema of the attack options.
+var leakSchema = attack.Schema{
+	{Name: rateKey, Type: attack.SizeOptType, Required: true, Description: "The bytes allocated on every interval."},
+	{Name: intervalKey, Type: attack.DurationOptType, Default: defaultLeakInterval.String(), Description: "The interval between allocations."},
+	{Name: sizeKey, Type: attack.SizeOptType, Required: true, Description: "The maximum bytes that will be allocated."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(LeakID, attack.NewSchemaCreator("Leaks memory at a rate until reverted.", leakSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewMemLeakOpts(o)
+	})))
+}
+
+// MemLeak failer will apply a failure allocating memory gradually, it will
+// grow the allocation on every interval until the size is reached.
+type MemLeak struct {
+	Rate     uint64        // The bytes allocated on every interval.
+	Interval time.Duration // The interval between allocations.
+	Size     uint64        // The maximum bytes that will be allocated.
+
+	chunks    [][]byte       // The allocated memory.
+	allocated uint64         // The number of bytes allocated.
+	stopC     chan struct{}  // Channel used to stop the growing.
+	wg        sync.WaitGroup // Used to wait until the growing has finished.
+	running   bool           // Flag that marks the leak was already applied.
+	mu        sync.Mutex
+	clock     clock.Clock // Clock.
+	log       log.Logger  // Logger.
+}
+
+// NewMemLeakOpts returns a new memory leak failer using options. If the
+// interval is missing it will use 1s.
+func NewMemLeakOpts(opts attack.Opts) (*MemLeak, error) {
+	rate, ok := opts[rateKey].(int)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", rateKey, opts[rateKey])
+	}
+
+	size, ok := opts[sizeKey].(int)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", sizeKey, opts[sizeKey])
+	}
+
+	interval := defaultLeakInterval
+	if i, ok := opts[intervalKey]; ok {
+		is, ok := i.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", intervalKey, i)
+		}
+		var err error
+		if interval, err = time.ParseDuration(is); err != nil {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value: %s", intervalKey, i, err)
+		}
+	}
+
+	if rate <= 0 || size <= 0 {
+		return nil, fmt.Errorf("rate and size can't be 0 or less")
+	}
+
+	return NewMemLeak(uint64(rate), interval, uint64(size))
+}
+
+// NewMemLeak returns a new memory leak failer.
+func NewMemLeak(rate uint64, interval time.Duration, size uint64) (*MemLeak, error) {
+	if rate == 0 {
+		return nil, fmt.Errorf("rate can't be 0")
+	}
+
+	if size == 0 {
+		return nil, fmt.Errorf("size can't be 0")
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval can't be 0 or less")
+	}
+
+	return &MemLeak{
+		Rate:     rate,
+		Interval: interval,
+		Size:     size,
+		clock:    clock.Base(),
+		log:      log.Base(),
+	}, nil
+}
+
+// Apply will start allocating memory on every interval, the allocation will
+// grow until the size is reached, the context is cancelled or the attack is
+// reverted.
+func (m *MemLeak) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		return errors.New("memory leak already applied")
+	}
+
+	m.stopC = make(chan struct{})
+	m.wg.Add(1)
+	go m.grow(ctx, m.stopC)
+	m.running = true
+	m.log.With("rate", m.Rate).With("interval", m.Interval).With("MiB", m.Size/MiB).Infof("memory leak started")
+	return nil
+}
+
+// grow will allocate memory on every interval until stopped or the size is reached.
+func (m *MemLeak) grow(ctx context.Context, stopC chan struct{}) {
+	defer m.wg.Done()
+
+	t := m.clock.NewTicker(m.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopC:
+			return
+		case <-t.C:
+		}
+
+		if full := m.leak(); full {
+			m.log.With("MiB", m.Size/MiB).Infof("memory leak reached its size")
+			return
+		}
+	}
+}
+
+// leak allocates the next chunk of memory, returns true if the size has been reached.
+func (m *MemLeak) leak() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := m.Rate
+	if m.allocated+n > m.Size {
+		n = m.Size - m.allocated
+	}
+	b := make([]byte, n)
+	// Touch every page so the memory is resident and not only reserved.
+	for i := 0; i < len(b); i += os.Getpagesize() {
+		b[i] = 1
+	}
+	m.chunks = append(m.chunks, b)
+	m.allocated += n
+
+	return m.allocated >= m.Size
+}
+
+// Allocated returns the number of bytes allocated by the leak.
+func (m *MemLeak) Allocated() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.allocated
+}
+
+// Revert will stop the leak and clean the memory usage letting the GC do its work.
+func (m *MemLeak) Revert() error {
+	m.mu.Lock()
+	if m.running {
+		close(m.stopC)
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chunks = nil
+	m.allocated = 0
+	m.running = false
+
+	// Return memory to the OS.
+	debug.FreeOSMemory()
+	m.log.With("MiB", m.Size/MiB).Infof("reverted memory leak")
+	return nil
+}
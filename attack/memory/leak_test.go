@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+	mclock "github.com/slok/ragnarok/mocks/clock"
+)
+
+// waitAllocated waits until the leak has allocated the expected bytes or the
+// timeout is reached.
+func waitAllocated(m *MemLeak, exp uint64) uint64 {
+	got := m.Allocated()
+	for start := time.Now(); got != exp && time.Since(start) < 1*time.Second; got = m.Allocated() {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return got
+}
+
+func TestMemLeakCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        attack.Opts
+		expRate     uint64
+		expInterval time.Duration
+		expSize     uint64
+		expErr      bool
+	}{
+		{
+			name:        "Creating a leak with all the options should use them.",
+			opts:        attack.Opts{"rate": 10 * MiB, "interval": "500ms", "size": 100 * MiB},
+			expRate:     10 * MiB,
+			expInterval: 500 * time.Millisecond,
+			expSize:     100 * MiB,
+		},
+		{
+			name:        "Creating a leak without interval should use the default.",
+			opts:        attack.Opts{"rate": 10 * MiB, "size": 100 * MiB},
+			expRate:     10 * MiB,
+			expInterval: time.Second,
+			expSize:     100 * MiB,
+		},
+		{
+			name:   "Creating a leak without rate should error.",
+			opts:   attack.Opts{"size": 100 * MiB},
+			expErr: true,
+		},
+		{
+			name:   "Creating a leak without size should error.",
+			opts:   attack.Opts{"rate": 10 * MiB},
+			expErr: true,
+		},
+		{
+			name:   "Creating a leak with a negative rate should error.",
+			opts:   attack.Opts{"rate": -1, "size": 100 * MiB},
+			expErr: true,
+		},
+		{
+			name:   "Creating a leak with an invalid interval should error.",
+			opts:   attack.Opts{"rate": 10 * MiB, "size": 100 * MiB, "interval": "wrong"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a leak with a 0 interval should error.",
+			opts:   attack.Opts{"rate": 10 * MiB, "size": 100 * MiB, "interval": "0s"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			m, err := NewMemLeakOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expRate, m.Rate)
+				assert.Equal(test.expInterval, m.Interval)
+				assert.Equal(test.expSize, m.Size)
+			}
+		})
+	}
+}
+
+func TestMemLeakGrowsUntilSize(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tC := make(chan time.Time)
+	mc := &mclock.Clock{}
+	mc.On("NewTicker", mock.Anything).Return(&time.Ticker{C: tC})
+
+	m, err := NewMemLeak(4*KiB, time.Second, 10*KiB)
+	require.NoError(err)
+	m.clock = mc
+
+	require.NoError(m.Apply(context.Background()))
+	assert.Error(m.Apply(context.Background()), "applying twice should error")
+	assert.EqualValues(0, m.Allocated())
+
+	// Grow on every tick until the size is reached.
+	tC <- time.Now()
+	assert.EqualValues(4*KiB, waitAllocated(m, 4*KiB))
+	tC <- time.Now()
+	assert.EqualValues(8*KiB, waitAllocated(m, 8*KiB))
+	tC <- time.Now()
+	assert.EqualValues(10*KiB, waitAllocated(m, 10*KiB))
+
+	// Once the size is reached it shouldn't grow anymore.
+	select {
+	case tC <- time.Now():
+		assert.Fail("leak shouldn't be growing after reaching the size")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(m.Revert())
+	assert.EqualValues(0, m.Allocated())
+	assert.Nil(m.chunks)
+
+	// Should be able to be applied again after a revert.
+	require.NoError(m.Apply(context.Background()))
+	assert.NoError(m.Revert())
+}
+
+func TestMemLeakStopsOnContextCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	tC := make(chan time.Time)
+	mc := &mclock.Clock{}
+	mc.On("NewTicker", mock.Anything).Return(&time.Ticker{C: tC})
+
+	m, err := NewMemLeak(4*KiB, time.Second, 100*KiB)
+	require.NoError(err)
+	m.clock = mc
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(m.Apply(ctx))
+	tC <- time.Now()
+	assert.EqualValues(4*KiB, waitAllocated(m, 4*KiB))
+	cancel()
+
+	// Should stop growing but keep the memory until reverted.
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-time.After(1 * time.Second):
+		require.Fail("leak didn't stop after the context cancellation")
+	case <-done:
+	}
+	assert.EqualValues(4*KiB, m.Allocated())
+
+	require.NoError(m.Revert())
+	assert.EqualValues(0, m.Allocated())
+}
@@ -0,0 +1,468 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+// DNSFaultAction is the fault applied to the queries that match a rule.
+type DNSFaultAction string
+
+const (
+	// NXDomainDNSFaultAction will respond that the domain doesn't exist.
+	NXDomainDNSFaultAction DNSFaultAction = "nxdomain"
+	// ServFailDNSFaultAction will respond with a server failure.
+	ServFailDNSFaultAction DNSFaultAction = "servfail"
+	// TimeoutDNSFaultAction will not respond.
+	TimeoutDNSFaultAction DNSFaultAction = "timeout"
+	// WrongDNSFaultAction will respond with a wrong address.
+	WrongDNSFaultAction DNSFaultAction = "wrong"
+)
+
+const (
+	// DNSFaultID is the identifier of the attack
+	DNSFaultID = "dns_fault"
+
+	// Options
+	rulesKey   = "rules"
+	patternKey = "pattern"
+	actionKey  = "action"
+	answerKey  = "answer"
+
+	defaultDNSFaultAnswer = "127.0.0.1"
+	dnsFaultAnswerTTL     = 60
+	dnsUpstreamTimeout    = 5 * time.Second
+	maxDNSMessageSize     = 65535
+)
+
+// dnsFaultSchema is the schema of the attack options.
+var dnsFaultSchema = attack.Schema{
+	{Name: listenKey, Type: attack.StringOptType, Required: true, Description: "The address where the resolver will listen (UDP and TCP)."},
+	{Name: upstreamKey, Type: attack.StringOptType, Required: true, Description: "The address of the resolver where the queries without faults will be forwarded."},
+	{Name: rulesKey, Type: attack.ListOptType, Required: true, Description: "The rules of the faults, objects with pattern, action (nxdomain, servfail, timeout or wrong) and answer."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(DNSFaultID, attack.NewSchemaCreator("Serves DNS injecting faults on the queries that match the rules.", dnsFaultSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewDNSFaultOpts(o)
+	})))
+}
+
+// DNSFaultRule is a fault that will be applied to the queries of the domains
+// that match the pattern.
+type DNSFaultRule struct {
+	Pattern string         // The glob pattern of the domains ("*.example.com").
+	Action  DNSFaultAction // The fault applied to the matching queries.
+	Answer  string         // The address answered by the wrong action.
+}
+
+// DNSFaultConfig is the configuration of the DNS fault attack.
+type DNSFaultConfig struct {
+	ListenAddress   string         // The address where the resolver will listen (UDP and TCP).
+	UpstreamAddress string         // The address of the resolver where the queries without faults will be forwarded.
+	Rules           []DNSFaultRule // The rules of the faults, the first matching rule will be applied.
+}
+
+// DNSFault failer will apply a failure running a DNS resolver that forwards the
+// queries to an upstream resolver and injects faults on the queries of the
+// domains that match the rules.
+type DNSFault struct {
+	Config DNSFaultConfig
+
+	packetConn net.PacketConn
+	listener   net.Listener
+	stopC      chan struct{}         // Channel closed when the resolver is shutdown.
+	conns      map[net.Conn]struct{} // The active TCP connections.
+	wg         sync.WaitGroup        // Used to wait until all the queries have finished.
+	mu         sync.Mutex
+	log        log.Logger // Logger.
+}
+
+// NewDNSFaultOpts returns a new DNS fault failer using options. The rules are
+// a list of objects with pattern, action and the optional answer.
+func NewDNSFaultOpts(opts attack.Opts) (*DNSFault, error) {
+	cfg := DNSFaultConfig{}
+	var ok bool
+	if cfg.ListenAddress, ok = opts[listenKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", listenKey, opts[listenKey])
+	}
+	if cfg.UpstreamAddress, ok = opts[upstreamKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", upstreamKey, opts[upstreamKey])
+	}
+
+	var rules []map[string]interface{}
+	switch l := opts[rulesKey].(type) {
+	case []map[string]interface{}:
+		rules = l
+	case []interface{}:
+		for _, e := range l {
+			r, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid '%s' option with '%v' value", rulesKey, opts[rulesKey])
+			}
+			rules = append(rules, r)
+		}
+	default:
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", rulesKey, opts[rulesKey])
+	}
+
+	for _, r := range rules {
+		rule := DNSFaultRule{Answer: defaultDNSFaultAnswer}
+		if rule.Pattern, ok = r[patternKey].(string); !ok {
+			return nil, fmt.Errorf("invalid rule '%s' with '%v' value", patternKey, r[patternKey])
+		}
+		a, ok := r[actionKey].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid rule '%s' with '%v' value", actionKey, r[actionKey])
+		}
+		rule.Action = DNSFaultAction(a)
+		if v, ok := r[answerKey]; ok {
+			if rule.Answer, ok = v.(string); !ok {
+				return nil, fmt.Errorf("invalid rule '%s' with '%v' value", answerKey, v)
+			}
+		}
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+
+	return NewDNSFault(cfg)
+}
+
+// NewDNSFault returns a new DNS fault failer.
+func NewDNSFault(cfg DNSFaultConfig) (*DNSFault, error) {
+	if cfg.ListenAddress == "" {
+		return nil, fmt.Errorf("listen address can't be empty")
+	}
+	if cfg.UpstreamAddress == "" {
+		return nil, fmt.Errorf("upstream address can't be empty")
+	}
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("rules can't be empty")
+	}
+
+	for i, r := range cfg.Rules {
+		if _, err := path.Match(r.Pattern, ""); err != nil || r.Pattern == "" {
+			return nil, fmt.Errorf("invalid rule pattern '%s'", r.Pattern)
+		}
+		switch r.Action {
+		case NXDomainDNSFaultAction, ServFailDNSFaultAction, TimeoutDNSFaultAction:
+		case WrongDNSFaultAction:
+			if net.ParseIP(r.Answer) == nil {
+				return nil, fmt.Errorf("invalid rule answer '%s'", r.Answer)
+			}
+		default:
+			return nil, fmt.Errorf("rule action needs to be %s, %s, %s or %s", NXDomainDNSFaultAction, ServFailDNSFaultAction, TimeoutDNSFaultAction, WrongDNSFaultAction)
+		}
+		cfg.Rules[i].Pattern = strings.ToLower(strings.TrimSuffix(r.Pattern, "."))
+	}
+
+	return &DNSFault{
+		Config: cfg,
+		conns:  map[net.Conn]struct{}{},
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will start the resolver listening on UDP and TCP, the resolver will run
+// until the context is cancelled or the attack is reverted.
+func (d *DNSFault) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.packetConn != nil {
+		return errors.New("dns fault already applied")
+	}
+
+	pc, err := net.ListenPacket("udp", d.Config.ListenAddress)
+	if err != nil {
+		return err
+	}
+	// Listen TCP on the same port as UDP in case of a random port.
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		pc.Close()
+		return err
+	}
+	d.packetConn = pc
+	d.listener = l
+	d.stopC = make(chan struct{})
+
+	d.wg.Add(2)
+	go d.serveUDP(pc)
+	go d.serveTCP(l)
+
+	// Stop resolving when the context is done.
+	go func(stopC chan struct{}) {
+		select {
+		case <-stopC:
+		case <-ctx.Done():
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			if d.packetConn == pc {
+				d.shutdown()
+			}
+		}
+	}(d.stopC)
+
+	d.log.With("listen", pc.LocalAddr()).With("upstream", d.Config.UpstreamAddress).Infof("dns fault resolver started")
+	return nil
+}
+
+// Addr returns the address where the resolver is listening, nil if not listening.
+func (d *DNSFault) Addr() net.Addr {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.packetConn == nil {
+		return nil
+	}
+	return d.packetConn.LocalAddr()
+}
+
+// serveUDP answers the UDP queries until the connection is closed.
+func (d *DNSFault) serveUDP(pc net.PacketConn) {
+	defer d.wg.Done()
+	for {
+		b := make([]byte, maxDNSMessageSize)
+		n, addr, err := pc.ReadFrom(b)
+		if err != nil {
+			return
+		}
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			if resp := d.resolve("udp", b[:n]); resp != nil {
+				pc.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+// serveTCP accepts the TCP connections until the listener is closed.
+func (d *DNSFault) serveTCP(l net.Listener) {
+	defer d.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		d.mu.Lock()
+		// Closed while accepting.
+		if d.listener != l {
+			d.mu.Unlock()
+			conn.Close()
+			return
+		}
+		d.conns[conn] = struct{}{}
+		d.wg.Add(1)
+		d.mu.Unlock()
+		go d.handleTCP(conn)
+	}
+}
+
+// handleTCP answers the queries of a TCP connection until it's closed.
+func (d *DNSFault) handleTCP(conn net.Conn) {
+	defer d.wg.Done()
+	defer func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		conn.Close()
+		delete(d.conns, conn)
+	}()
+
+	for {
+		msg, err := readTCPDNSMessage(conn)
+		if err != nil {
+			return
+		}
+		// On timeouts the client will be waiting until it gives up.
+		if resp := d.resolve("tcp", msg); resp != nil {
+			if err := writeTCPDNSMessage(conn, resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// resolve returns the response of a query, nil if there is no response.
+func (d *DNSFault) resolve(network string, msg []byte) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return nil
+	}
+	q, err := p.Question()
+	if err != nil {
+		return d.forward(network, msg)
+	}
+
+	rule, ok := d.match(q.Name.String())
+	if !ok {
+		return d.forward(network, msg)
+	}
+
+	resp := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 h.ID,
+			Response:           true,
+			OpCode:             h.OpCode,
+			RecursionDesired:   h.RecursionDesired,
+			RecursionAvailable: true,
+		},
+		Questions: []dnsmessage.Question{q},
+	}
+	switch rule.Action {
+	case TimeoutDNSFaultAction:
+		return nil
+	case NXDomainDNSFaultAction:
+		resp.RCode = dnsmessage.RCodeNameError
+	case ServFailDNSFaultAction:
+		resp.RCode = dnsmessage.RCodeServerFailure
+	case WrongDNSFaultAction:
+		resp.RCode = dnsmessage.RCodeSuccess
+		if answer, ok := wrongAnswer(q, rule.Answer); ok {
+			resp.Answers = []dnsmessage.Resource{answer}
+		}
+	}
+
+	b, err := resp.Pack()
+	if err != nil {
+		d.log.Errorf("error packing dns response: %s", err)
+		return nil
+	}
+	return b
+}
+
+// match returns the first rule that matches the domain.
+func (d *DNSFault) match(domain string) (DNSFaultRule, bool) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, r := range d.Config.Rules {
+		if ok, _ := path.Match(r.Pattern, domain); ok {
+			return r, true
+		}
+	}
+	return DNSFaultRule{}, false
+}
+
+// wrongAnswer returns the wrong answer of a question, only A and AAAA questions
+// with an answer of the same family have answer.
+func wrongAnswer(q dnsmessage.Question, answer string) (dnsmessage.Resource, bool) {
+	ip := net.ParseIP(answer)
+	hdr := dnsmessage.ResourceHeader{
+		Name:  q.Name,
+		Class: q.Class,
+		TTL:   dnsFaultAnswerTTL,
+	}
+
+	switch {
+	case q.Type == dnsmessage.TypeA && ip.To4() != nil:
+		r := &dnsmessage.AResource{}
+		copy(r.A[:], ip.To4())
+		return dnsmessage.Resource{Header: hdr, Body: r}, true
+	case q.Type == dnsmessage.TypeAAAA && ip.To4() == nil:
+		r := &dnsmessage.AAAAResource{}
+		copy(r.AAAA[:], ip.To16())
+		return dnsmessage.Resource{Header: hdr, Body: r}, true
+	}
+	return dnsmessage.Resource{}, false
+}
+
+// forward sends the query to the upstream and returns its response.
+func (d *DNSFault) forward(network string, msg []byte) []byte {
+	conn, err := net.DialTimeout(network, d.Config.UpstreamAddress, dnsUpstreamTimeout)
+	if err != nil {
+		d.log.Errorf("error connecting to upstream: %s", err)
+		return nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dnsUpstreamTimeout))
+
+	if network == "tcp" {
+		if err := writeTCPDNSMessage(conn, msg); err != nil {
+			return nil
+		}
+		resp, err := readTCPDNSMessage(conn)
+		if err != nil {
+			return nil
+		}
+		return resp
+	}
+
+	if _, err := conn.Write(msg); err != nil {
+		return nil
+	}
+	b := make([]byte, maxDNSMessageSize)
+	n, err := conn.Read(b)
+	if err != nil {
+		return nil
+	}
+	return b[:n]
+}
+
+// readTCPDNSMessage reads a DNS message prefixed with its length.
+func readTCPDNSMessage(r io.Reader) ([]byte, error) {
+	var l uint16
+	if err := binary.Read(r, binary.BigEndian, &l); err != nil {
+		return nil, err
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// writeTCPDNSMessage writes a DNS message prefixed with its length.
+func writeTCPDNSMessage(w io.Writer, msg []byte) error {
+	b := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(b, uint16(len(msg)))
+	copy(b[2:], msg)
+	_, err := w.Write(b)
+	return err
+}
+
+// shutdown closes the listeners and all the active connections.
+func (d *DNSFault) shutdown() {
+	d.packetConn.Close()
+	d.listener.Close()
+	d.packetConn = nil
+	d.listener = nil
+	close(d.stopC)
+	for c := range d.conns {
+		c.Close()
+	}
+}
+
+// Revert will stop the resolver closing the listeners and all the active connections.
+func (d *DNSFault) Revert() error {
+	d.mu.Lock()
+	if d.packetConn != nil {
+		d.shutdown()
+	}
+	d.mu.Unlock()
+
+	// Wait until all the queries have been drained.
+	d.wg.Wait()
+	d.log.With("listen", d.Config.ListenAddress).Infof("reverted dns fault resolver")
+	return nil
+}
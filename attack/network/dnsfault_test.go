@@ -0,0 +1,380 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+var testDNSUpstreamIP = [4]byte{10, 0, 0, 1}
+
+// startDNSUpstream starts a UDP and TCP DNS server that will answer all the A
+// queries with the same address.
+func startDNSUpstream(t *testing.T) (net.PacketConn, net.Listener) {
+	answer := func(msg []byte) []byte {
+		var m dnsmessage.Message
+		if err := m.Unpack(msg); err != nil || len(m.Questions) == 0 {
+			return nil
+		}
+		m.Response = true
+		q := m.Questions[0]
+		m.Answers = []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: q.Name, Class: q.Class, TTL: 60},
+			Body:   &dnsmessage.AResource{A: testDNSUpstreamIP},
+		}}
+		b, _ := m.Pack()
+		return b
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		b := make([]byte, maxDNSMessageSize)
+		for {
+			n, addr, err := pc.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(answer(b[:n]), addr)
+		}
+	}()
+
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	require.NoError(t, err)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				for {
+					msg, err := readTCPDNSMessage(c)
+					if err != nil {
+						return
+					}
+					writeTCPDNSMessage(c, answer(msg))
+				}
+			}()
+		}
+	}()
+	return pc, l
+}
+
+// queryDNS sends an A query to the DNS server.
+func queryDNS(network, addr, domain string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	name, err := dnsmessage.NewName(domain)
+	if err != nil {
+		return nil, err
+	}
+	q := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 1234, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	msg, err := q.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(300 * time.Millisecond))
+
+	var resp []byte
+	if network == "tcp" {
+		if err := writeTCPDNSMessage(conn, msg); err != nil {
+			return nil, err
+		}
+		if resp, err = readTCPDNSMessage(conn); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(msg); err != nil {
+			return nil, err
+		}
+		b := make([]byte, maxDNSMessageSize)
+		n, err := conn.Read(b)
+		if err != nil {
+			return nil, err
+		}
+		resp = b[:n]
+	}
+
+	var m dnsmessage.Message
+	if err := m.Unpack(resp); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func TestDNSFaultCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg DNSFaultConfig
+		expErr bool
+	}{
+		{
+			name: "Creating a DNS fault with all the options should use them.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:5353",
+				"upstream": "8.8.8.8:53",
+				"rules": []interface{}{
+					map[string]interface{}{"pattern": "*.Example.com.", "action": "nxdomain"},
+					map[string]interface{}{"pattern": "db.local", "action": "wrong", "answer": "::1"},
+				},
+			},
+			expCfg: DNSFaultConfig{
+				ListenAddress:   "127.0.0.1:5353",
+				UpstreamAddress: "8.8.8.8:53",
+				Rules: []DNSFaultRule{
+					{Pattern: "*.example.com", Action: NXDomainDNSFaultAction, Answer: "127.0.0.1"},
+					{Pattern: "db.local", Action: WrongDNSFaultAction, Answer: "::1"},
+				},
+			},
+		},
+		{
+			name: "Creating a DNS fault without rules should error.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:5353",
+				"upstream": "8.8.8.8:53",
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a DNS fault without upstream should error.",
+			opts: attack.Opts{
+				"listen": "127.0.0.1:5353",
+				"rules":  []interface{}{map[string]interface{}{"pattern": "*", "action": "servfail"}},
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a DNS fault with an invalid action should error.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:5353",
+				"upstream": "8.8.8.8:53",
+				"rules":    []interface{}{map[string]interface{}{"pattern": "*", "action": "refuse"}},
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a DNS fault with an invalid pattern should error.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:5353",
+				"upstream": "8.8.8.8:53",
+				"rules":    []interface{}{map[string]interface{}{"pattern": "[", "action": "servfail"}},
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a DNS fault with an invalid wrong answer should error.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:5353",
+				"upstream": "8.8.8.8:53",
+				"rules":    []interface{}{map[string]interface{}{"pattern": "*", "action": "wrong", "answer": "localhost"}},
+			},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			d, err := NewDNSFaultOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, d.Config)
+			}
+		})
+	}
+}
+
+func TestDNSFaultApply(t *testing.T) {
+	rules := []DNSFaultRule{
+		{Pattern: "*.missing.com", Action: NXDomainDNSFaultAction},
+		{Pattern: "broken.com", Action: ServFailDNSFaultAction},
+		{Pattern: "slow.com", Action: TimeoutDNSFaultAction},
+		{Pattern: "wrong.com", Action: WrongDNSFaultAction, Answer: "192.168.1.1"},
+		{Pattern: "wrong6.com", Action: WrongDNSFaultAction, Answer: "::1"},
+	}
+
+	tests := []struct {
+		name       string
+		domain     string
+		qtype      dnsmessage.Type
+		expTimeout bool
+		expRCode   dnsmessage.RCode
+		expA       *[4]byte
+		expAAAA    *[16]byte
+	}{
+		{
+			name:     "A not matching domain should be forwarded to the upstream.",
+			domain:   "ok.com.",
+			qtype:    dnsmessage.TypeA,
+			expRCode: dnsmessage.RCodeSuccess,
+			expA:     &testDNSUpstreamIP,
+		},
+		{
+			name:     "A nxdomain matching domain should respond with a name error.",
+			domain:   "api.MISSING.com.",
+			qtype:    dnsmessage.TypeA,
+			expRCode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:     "A servfail matching domain should respond with a server failure.",
+			domain:   "broken.com.",
+			qtype:    dnsmessage.TypeA,
+			expRCode: dnsmessage.RCodeServerFailure,
+		},
+		{
+			name:       "A timeout matching domain should not respond.",
+			domain:     "slow.com.",
+			qtype:      dnsmessage.TypeA,
+			expTimeout: true,
+		},
+		{
+			name:     "A wrong matching domain should respond with the wrong address.",
+			domain:   "wrong.com.",
+			qtype:    dnsmessage.TypeA,
+			expRCode: dnsmessage.RCodeSuccess,
+			expA:     &[4]byte{192, 168, 1, 1},
+		},
+		{
+			name:     "A wrong matching domain should respond with the wrong IPv6 address.",
+			domain:   "wrong6.com.",
+			qtype:    dnsmessage.TypeAAAA,
+			expRCode: dnsmessage.RCodeSuccess,
+			expAAAA:  &[16]byte{15: 1},
+		},
+		{
+			name:     "A wrong matching domain of other family should respond without answers.",
+			domain:   "wrong.com.",
+			qtype:    dnsmessage.TypeAAAA,
+			expRCode: dnsmessage.RCodeSuccess,
+		},
+	}
+
+	upc, ul := startDNSUpstream(t)
+	defer upc.Close()
+	defer ul.Close()
+
+	d, err := NewDNSFault(DNSFaultConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: upc.LocalAddr().String(),
+		Rules:           rules,
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Apply(context.Background()))
+	defer d.Revert()
+
+	for _, network := range []string{"udp", "tcp"} {
+		for _, test := range tests {
+			t.Run(network+" "+test.name, func(t *testing.T) {
+				assert := assert.New(t)
+				require := require.New(t)
+
+				m, err := queryDNS(network, d.Addr().String(), test.domain, test.qtype)
+				if test.expTimeout {
+					require.Error(err)
+					nerr, ok := err.(net.Error)
+					assert.True(ok && nerr.Timeout())
+					return
+				}
+				require.NoError(err)
+
+				assert.True(m.Response)
+				assert.EqualValues(1234, m.ID)
+				assert.Equal(test.expRCode, m.RCode)
+				switch {
+				case test.expA != nil:
+					require.Len(m.Answers, 1)
+					assert.Equal(&dnsmessage.AResource{A: *test.expA}, m.Answers[0].Body)
+				case test.expAAAA != nil:
+					require.Len(m.Answers, 1)
+					assert.Equal(&dnsmessage.AAAAResource{AAAA: *test.expAAAA}, m.Answers[0].Body)
+				default:
+					assert.Len(m.Answers, 0)
+				}
+			})
+		}
+	}
+}
+
+func TestDNSFaultApplyTwice(t *testing.T) {
+	require := require.New(t)
+
+	d, err := NewDNSFault(DNSFaultConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: "127.0.0.1:53",
+		Rules:           []DNSFaultRule{{Pattern: "*", Action: ServFailDNSFaultAction}},
+	})
+	require.NoError(err)
+	require.NoError(d.Apply(context.Background()))
+	defer d.Revert()
+	require.Error(d.Apply(context.Background()))
+}
+
+func TestDNSFaultRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	d, err := NewDNSFault(DNSFaultConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: "127.0.0.1:53",
+		Rules:           []DNSFaultRule{{Pattern: "*", Action: ServFailDNSFaultAction}},
+	})
+	require.NoError(err)
+	require.NoError(d.Apply(context.Background()))
+	addr := d.Addr().String()
+
+	require.NoError(d.Revert())
+	assert.Nil(d.Addr())
+	_, err = queryDNS("tcp", addr, "test.com.", dnsmessage.TypeA)
+	assert.Error(err)
+
+	// The ports should be released.
+	pc, err := net.ListenPacket("udp", addr)
+	if assert.NoError(err) {
+		pc.Close()
+	}
+
+	// Should be able to apply again after revert.
+	require.NoError(d.Apply(context.Background()))
+	assert.NoError(d.Revert())
+}
+
+func TestDNSFaultContextCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	d, err := NewDNSFault(DNSFaultConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: "127.0.0.1:53",
+		Rules:           []DNSFaultRule{{Pattern: "*", Action: ServFailDNSFaultAction}},
+	})
+	require.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(d.Apply(ctx))
+	cancel()
+
+	// Wait until the resolver has been stopped.
+	for i := 0; i < 100 && d.Addr() != nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(d.Addr())
+	assert.NoError(d.Revert())
+}
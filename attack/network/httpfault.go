@@ -0,0 +1,396 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// HTTPFaultID is the identifier of the attack
+	HTTPFaultID = "http_fault"
+
+	// Options
+	pathPrefixKey      = "path_prefix"
+	methodsKey         = "methods"
+	errorPercentKey    = "error_percent"
+	errorCodeKey       = "error_code"
+	truncatePercentKey = "truncate_percent"
+	corruptPercentKey  = "corrupt_percent"
+
+	defaultHTTPFaultErrorCode = http.StatusServiceUnavailable
+	httpFaultShutdownTimeout  = 5 * time.Second
+	corruptedContentType      = "application/x-ragnarok-corrupted"
+)
+
+// httpFaultSchema is the schema of the attack options.
+var httpFaultSchema = attack.Schema{
+	{Name: listenKey, Type: attack.StringOptType, Required: true, Description: "The address where the proxy will listen."},
+	{Name: upstreamKey, Type: attack.StringOptType, Required: true, Description: "The URL of the upstream where the proxy will forward the requests."},
+	{Name: pathPrefixKey, Type: attack.StringOptType, Description: "The path prefix of the requests that will have faults."},
+	{Name: methodsKey, Type: attack.StringListOptType, Description: "The methods of the requests that will have faults."},
+	{Name: latencyKey, Type: attack.DurationOptType, Description: "The latency added to the requests."},
+	{Name: errorPercentKey, Type: attack.IntOptType, Description: "The percent of requests that will be responded with an error."},
+	{Name: errorCodeKey, Type: attack.IntOptType, Default: defaultHTTPFaultErrorCode, Description: "The 5xx status code of the error responses."},
+	{Name: truncatePercentKey, Type: attack.IntOptType, Description: "The percent of responses whose body will be truncated."},
+	{Name: corruptPercentKey, Type: attack.IntOptType, Description: "The percent of responses whose headers will be corrupted."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(HTTPFaultID, attack.NewSchemaCreator("Proxies HTTP requests to an upstream injecting latency, errors and corrupted responses.", httpFaultSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewHTTPFaultOpts(o)
+	})))
+}
+
+// HTTPFaultConfig is the configuration of the HTTP fault attack.
+type HTTPFaultConfig struct {
+	ListenAddress   string        // The address where the proxy will listen.
+	Upstream        string        // The URL of the upstream where the proxy will forward the requests.
+	PathPrefix      string        // The path prefix of the requests that will have faults, empty matches all.
+	Methods         []string      // The methods of the requests that will have faults, empty matches all.
+	Latency         time.Duration // The latency added to the requests.
+	ErrorPercent    int           // The percent of requests that will be responded with an error.
+	ErrorCode       int           // The status code of the error responses.
+	TruncatePercent int           // The percent of responses whose body will be truncated.
+	CorruptPercent  int           // The percent of responses whose headers will be corrupted.
+}
+
+// httpFaults are the faults that need to be applied to a request.
+type httpFaults struct {
+	truncate bool
+	corrupt  bool
+}
+
+type httpFaultsKey struct{}
+
+// HTTPFault failer will apply a failure running a reverse proxy in front of an
+// HTTP upstream that injects faults on the requests.
+type HTTPFault struct {
+	Config HTTPFaultConfig
+
+	upstream *url.URL
+	server   *http.Server
+	listener net.Listener
+	serveErr error         // The error that stopped the server while listening.
+	stopC    chan struct{} // Channel closed when the proxy is shutdown.
+	rnd      *rand.Rand
+	rndMu    sync.Mutex
+	wg       sync.WaitGroup // Used to wait until the server has finished.
+	mu       sync.Mutex
+	log      log.Logger // Logger.
+}
+
+// NewHTTPFaultOpts returns a new HTTP fault failer using options.
+func NewHTTPFaultOpts(opts attack.Opts) (*HTTPFault, error) {
+	cfg := HTTPFaultConfig{
+		ErrorCode: defaultHTTPFaultErrorCode,
+	}
+	var ok bool
+	if cfg.ListenAddress, ok = opts[listenKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", listenKey, opts[listenKey])
+	}
+	if cfg.Upstream, ok = opts[upstreamKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", upstreamKey, opts[upstreamKey])
+	}
+	if v, ok := opts[pathPrefixKey]; ok {
+		if cfg.PathPrefix, ok = v.(string); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", pathPrefixKey, v)
+		}
+	}
+
+	var err error
+	if cfg.Methods, err = getStringsOpt(opts, methodsKey); err != nil {
+		return nil, err
+	}
+	if cfg.Latency, err = getDurationOpt(opts, latencyKey); err != nil {
+		return nil, err
+	}
+	if cfg.ErrorPercent, err = getIntOpt(opts, errorPercentKey); err != nil {
+		return nil, err
+	}
+	if _, ok := opts[errorCodeKey]; ok {
+		if cfg.ErrorCode, err = getIntOpt(opts, errorCodeKey); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TruncatePercent, err = getIntOpt(opts, truncatePercentKey); err != nil {
+		return nil, err
+	}
+	if cfg.CorruptPercent, err = getIntOpt(opts, corruptPercentKey); err != nil {
+		return nil, err
+	}
+
+	return NewHTTPFault(cfg)
+}
+
+func validPercent(p int) bool {
+	return p >= 0 && p <= 100
+}
+
+// NewHTTPFault returns a new HTTP fault failer.
+func NewHTTPFault(cfg HTTPFaultConfig) (*HTTPFault, error) {
+	if cfg.ListenAddress == "" {
+		return nil, fmt.Errorf("listen address can't be empty")
+	}
+	u, err := url.Parse(cfg.Upstream)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid upstream URL '%s'", cfg.Upstream)
+	}
+	if cfg.ErrorCode < 500 || cfg.ErrorCode > 599 {
+		return nil, fmt.Errorf("error code needs to be a 5xx status code")
+	}
+	if !validPercent(cfg.ErrorPercent) || !validPercent(cfg.TruncatePercent) || !validPercent(cfg.CorruptPercent) {
+		return nil, fmt.Errorf("percents need to be between 0 and 100")
+	}
+	for i, m := range cfg.Methods {
+		cfg.Methods[i] = strings.ToUpper(m)
+	}
+
+	return &HTTPFault{
+		Config:   cfg,
+		upstream: u,
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		log:      log.Base(),
+	}, nil
+}
+
+// Apply will start the reverse proxy, the proxy will run until the context is
+// cancelled or the attack is reverted.
+func (h *HTTPFault) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.server != nil {
+		return errors.New("http fault already applied")
+	}
+
+	l, err := net.Listen("tcp", h.Config.ListenAddress)
+	if err != nil {
+		return err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(h.upstream)
+	proxy.ModifyResponse = h.modifyResponse
+	// Flush every write so the truncated responses reach the client partially.
+	proxy.FlushInterval = -1
+	srv := &http.Server{Handler: h.handler(proxy)}
+	h.server = srv
+	h.listener = l
+	h.serveErr = nil
+	h.stopC = make(chan struct{})
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		err := srv.Serve(l)
+		h.mu.Lock()
+		if h.server == srv {
+			h.serveErr = err
+		}
+		h.mu.Unlock()
+	}()
+
+	// Stop proxying when the context is done.
+	go func(stopC chan struct{}) {
+		select {
+		case <-stopC:
+		case <-ctx.Done():
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.server == srv {
+				h.shutdown()
+			}
+		}
+	}(h.stopC)
+
+	h.log.With("listen", l.Addr()).With("upstream", h.Config.Upstream).Infof("http fault proxy started")
+	return nil
+}
+
+// Addr returns the address where the proxy is listening, nil if not listening.
+func (h *HTTPFault) Addr() net.Addr {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listener == nil {
+		return nil
+	}
+	return h.listener.Addr()
+}
+
+// matches returns true if the faults need to be applied to the request.
+func (h *HTTPFault) matches(r *http.Request) bool {
+	if !strings.HasPrefix(r.URL.Path, h.Config.PathPrefix) {
+		return false
+	}
+	if len(h.Config.Methods) == 0 {
+		return true
+	}
+	for _, m := range h.Config.Methods {
+		if m == r.Method {
+			return true
+		}
+	}
+	return false
+}
+
+// roll returns true based on a percent of probability.
+func (h *HTTPFault) roll(percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	h.rndMu.Lock()
+	defer h.rndMu.Unlock()
+	return h.rnd.Intn(100) < percent
+}
+
+// handler returns the handler that will apply the faults on the requests before
+// proxying them.
+func (h *HTTPFault) handler(proxy http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.matches(r) {
+			proxy.ServeHTTP(w, r)
+			return
+		}
+
+		if h.Config.Latency > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(h.Config.Latency):
+			}
+		}
+
+		if h.roll(h.Config.ErrorPercent) {
+			http.Error(w, "fault injected by ragnarok", h.Config.ErrorCode)
+			return
+		}
+
+		faults := httpFaults{
+			truncate: h.roll(h.Config.TruncatePercent),
+			corrupt:  h.roll(h.Config.CorruptPercent),
+		}
+		ctx := context.WithValue(r.Context(), httpFaultsKey{}, faults)
+		proxy.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// modifyResponse will apply the faults on the upstream response.
+func (h *HTTPFault) modifyResponse(resp *http.Response) error {
+	faults, ok := resp.Request.Context().Value(httpFaultsKey{}).(httpFaults)
+	if !ok {
+		return nil
+	}
+
+	if faults.corrupt {
+		corruptHeaders(resp.Header)
+	}
+
+	if faults.truncate {
+		var limit int64
+		if resp.ContentLength > 0 {
+			limit = resp.ContentLength / 2
+		}
+		resp.Body = &truncatedBody{
+			r: io.LimitReader(resp.Body, limit),
+			c: resp.Body,
+		}
+	}
+	return nil
+}
+
+// corruptHeaders will corrupt the values of the headers, it will keep the
+// headers that are required to read the response.
+func corruptHeaders(hs http.Header) {
+	for k, vs := range hs {
+		switch k {
+		case "Content-Length", "Transfer-Encoding", "Connection":
+			continue
+		case "Content-Type":
+			hs.Set(k, corruptedContentType)
+			continue
+		}
+		for i, v := range vs {
+			b := []byte(v)
+			for j := 0; j < len(b)/2; j++ {
+				b[j], b[len(b)-1-j] = b[len(b)-1-j], b[j]
+			}
+			vs[i] = string(b)
+		}
+	}
+}
+
+// truncatedBody is a body that will return an error instead of an EOF when the
+// limited reader has finished, this will abort the response.
+type truncatedBody struct {
+	r io.Reader
+	c io.Closer
+}
+
+func (t *truncatedBody) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (t *truncatedBody) Close() error {
+	return t.c.Close()
+}
+
+// shutdown stops the server.
+func (h *HTTPFault) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), httpFaultShutdownTimeout)
+	defer cancel()
+	if err := h.server.Shutdown(ctx); err != nil {
+		h.server.Close()
+	}
+	h.server = nil
+	h.listener = nil
+	close(h.stopC)
+}
+
+// Verify will check the reverse proxy is serving.
+func (h *HTTPFault) Verify(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.server == nil {
+		return errors.New("http fault proxy is not listening")
+	}
+	if h.serveErr != nil {
+		return fmt.Errorf("http fault proxy stopped serving: %s", h.serveErr)
+	}
+	return nil
+}
+
+// Revert will stop the reverse proxy.
+func (h *HTTPFault) Revert() error {
+	h.mu.Lock()
+	if h.server != nil {
+		h.shutdown()
+	}
+	h.mu.Unlock()
+
+	h.wg.Wait()
+	h.log.With("listen", h.Config.ListenAddress).Infof("reverted http fault proxy")
+	return nil
+}
@@ -0,0 +1,327 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+const testHTTPBody = "Nothing is true, everything is permitted."
+
+// startHTTPUpstream starts an HTTP server that will respond always with the
+// same body.
+func startHTTPUpstream() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Ragnarok", "test-value")
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(testHTTPBody)))
+		w.Write([]byte(testHTTPBody))
+	}))
+}
+
+func TestHTTPFaultCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg HTTPFaultConfig
+		expErr bool
+	}{
+		{
+			name: "Creating a proxy with all the options should use them.",
+			opts: attack.Opts{
+				"listen":           "127.0.0.1:8080",
+				"upstream":         "http://127.0.0.1:80",
+				"path_prefix":      "/api",
+				"methods":          []interface{}{"get", "POST"},
+				"latency":          "100ms",
+				"error_percent":    10,
+				"error_code":       500,
+				"truncate_percent": 20,
+				"corrupt_percent":  30,
+			},
+			expCfg: HTTPFaultConfig{
+				ListenAddress:   "127.0.0.1:8080",
+				Upstream:        "http://127.0.0.1:80",
+				PathPrefix:      "/api",
+				Methods:         []string{"GET", "POST"},
+				Latency:         100 * time.Millisecond,
+				ErrorPercent:    10,
+				ErrorCode:       500,
+				TruncatePercent: 20,
+				CorruptPercent:  30,
+			},
+		},
+		{
+			name: "Creating a proxy without optional options should use defaults.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:8080",
+				"upstream": "http://127.0.0.1:80",
+			},
+			expCfg: HTTPFaultConfig{
+				ListenAddress: "127.0.0.1:8080",
+				Upstream:      "http://127.0.0.1:80",
+				ErrorCode:     503,
+			},
+		},
+		{
+			name: "Creating a proxy without upstream should error.",
+			opts: attack.Opts{
+				"listen": "127.0.0.1:8080",
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a proxy with an invalid upstream URL should error.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:8080",
+				"upstream": "127.0.0.1:80",
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a proxy with a non 5xx error code should error.",
+			opts: attack.Opts{
+				"listen":     "127.0.0.1:8080",
+				"upstream":   "http://127.0.0.1:80",
+				"error_code": 404,
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a proxy with an invalid percent should error.",
+			opts: attack.Opts{
+				"listen":          "127.0.0.1:8080",
+				"upstream":        "http://127.0.0.1:80",
+				"corrupt_percent": 101,
+			},
+			expErr: true,
+		},
+		{
+			name: "Creating a proxy with invalid methods should error.",
+			opts: attack.Opts{
+				"listen":   "127.0.0.1:8080",
+				"upstream": "http://127.0.0.1:80",
+				"methods":  "GET",
+			},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			h, err := NewHTTPFaultOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, h.Config)
+			}
+		})
+	}
+}
+
+func TestHTTPFaultApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        HTTPFaultConfig
+		method     string
+		path       string
+		expCode    int
+		expReadErr bool
+		expCorrupt bool
+		expMinTime time.Duration
+	}{
+		{
+			name:    "A proxy without faults should proxy the requests.",
+			cfg:     HTTPFaultConfig{},
+			method:  "GET",
+			path:    "/",
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "A proxy with all the requests failing should respond with errors.",
+			cfg:     HTTPFaultConfig{ErrorPercent: 100, ErrorCode: 502},
+			method:  "GET",
+			path:    "/",
+			expCode: http.StatusBadGateway,
+		},
+		{
+			name:       "A proxy with latency should delay the requests.",
+			cfg:        HTTPFaultConfig{Latency: 50 * time.Millisecond},
+			method:     "GET",
+			path:       "/",
+			expCode:    http.StatusOK,
+			expMinTime: 50 * time.Millisecond,
+		},
+		{
+			name:       "A proxy with all the responses truncated should abort the responses.",
+			cfg:        HTTPFaultConfig{TruncatePercent: 100},
+			method:     "GET",
+			path:       "/",
+			expCode:    http.StatusOK,
+			expReadErr: true,
+		},
+		{
+			name:       "A proxy with all the responses corrupted should corrupt the headers.",
+			cfg:        HTTPFaultConfig{CorruptPercent: 100},
+			method:     "GET",
+			path:       "/",
+			expCode:    http.StatusOK,
+			expCorrupt: true,
+		},
+		{
+			name:    "A proxy should not apply faults on requests with a not matching path.",
+			cfg:     HTTPFaultConfig{PathPrefix: "/api", ErrorPercent: 100},
+			method:  "GET",
+			path:    "/health",
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "A proxy should not apply faults on requests with a not matching method.",
+			cfg:     HTTPFaultConfig{Methods: []string{"post"}, ErrorPercent: 100},
+			method:  "GET",
+			path:    "/",
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "A proxy should apply faults on requests with matching path and method.",
+			cfg:     HTTPFaultConfig{PathPrefix: "/api", Methods: []string{"post"}, ErrorPercent: 100},
+			method:  "POST",
+			path:    "/api/users",
+			expCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			upstream := startHTTPUpstream()
+			defer upstream.Close()
+
+			cfg := test.cfg
+			cfg.ListenAddress = "127.0.0.1:0"
+			cfg.Upstream = upstream.URL
+			if cfg.ErrorCode == 0 {
+				cfg.ErrorCode = defaultHTTPFaultErrorCode
+			}
+			h, err := NewHTTPFault(cfg)
+			require.NoError(err)
+			require.NoError(h.Apply(context.Background()))
+			defer h.Revert()
+
+			req, err := http.NewRequest(test.method, fmt.Sprintf("http://%s%s", h.Addr(), test.path), nil)
+			require.NoError(err)
+			start := time.Now()
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(err)
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+
+			assert.Equal(test.expCode, resp.StatusCode)
+			assert.True(time.Since(start) >= test.expMinTime)
+			if test.expReadErr {
+				assert.Error(err)
+				assert.True(len(body) < len(testHTTPBody))
+				return
+			}
+			require.NoError(err)
+			if test.expCode == http.StatusOK {
+				assert.Equal(testHTTPBody, string(body))
+			}
+			if test.expCorrupt {
+				assert.Equal("eulav-tset", resp.Header.Get("X-Ragnarok"))
+				assert.Equal(corruptedContentType, resp.Header.Get("Content-Type"))
+			} else if test.expCode == http.StatusOK {
+				assert.Equal("test-value", resp.Header.Get("X-Ragnarok"))
+			}
+		})
+	}
+}
+
+func TestHTTPFaultApplyTwice(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	h, err := NewHTTPFault(HTTPFaultConfig{ListenAddress: "127.0.0.1:0", Upstream: "http://127.0.0.1:80", ErrorCode: 503})
+	require.NoError(err)
+	require.NoError(h.Apply(context.Background()))
+	defer h.Revert()
+	assert.Error(h.Apply(context.Background()))
+}
+
+func TestHTTPFaultRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	upstream := startHTTPUpstream()
+	defer upstream.Close()
+
+	h, err := NewHTTPFault(HTTPFaultConfig{ListenAddress: "127.0.0.1:0", Upstream: upstream.URL, ErrorCode: 503})
+	require.NoError(err)
+	require.NoError(h.Apply(context.Background()))
+	addr := h.Addr().String()
+
+	require.NoError(h.Revert())
+	assert.Nil(h.Addr())
+	_, err = http.Get(fmt.Sprintf("http://%s/", addr))
+	assert.Error(err)
+
+	// Should be able to apply again after revert.
+	require.NoError(h.Apply(context.Background()))
+	assert.NoError(h.Revert())
+}
+
+func TestHTTPFaultVerify(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	h, err := NewHTTPFault(HTTPFaultConfig{ListenAddress: "127.0.0.1:0", Upstream: "http://127.0.0.1:80", ErrorCode: 503})
+	require.NoError(err)
+	assert.Error(h.Verify(context.Background()), "not applied proxy shouldn't be verified")
+
+	require.NoError(h.Apply(context.Background()))
+	defer h.Revert()
+	assert.NoError(h.Verify(context.Background()))
+
+	// Break the listener under the proxy.
+	h.mu.Lock()
+	h.listener.Close()
+	h.mu.Unlock()
+	for i := 0; h.Verify(context.Background()) == nil; i++ {
+		require.True(i < 100, "broken proxy shouldn't be verified")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHTTPFaultContextCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	h, err := NewHTTPFault(HTTPFaultConfig{ListenAddress: "127.0.0.1:0", Upstream: "http://127.0.0.1:80", ErrorCode: 503})
+	require.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(h.Apply(ctx))
+	addr := h.Addr().String()
+	cancel()
+
+	// Wait until the proxy has been stopped.
+	for i := 0; i < 100 && h.Addr() != nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(h.Addr())
+	_, err = http.Get(fmt.Sprintf("http://%s/", addr))
+	assert.Error(err)
+	assert.NoError(h.Revert())
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// getDurationOpt returns a duration option from a duration string.
+func getDurationOpt(opts attack.Opts, key string) (time.Duration, error) {
+	v, ok := opts[key]
+	if !ok {
+		return 0, nil
+	}
+	s, _ := v.(string)
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+	}
+	return d, nil
+}
+
+// getIntOpt returns an int option.
+func getIntOpt(opts attack.Opts, key string) (int, error) {
+	v, ok := opts[key]
+	if !ok {
+		return 0, nil
+	}
+	i, ok := v.(int)
+	if !ok {
+		return 0, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+	}
+	return i, nil
+}
+
+// getStringsOpt returns a string list option.
+func getStringsOpt(opts attack.Opts, key string) ([]string, error) {
+	v, ok := opts[key]
+	if !ok {
+		return nil, nil
+	}
+
+	switch l := v.(type) {
+	case []string:
+		return l, nil
+	case []interface{}:
+		res := make([]string, len(l))
+		for i, e := range l {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+			}
+			res[i] = s
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+}
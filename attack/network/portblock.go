@@ -0,0 +1,379 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+// PortBlockMode is the way the blocked ports handle the connections.
+type PortBlockMode string
+
+const (
+	// HangPortBlockMode will accept the connections and leave them hanging.
+	HangPortBlockMode PortBlockMode = "hang"
+	// RefusePortBlockMode will accept the connections and reset them right away.
+	RefusePortBlockMode PortBlockMode = "refuse"
+)
+
+const (
+	// PortBlockID is the identifier of the attack
+	PortBlockID = "port_block"
+
+	// Options
+	portsKey    = "ports"
+	protocolKey = "protocol"
+	addressKey  = "address"
+	modeKey     = "mode"
+
+	tcpProtocol  = "tcp"
+	udpProtocol  = "udp"
+	bothProtocol = "both"
+
+	maxPort = 65535
+)
+
+// portBlockSchema is the schema of the attack options.
+var portBlockSchema = attack.Schema{
+	{Name: portsKey, Type: attack.ListOptType, Required: true, Description: "The ports that will be bound, numbers or ranges (\"8000-8010\")."},
+	{Name: protocolKey, Type: attack.StringOptType, Default: tcpProtocol, Description: "The protocol of the ports: tcp, udp or both."},
+	{Name: addressKey, Type: attack.StringOptType, Description: "The address where the ports will be bound, by default all the addresses."},
+	{Name: modeKey, Type: attack.StringOptType, Default: string(HangPortBlockMode), Description: "The way the TCP connections will be handled: hang or refuse."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(PortBlockID, attack.NewSchemaCreator("Blocks ports binding them and hanging or refusing the connections.", portBlockSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewPortBlockOpts(o)
+	})))
+}
+
+// PortBlockConfig is the configuration of the port block attack.
+type PortBlockConfig struct {
+	Address  string        // The address where the ports will be bound, empty means all the addresses.
+	Ports    []int         // The ports that will be bound.
+	Protocol string        // The protocol of the ports, tcp, udp or both.
+	Mode     PortBlockMode // The way the TCP connections will be handled.
+}
+
+// PortBlock failer will apply a failure binding and holding ports so any other
+// process trying to bind them will fail.
+type PortBlock struct {
+	Config PortBlockConfig
+
+	listeners   []net.Listener
+	packetConns []net.PacketConn
+	conns       map[net.Conn]struct{} // The hanging connections.
+	stopC       chan struct{}         // Channel closed when the ports are released.
+	running     bool                  // Flag that marks the ports are bound.
+	wg          sync.WaitGroup        // Used to wait until all the ports have been released.
+	mu          sync.Mutex
+	log         log.Logger // Logger.
+}
+
+// NewPortBlockOpts returns a new port block failer using options. If the protocol
+// is missing it will use tcp, if the mode is missing it will use hang.
+func NewPortBlockOpts(opts attack.Opts) (*PortBlock, error) {
+	cfg := PortBlockConfig{
+		Protocol: tcpProtocol,
+		Mode:     HangPortBlockMode,
+	}
+
+	var err error
+	if cfg.Ports, err = getPortsOpt(opts, portsKey); err != nil {
+		return nil, err
+	}
+
+	if v, ok := opts[protocolKey]; ok {
+		if cfg.Protocol, ok = v.(string); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", protocolKey, v)
+		}
+	}
+
+	if v, ok := opts[addressKey]; ok {
+		if cfg.Address, ok = v.(string); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", addressKey, v)
+		}
+	}
+
+	if v, ok := opts[modeKey]; ok {
+		m, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", modeKey, v)
+		}
+		cfg.Mode = PortBlockMode(m)
+	}
+
+	return NewPortBlock(cfg)
+}
+
+// getPortsOpt returns a port list option, the ports can be numbers or strings
+// with a single port or a range of ports ("8000-8010").
+func getPortsOpt(opts attack.Opts, key string) ([]int, error) {
+	v, ok := opts[key]
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+	}
+
+	var elems []interface{}
+	switch l := v.(type) {
+	case []interface{}:
+		elems = l
+	case []int:
+		for _, e := range l {
+			elems = append(elems, e)
+		}
+	case []string:
+		for _, e := range l {
+			elems = append(elems, e)
+		}
+	default:
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+	}
+
+	ports := []int{}
+	for _, e := range elems {
+		switch p := e.(type) {
+		case int:
+			ports = append(ports, p)
+		case string:
+			rng, err := parsePortRange(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid '%s' option with '%v' value: %s", key, v, err)
+			}
+			ports = append(ports, rng...)
+		default:
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", key, v)
+		}
+	}
+	return ports, nil
+}
+
+// parsePortRange parses a single port or a range of ports.
+func parsePortRange(s string) ([]int, error) {
+	bounds := strings.SplitN(s, "-", 2)
+	low, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid port '%s'", s)
+	}
+	high := low
+	if len(bounds) == 2 {
+		if high, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+			return nil, fmt.Errorf("invalid port '%s'", s)
+		}
+	}
+	if low > high {
+		return nil, fmt.Errorf("invalid port range '%s'", s)
+	}
+
+	ports := make([]int, 0, high-low+1)
+	for p := low; p <= high; p++ {
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// NewPortBlock returns a new port block failer.
+func NewPortBlock(cfg PortBlockConfig) (*PortBlock, error) {
+	if len(cfg.Ports) == 0 {
+		return nil, fmt.Errorf("ports can't be empty")
+	}
+	for _, p := range cfg.Ports {
+		if p <= 0 || p > maxPort {
+			return nil, fmt.Errorf("port %d needs to be between 1 and %d", p, maxPort)
+		}
+	}
+
+	switch cfg.Protocol {
+	case tcpProtocol, udpProtocol, bothProtocol:
+	default:
+		return nil, fmt.Errorf("protocol needs to be %s, %s or %s", tcpProtocol, udpProtocol, bothProtocol)
+	}
+
+	switch cfg.Mode {
+	case HangPortBlockMode, RefusePortBlockMode:
+	default:
+		return nil, fmt.Errorf("mode needs to be %s or %s", HangPortBlockMode, RefusePortBlockMode)
+	}
+
+	return &PortBlock{
+		Config: cfg,
+		conns:  map[net.Conn]struct{}{},
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will bind all the ports, if any of the ports can't be bound it will
+// release the already bound ones and error. The ports will be held until the
+// context is cancelled or the attack is reverted.
+func (p *PortBlock) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return errors.New("port block already applied")
+	}
+
+	if err := p.bind(); err != nil {
+		p.release()
+		return err
+	}
+	p.stopC = make(chan struct{})
+	p.running = true
+
+	for _, l := range p.listeners {
+		p.wg.Add(1)
+		go p.serve(l)
+	}
+	for _, pc := range p.packetConns {
+		p.wg.Add(1)
+		go p.discard(pc)
+	}
+
+	// Release the ports when the context is done.
+	go func(stopC chan struct{}) {
+		select {
+		case <-stopC:
+		case <-ctx.Done():
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.stopC == stopC {
+				p.shutdown()
+			}
+		}
+	}(p.stopC)
+
+	p.log.With("ports", p.Config.Ports).With("protocol", p.Config.Protocol).With("mode", p.Config.Mode).Infof("port block started")
+	return nil
+}
+
+// bind will bind all the ports.
+func (p *PortBlock) bind() error {
+	tcp := p.Config.Protocol == tcpProtocol || p.Config.Protocol == bothProtocol
+	udp := p.Config.Protocol == udpProtocol || p.Config.Protocol == bothProtocol
+	for _, port := range p.Config.Ports {
+		addr := net.JoinHostPort(p.Config.Address, strconv.Itoa(port))
+		if tcp {
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			p.listeners = append(p.listeners, l)
+		}
+		if udp {
+			pc, err := net.ListenPacket("udp", addr)
+			if err != nil {
+				return err
+			}
+			p.packetConns = append(p.packetConns, pc)
+		}
+	}
+	return nil
+}
+
+// release will close all the bound ports and the hanging connections.
+func (p *PortBlock) release() {
+	for _, l := range p.listeners {
+		l.Close()
+	}
+	for _, pc := range p.packetConns {
+		pc.Close()
+	}
+	for c := range p.conns {
+		c.Close()
+		delete(p.conns, c)
+	}
+	p.listeners = nil
+	p.packetConns = nil
+}
+
+// serve accepts the connections until the listener is closed, the connections
+// will hang or will be refused depending on the mode.
+func (p *PortBlock) serve(l net.Listener) {
+	defer p.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		if p.Config.Mode == RefusePortBlockMode {
+			if tc, ok := conn.(*net.TCPConn); ok {
+				tc.SetLinger(0)
+			}
+			conn.Close()
+			continue
+		}
+
+		p.mu.Lock()
+		// Released while accepting.
+		if !p.running {
+			p.mu.Unlock()
+			conn.Close()
+			return
+		}
+		p.conns[conn] = struct{}{}
+		p.wg.Add(1)
+		p.mu.Unlock()
+		go p.hang(conn)
+	}
+}
+
+// hang will hold the connection without answering until it is closed.
+func (p *PortBlock) hang(conn net.Conn) {
+	defer p.wg.Done()
+	io.Copy(ioutil.Discard, conn)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn.Close()
+	delete(p.conns, conn)
+}
+
+// discard will read and ignore the packets until the connection is closed.
+func (p *PortBlock) discard(pc net.PacketConn) {
+	defer p.wg.Done()
+	b := make([]byte, 64*1024)
+	for {
+		if _, _, err := pc.ReadFrom(b); err != nil {
+			return
+		}
+	}
+}
+
+// shutdown releases the ports.
+func (p *PortBlock) shutdown() {
+	p.release()
+	p.running = false
+	close(p.stopC)
+	p.stopC = nil
+}
+
+// Revert will release all the ports and close the hanging connections.
+func (p *PortBlock) Revert() error {
+	p.mu.Lock()
+	if p.running {
+		p.shutdown()
+	}
+	p.mu.Unlock()
+
+	// Wait until all the ports have been released.
+	p.wg.Wait()
+	p.log.With("ports", p.Config.Ports).Infof("reverted port block")
+	return nil
+}
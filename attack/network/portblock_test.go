@@ -0,0 +1,315 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// freePort returns a port that is free on loopback for TCP and UDP.
+func freePort(t *testing.T) int {
+	for i := 0; i < 10; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+
+		pc, err := net.ListenPacket("udp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			continue
+		}
+		pc.Close()
+		return port
+	}
+	require.FailNow(t, "couldn't get a free port")
+	return 0
+}
+
+func TestPortBlockCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg PortBlockConfig
+		expErr bool
+	}{
+		{
+			name: "Creating a port block with all the options should use them.",
+			opts: attack.Opts{
+				"ports":    []interface{}{8080, "9000", "10000-10002"},
+				"protocol": "both",
+				"address":  "127.0.0.1",
+				"mode":     "refuse",
+			},
+			expCfg: PortBlockConfig{
+				Address:  "127.0.0.1",
+				Ports:    []int{8080, 9000, 10000, 10001, 10002},
+				Protocol: "both",
+				Mode:     RefusePortBlockMode,
+			},
+		},
+		{
+			name: "Creating a port block without optional options should use defaults.",
+			opts: attack.Opts{
+				"ports": []int{8080},
+			},
+			expCfg: PortBlockConfig{
+				Ports:    []int{8080},
+				Protocol: "tcp",
+				Mode:     HangPortBlockMode,
+			},
+		},
+		{
+			name:   "Creating a port block without ports should error.",
+			opts:   attack.Opts{},
+			expErr: true,
+		},
+		{
+			name:   "Creating a port block with empty ports should error.",
+			opts:   attack.Opts{"ports": []int{}},
+			expErr: true,
+		},
+		{
+			name:   "Creating a port block with an invalid port range should error.",
+			opts:   attack.Opts{"ports": []string{"9000-8000"}},
+			expErr: true,
+		},
+		{
+			name:   "Creating a port block with an out of range port should error.",
+			opts:   attack.Opts{"ports": []int{70000}},
+			expErr: true,
+		},
+		{
+			name:   "Creating a port block with an invalid protocol should error.",
+			opts:   attack.Opts{"ports": []int{8080}, "protocol": "sctp"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a port block with an invalid mode should error.",
+			opts:   attack.Opts{"ports": []int{8080}, "mode": "drop"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := NewPortBlockOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, p.Config)
+			}
+		})
+	}
+}
+
+func TestPortBlockApplyRevert(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		expTCP   bool
+		expUDP   bool
+	}{
+		{
+			name:     "Blocking TCP ports should make the TCP binds fail.",
+			protocol: "tcp",
+			expTCP:   true,
+		},
+		{
+			name:     "Blocking UDP ports should make the UDP binds fail.",
+			protocol: "udp",
+			expUDP:   true,
+		},
+		{
+			name:     "Blocking both protocols ports should make all the binds fail.",
+			protocol: "both",
+			expTCP:   true,
+			expUDP:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			port := freePort(t)
+			addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+			p, err := NewPortBlock(PortBlockConfig{
+				Address:  "127.0.0.1",
+				Ports:    []int{port},
+				Protocol: test.protocol,
+				Mode:     HangPortBlockMode,
+			})
+			require.NoError(err)
+
+			require.NoError(p.Apply(context.Background()))
+			assert.Error(p.Apply(context.Background()), "applying twice should error")
+
+			l, err := net.Listen("tcp", addr)
+			assert.Equal(test.expTCP, err != nil)
+			if err == nil {
+				l.Close()
+			}
+			pc, err := net.ListenPacket("udp", addr)
+			assert.Equal(test.expUDP, err != nil)
+			if err == nil {
+				pc.Close()
+			}
+
+			require.NoError(p.Revert())
+
+			// After the revert the ports should be free.
+			l, err = net.Listen("tcp", addr)
+			if assert.NoError(err) {
+				l.Close()
+			}
+			pc, err = net.ListenPacket("udp", addr)
+			if assert.NoError(err) {
+				pc.Close()
+			}
+		})
+	}
+}
+
+func TestPortBlockModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      PortBlockMode
+		expHang   bool
+		expRefuse bool
+	}{
+		{
+			name:    "Hang mode should accept the connections and leave them hanging.",
+			mode:    HangPortBlockMode,
+			expHang: true,
+		},
+		{
+			name:      "Refuse mode should reset the connections.",
+			mode:      RefusePortBlockMode,
+			expRefuse: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			port := freePort(t)
+			p, err := NewPortBlock(PortBlockConfig{
+				Address:  "127.0.0.1",
+				Ports:    []int{port},
+				Protocol: "tcp",
+				Mode:     test.mode,
+			})
+			require.NoError(err)
+			require.NoError(p.Apply(context.Background()))
+			defer p.Revert()
+
+			conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+			if err != nil {
+				// The reset could be received while connecting.
+				assert.True(test.expRefuse)
+				return
+			}
+			defer conn.Close()
+
+			conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			_, err = conn.Read(make([]byte, 1))
+			require.Error(err)
+			nerr, ok := err.(net.Error)
+			timeout := ok && nerr.Timeout()
+			assert.Equal(test.expHang, timeout)
+			assert.Equal(test.expRefuse, !timeout)
+		})
+	}
+}
+
+func TestPortBlockApplyBusyPort(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+	port := freePort(t)
+
+	p, err := NewPortBlock(PortBlockConfig{
+		Address:  "127.0.0.1",
+		Ports:    []int{port, busyPort},
+		Protocol: "tcp",
+		Mode:     HangPortBlockMode,
+	})
+	require.NoError(err)
+	assert.Error(p.Apply(context.Background()))
+
+	// The already bound ports should be released.
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if assert.NoError(err) {
+		l.Close()
+	}
+	assert.NoError(p.Revert())
+}
+
+func TestPortBlockContextCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	port := freePort(t)
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	p, err := NewPortBlock(PortBlockConfig{
+		Address:  "127.0.0.1",
+		Ports:    []int{port},
+		Protocol: "tcp",
+		Mode:     HangPortBlockMode,
+	})
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(p.Apply(ctx))
+	cancel()
+
+	// Wait until the ports have been released.
+	var l net.Listener
+	for i := 0; i < 100; i++ {
+		if l, err = net.Listen("tcp", addr); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if assert.NoError(err) {
+		l.Close()
+	}
+	assert.NoError(p.Revert())
+}
+
+func TestPortBlockContextCancelAndRevert(t *testing.T) {
+	require := require.New(t)
+
+	p, err := NewPortBlock(PortBlockConfig{
+		Address:  "127.0.0.1",
+		Ports:    []int{freePort(t)},
+		Protocol: "tcp",
+		Mode:     HangPortBlockMode,
+	})
+	require.NoError(err)
+
+	// Reverting right after cancelling the context shouldn't release the ports twice.
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(p.Apply(ctx))
+		cancel()
+		require.NoError(p.Revert())
+	}
+}
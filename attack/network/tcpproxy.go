@@ -0,0 +1,377 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// TCPProxyID is the identifier of the attack
+	TCPProxyID = "tcp_proxy"
+
+	// Options
+	listenKey       = "listen"
+	upstreamKey     = "upstream"
+	latencyKey      = "latency"
+	jitterKey       = "jitter"
+	bandwidthKey    = "bandwidth"
+	resetPercentKey = "reset_percent"
+	dropPercentKey  = "drop_percent"
+
+	tcpProxyBufferSize  = 32 << 10 // 32KiB
+	tcpProxyDialTimeout = 5 * time.Second
+)
+
+// tcpProxySchema is the schema of the attack options.
+var tcpProxySchema = attack.Schema{
+	{Name: listenKey, Type: attack.StringOptType, Required: true, Description: "The address where the proxy will listen."},
+	{Name: upstreamKey, Type: attack.StringOptType, Required: true, Description: "The address where the proxy will forward the connections."},
+	{Name: latencyKey, Type: attack.DurationOptType, Description: "The latency added to the forwarded data."},
+	{Name: jitterKey, Type: attack.DurationOptType, Description: "The random variation of the latency."},
+	{Name: bandwidthKey, Type: attack.SizeOptType, Description: "The bandwidth limit of each direction in bytes per second."},
+	{Name: resetPercentKey, Type: attack.IntOptType, Description: "The percent of connections that will be reset."},
+	{Name: dropPercentKey, Type: attack.IntOptType, Description: "The percent of connections whose data will be dropped."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(TCPProxyID, attack.NewSchemaCreator("Proxies TCP connections to an upstream injecting latency, bandwidth limits, resets and drops.", tcpProxySchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewTCPProxyOpts(o)
+	})))
+}
+
+// TCPProxyConfig is the configuration of the TCP proxy attack.
+type TCPProxyConfig struct {
+	ListenAddress   string        // The address where the proxy will listen.
+	UpstreamAddress string        // The address where the proxy will forward the connections.
+	Latency         time.Duration // The latency added to each forwarded chunk of data.
+	Jitter          time.Duration // The random variation of the latency.
+	Bandwidth       int           // The bandwidth limit of each connection direction in bytes per second, 0 is unlimited.
+	ResetPercent    int           // The percent of connections that will be reset.
+	DropPercent     int           // The percent of connections whose data will be dropped.
+}
+
+// TCPProxy failer will apply a failure proxying TCP connections to an upstream
+// and injecting network faults on them.
+type TCPProxy struct {
+	Config TCPProxyConfig
+
+	listener net.Listener
+	serveErr error                 // The error that stopped accepting connections while listening.
+	stopC    chan struct{}         // Channel closed when the proxy is shutdown.
+	conns    map[net.Conn]struct{} // The active connections.
+	rnd      *rand.Rand
+	wg       sync.WaitGroup // Used to wait until all the connections have finished.
+	mu       sync.Mutex
+	log      log.Logger // Logger.
+}
+
+// NewTCPProxyOpts returns a new TCP proxy failer using options.
+func NewTCPProxyOpts(opts attack.Opts) (*TCPProxy, error) {
+	cfg := TCPProxyConfig{}
+	var ok bool
+	if cfg.ListenAddress, ok = opts[listenKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", listenKey, opts[listenKey])
+	}
+	if cfg.UpstreamAddress, ok = opts[upstreamKey].(string); !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", upstreamKey, opts[upstreamKey])
+	}
+
+	var err error
+	if cfg.Latency, err = getDurationOpt(opts, latencyKey); err != nil {
+		return nil, err
+	}
+	if cfg.Jitter, err = getDurationOpt(opts, jitterKey); err != nil {
+		return nil, err
+	}
+	if cfg.Bandwidth, err = getIntOpt(opts, bandwidthKey); err != nil {
+		return nil, err
+	}
+	if cfg.ResetPercent, err = getIntOpt(opts, resetPercentKey); err != nil {
+		return nil, err
+	}
+	if cfg.DropPercent, err = getIntOpt(opts, dropPercentKey); err != nil {
+		return nil, err
+	}
+
+	return NewTCPProxy(cfg)
+}
+
+// NewTCPProxy returns a new TCP proxy failer.
+func NewTCPProxy(cfg TCPProxyConfig) (*TCPProxy, error) {
+	if cfg.ListenAddress == "" {
+		return nil, fmt.Errorf("listen address can't be empty")
+	}
+	if cfg.UpstreamAddress == "" {
+		return nil, fmt.Errorf("upstream address can't be empty")
+	}
+	if cfg.Bandwidth < 0 {
+		return nil, fmt.Errorf("bandwidth can't be negative")
+	}
+	if cfg.ResetPercent < 0 || cfg.DropPercent < 0 || cfg.ResetPercent+cfg.DropPercent > 100 {
+		return nil, fmt.Errorf("reset and drop percents need to be between 0 and 100 (both summed)")
+	}
+
+	return &TCPProxy{
+		Config: cfg,
+		conns:  map[net.Conn]struct{}{},
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will start listening and proxying the connections, the proxy will run
+// until the context is cancelled or the attack is reverted.
+func (t *TCPProxy) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listener != nil {
+		return errors.New("tcp proxy already applied")
+	}
+
+	l, err := net.Listen("tcp", t.Config.ListenAddress)
+	if err != nil {
+		return err
+	}
+	t.listener = l
+	t.serveErr = nil
+	t.stopC = make(chan struct{})
+
+	t.wg.Add(1)
+	go t.serve(l)
+
+	// Stop proxying when the context is done.
+	go func(stopC chan struct{}) {
+		select {
+		case <-stopC:
+		case <-ctx.Done():
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.listener == l {
+				t.shutdown()
+			}
+		}
+	}(t.stopC)
+
+	t.log.With("listen", l.Addr()).With("upstream", t.Config.UpstreamAddress).Infof("tcp proxy started")
+	return nil
+}
+
+// Addr returns the address where the proxy is listening, nil if not listening.
+func (t *TCPProxy) Addr() net.Addr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listener == nil {
+		return nil
+	}
+	return t.listener.Addr()
+}
+
+// serve accepts the connections until the listener is closed.
+func (t *TCPProxy) serve(l net.Listener) {
+	defer t.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			t.mu.Lock()
+			if t.listener == l {
+				t.serveErr = err
+			}
+			t.mu.Unlock()
+			return
+		}
+
+		t.mu.Lock()
+		// Closed while accepting.
+		if t.listener != l {
+			t.mu.Unlock()
+			conn.Close()
+			return
+		}
+		t.conns[conn] = struct{}{}
+		roll := t.rnd.Intn(100)
+		t.wg.Add(1)
+		t.mu.Unlock()
+
+		switch {
+		case roll < t.Config.ResetPercent:
+			go t.reset(conn)
+		case roll < t.Config.ResetPercent+t.Config.DropPercent:
+			go t.drop(conn)
+		default:
+			go t.proxy(conn)
+		}
+	}
+}
+
+// forget will close and stop tracking the connections.
+func (t *TCPProxy) forget(conns ...net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+		delete(t.conns, c)
+	}
+}
+
+// reset will close the connection sending a reset.
+func (t *TCPProxy) reset(conn net.Conn) {
+	defer t.wg.Done()
+	if tc, ok := conn.(*net.TCPConn); ok {
+		tc.SetLinger(0)
+	}
+	t.forget(conn)
+}
+
+// drop will discard all the data received on the connection.
+func (t *TCPProxy) drop(conn net.Conn) {
+	defer t.wg.Done()
+	io.Copy(ioutil.Discard, conn)
+	t.forget(conn)
+}
+
+// proxy will forward the connection to the upstream.
+func (t *TCPProxy) proxy(conn net.Conn) {
+	defer t.wg.Done()
+
+	up, err := net.DialTimeout("tcp", t.Config.UpstreamAddress, tcpProxyDialTimeout)
+	if err != nil {
+		t.log.Errorf("error connecting to upstream: %s", err)
+		t.forget(conn)
+		return
+	}
+
+	t.mu.Lock()
+	// Closed while connecting.
+	if t.listener == nil {
+		t.mu.Unlock()
+		up.Close()
+		t.forget(conn)
+		return
+	}
+	t.conns[up] = struct{}{}
+	stopC := t.stopC
+	t.mu.Unlock()
+
+	// Forward on both directions, when one of them ends, end both.
+	done := make(chan struct{}, 2)
+	go func() {
+		t.copy(up, conn, stopC)
+		done <- struct{}{}
+	}()
+	go func() {
+		t.copy(conn, up, stopC)
+		done <- struct{}{}
+	}()
+	<-done
+	t.forget(conn, up)
+	<-done
+}
+
+// copy copies the data from src to dst applying the latency and the bandwidth limit
+// until the proxy is stopped.
+func (t *TCPProxy) copy(dst io.Writer, src io.Reader, stopC chan struct{}) {
+	buf := make([]byte, tcpProxyBufferSize)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if !sleep(t.delay(), stopC) {
+				return
+			}
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return
+			}
+			if t.Config.Bandwidth > 0 {
+				if !sleep(time.Duration(float64(n)/float64(t.Config.Bandwidth)*float64(time.Second)), stopC) {
+					return
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// sleep waits the duration, returns false if the proxy was stopped while waiting.
+func sleep(d time.Duration, stopC chan struct{}) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stopC:
+		return false
+	}
+}
+
+// delay returns the latency with the jitter applied.
+func (t *TCPProxy) delay() time.Duration {
+	d := t.Config.Latency
+	if t.Config.Jitter > 0 {
+		t.mu.Lock()
+		d += time.Duration(t.rnd.Int63n(int64(2*t.Config.Jitter))) - t.Config.Jitter
+		t.mu.Unlock()
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// shutdown closes the listener and all the active connections.
+func (t *TCPProxy) shutdown() {
+	t.listener.Close()
+	t.listener = nil
+	close(t.stopC)
+	for c := range t.conns {
+		c.Close()
+	}
+}
+
+// Verify will check the proxy is accepting connections.
+func (t *TCPProxy) Verify(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listener == nil {
+		return errors.New("tcp proxy is not listening")
+	}
+	if t.serveErr != nil {
+		return fmt.Errorf("tcp proxy stopped accepting connections: %s", t.serveErr)
+	}
+	return nil
+}
+
+// Revert will stop the proxy closing the listener and all the active connections.
+func (t *TCPProxy) Revert() error {
+	t.mu.Lock()
+	if t.listener != nil {
+		t.shutdown()
+	}
+	t.mu.Unlock()
+
+	// Wait until all the connections have been drained.
+	t.wg.Wait()
+	t.log.With("listen", t.Config.ListenAddress).Infof("reverted tcp proxy")
+	return nil
+}
@@ -0,0 +1,298 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// startEchoServer starts a TCP echo server on loopback.
+func startEchoServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(c, c)
+				c.Close()
+			}()
+		}
+	}()
+	return l
+}
+
+func TestTCPProxyCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   attack.Opts
+		expCfg TCPProxyConfig
+		expErr bool
+	}{
+		{
+			name: "Creating a proxy with all the options should use them.",
+			opts: attack.Opts{
+				"listen":        "127.0.0.1:8080",
+				"upstream":      "127.0.0.1:80",
+				"latency":       "100ms",
+				"jitter":        "10ms",
+				"bandwidth":     1024,
+				"reset_percent": 10,
+				"drop_percent":  5,
+			},
+			expCfg: TCPProxyConfig{
+				ListenAddress:   "127.0.0.1:8080",
+				UpstreamAddress: "127.0.0.1:80",
+				Latency:         100 * time.Millisecond,
+				Jitter:          10 * time.Millisecond,
+				Bandwidth:       1024,
+				ResetPercent:    10,
+				DropPercent:     5,
+			},
+		},
+		{
+			name:   "Creating a proxy without upstream should error.",
+			opts:   attack.Opts{"listen": "127.0.0.1:8080"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a proxy with an invalid latency should error.",
+			opts:   attack.Opts{"listen": "127.0.0.1:8080", "upstream": "127.0.0.1:80", "latency": 100},
+			expErr: true,
+		},
+		{
+			name:   "Creating a proxy with percents over 100 should error.",
+			opts:   attack.Opts{"listen": "127.0.0.1:8080", "upstream": "127.0.0.1:80", "reset_percent": 60, "drop_percent": 50},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			p, err := NewTCPProxyOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expCfg, p.Config)
+			}
+		})
+	}
+}
+
+func TestTCPProxyLatency(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	up := startEchoServer(t)
+	defer up.Close()
+
+	latency := 30 * time.Millisecond
+	p, err := NewTCPProxy(TCPProxyConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: up.Addr().String(),
+		Latency:         latency,
+	})
+	require.NoError(err)
+	require.NoError(p.Apply(context.Background()))
+	defer p.Revert()
+
+	c, err := net.Dial("tcp", p.Addr().String())
+	require.NoError(err)
+	defer c.Close()
+
+	start := time.Now()
+	_, err = c.Write([]byte("ragnarok"))
+	require.NoError(err)
+	b := make([]byte, 8)
+	_, err = io.ReadFull(c, b)
+	require.NoError(err)
+	assert.Equal("ragnarok", string(b))
+	assert.True(time.Since(start) >= 2*latency, "round trip should have the latency of both directions")
+}
+
+func TestTCPProxyFaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		resetPercent int
+		dropPercent  int
+		expTimeout   bool
+	}{
+		{
+			name:         "Reset connections should error when reading.",
+			resetPercent: 100,
+		},
+		{
+			name:        "Dropped connections should timeout when reading.",
+			dropPercent: 100,
+			expTimeout:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			up := startEchoServer(t)
+			defer up.Close()
+
+			p, err := NewTCPProxy(TCPProxyConfig{
+				ListenAddress:   "127.0.0.1:0",
+				UpstreamAddress: up.Addr().String(),
+				ResetPercent:    test.resetPercent,
+				DropPercent:     test.dropPercent,
+			})
+			require.NoError(err)
+			require.NoError(p.Apply(context.Background()))
+			defer p.Revert()
+
+			c, err := net.Dial("tcp", p.Addr().String())
+			// The reset could happen while connecting.
+			if err != nil && !test.expTimeout {
+				return
+			}
+			require.NoError(err)
+			defer c.Close()
+
+			c.Write([]byte("ragnarok"))
+			c.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			_, err = c.Read(make([]byte, 8))
+			if assert.Error(err) {
+				nErr, ok := err.(net.Error)
+				assert.Equal(test.expTimeout, ok && nErr.Timeout())
+			}
+		})
+	}
+}
+
+func TestTCPProxyRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	up := startEchoServer(t)
+	defer up.Close()
+
+	p, err := NewTCPProxy(TCPProxyConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: up.Addr().String(),
+	})
+	require.NoError(err)
+	require.NoError(p.Apply(context.Background()))
+	addr := p.Addr().String()
+
+	c, err := net.Dial("tcp", addr)
+	require.NoError(err)
+	defer c.Close()
+	_, err = c.Write([]byte("ragnarok"))
+	require.NoError(err)
+	_, err = io.ReadFull(c, make([]byte, 8))
+	require.NoError(err)
+
+	// After reverting the connections should be drained and the proxy closed.
+	require.NoError(p.Revert())
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = c.Read(make([]byte, 8))
+	assert.Equal(io.EOF, err)
+	_, err = net.Dial("tcp", addr)
+	assert.Error(err)
+	assert.Nil(p.Addr())
+}
+
+func TestTCPProxyRevertWhileDelaying(t *testing.T) {
+	require := require.New(t)
+
+	up := startEchoServer(t)
+	defer up.Close()
+
+	p, err := NewTCPProxy(TCPProxyConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: up.Addr().String(),
+		Latency:         time.Minute,
+	})
+	require.NoError(err)
+	require.NoError(p.Apply(context.Background()))
+
+	c, err := net.Dial("tcp", p.Addr().String())
+	require.NoError(err)
+	defer c.Close()
+	_, err = c.Write([]byte("ragnarok"))
+	require.NoError(err)
+	// Let the proxy start delaying the data.
+	time.Sleep(50 * time.Millisecond)
+
+	// The revert shouldn't wait for the latency.
+	revertC := make(chan error, 1)
+	go func() {
+		revertC <- p.Revert()
+	}()
+	select {
+	case err := <-revertC:
+		require.NoError(err)
+	case <-time.After(5 * time.Second):
+		require.Fail("tcp proxy revert blocked by the latency")
+	}
+}
+
+func TestTCPProxyVerify(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	p, err := NewTCPProxy(TCPProxyConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: "127.0.0.1:80",
+	})
+	require.NoError(err)
+	assert.Error(p.Verify(context.Background()), "not applied proxy shouldn't be verified")
+
+	require.NoError(p.Apply(context.Background()))
+	defer p.Revert()
+	assert.NoError(p.Verify(context.Background()))
+
+	// Break the listener under the proxy.
+	p.mu.Lock()
+	p.listener.Close()
+	p.mu.Unlock()
+	for i := 0; p.Verify(context.Background()) == nil; i++ {
+		require.True(i < 100, "broken proxy shouldn't be verified")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTCPProxyStopsOnContextCancel(t *testing.T) {
+	require := require.New(t)
+
+	up := startEchoServer(t)
+	defer up.Close()
+
+	p, err := NewTCPProxy(TCPProxyConfig{
+		ListenAddress:   "127.0.0.1:0",
+		UpstreamAddress: up.Addr().String(),
+	})
+	require.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(p.Apply(ctx))
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-time.After(time.Second):
+		require.Fail("proxy didn't stop after the context cancellation")
+	case <-done:
+	}
+	require.NoError(p.Revert())
+}
@@ -0,0 +1,429 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+// closeTimeout is the time a plugin has to exit after closing its input.
+const closeTimeout = 5 * time.Second
+
+// Plugin is an attack plugin running on its own process.
+type Plugin struct {
+	Path string // The path of the plugin executable.
+
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	enc      *json.Encoder
+	dec      *json.Decoder
+	nextID   uint64
+	pending  map[uint64]chan Response // The calls waiting for their response by request ID.
+	readDone chan struct{}            // Channel closed when the plugin responses can't be read anymore.
+	readErr  error                    // The error reading the plugin responses.
+	done     chan struct{}            // Channel closed when the plugin process has finished.
+	err      error                    // The error of the plugin process after finishing.
+	mu       sync.Mutex
+	log      log.Logger // Logger.
+}
+
+// Start starts the plugin executable.
+func Start(path string) (*Plugin, error) {
+	p := &Plugin{
+		Path:     path,
+		cmd:      exec.Command(path),
+		pending:  map[uint64]chan Response{},
+		readDone: make(chan struct{}),
+		done:     make(chan struct{}),
+		log:      log.Base().With("plugin", filepath.Base(path)),
+	}
+
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := p.cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting %s plugin: %s", path, err)
+	}
+	p.stdin = stdin
+	p.enc = json.NewEncoder(stdin)
+	p.dec = json.NewDecoder(stdout)
+
+	// The plugin logs are its standard error.
+	go func() {
+		s := bufio.NewScanner(stderr)
+		for s.Scan() {
+			p.log.Info(s.Text())
+		}
+	}()
+
+	go p.readResponses()
+
+	go func() {
+		p.err = p.cmd.Wait()
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// readResponses reads the plugin responses and sends them to the calls waiting
+// for them until the responses can't be read.
+func (p *Plugin) readResponses() {
+	for {
+		var res Response
+		if err := p.dec.Decode(&res); err != nil {
+			p.readErr = err
+			close(p.readDone)
+			return
+		}
+
+		p.mu.Lock()
+		resC, ok := p.pending[res.ID]
+		delete(p.pending, res.ID)
+		p.mu.Unlock()
+		if !ok {
+			p.log.Warnf("received a response of an unknown %d request", res.ID)
+			continue
+		}
+		resC <- res
+	}
+}
+
+// call sends a request to the plugin and waits for the response until the
+// context is done, the calls don't block each other.
+func (p *Plugin) call(ctx context.Context, req Request) (Response, error) {
+	// Buffered so the response reader never blocks.
+	resC := make(chan Response, 1)
+
+	p.mu.Lock()
+	p.nextID++
+	req.ID = p.nextID
+	p.pending[req.ID] = resC
+	err := p.enc.Encode(req)
+	if err != nil {
+		delete(p.pending, req.ID)
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return Response{}, fmt.Errorf("error sending %s request to %s plugin: %s", req.Method, p.Path, err)
+	}
+
+	var res Response
+	select {
+	case res = <-resC:
+	case <-p.readDone:
+		p.forget(req.ID)
+		return Response{}, fmt.Errorf("error receiving %s response from %s plugin: %s", req.Method, p.Path, p.readErr)
+	case <-ctx.Done():
+		p.forget(req.ID)
+		return Response{}, fmt.Errorf("%s call to %s plugin stopped: %s", req.Method, p.Path, ctx.Err())
+	}
+	if res.Error != "" {
+		return res, errors.New(res.Error)
+	}
+	return res, nil
+}
+
+// forget stops waiting for the response of a request.
+func (p *Plugin) forget(id uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, id)
+}
+
+// Attacks returns the attacks the plugin serves.
+func (p *Plugin) Attacks() ([]attack.Info, error) {
+	res, err := p.call(context.Background(), Request{Method: DescribeMethod})
+	if err != nil {
+		return nil, err
+	}
+	return res.Attacks, nil
+}
+
+// Register registers the attacks of the plugin on the registry, the attack IDs
+// can't be already registered.
+func (p *Plugin) Register(reg attack.Registry) ([]string, error) {
+	infos, err := p.Attacks()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check all before registering any.
+	for _, info := range infos {
+		if reg.Exists(info.ID) {
+			return nil, fmt.Errorf("%s attack of %s plugin is already registered", info.ID, p.Path)
+		}
+	}
+
+	ids := make([]string, len(infos))
+	for i, info := range infos {
+		id := info.ID
+		c := attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+			return p.create(id, o)
+		})
+		if err := reg.Register(id, attack.NewSchemaCreator(info.Description, info.Schema, c)); err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// create creates a new attack instance on the plugin.
+func (p *Plugin) create(id string, opts attack.Opts) (*Attack, error) {
+	a := &Attack{
+		ID:     id,
+		opts:   opts,
+		plugin: p,
+		log:    p.log.With("attack", id),
+	}
+	if err := a.createInstance(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Close closes the plugin input so it exits, if the plugin doesn't exit in time
+// it will be killed. Returns the exit error of the plugin.
+func (p *Plugin) Close() error {
+	p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(closeTimeout):
+		p.log.Warnf("plugin didn't exit in time, killing it")
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+	return p.err
+}
+
+// Attack is an attack served by a plugin, the calls are proxied to the plugin.
+type Attack struct {
+	ID string // The ID of the attack.
+
+	opts     attack.Opts   // The options of the attack instances.
+	instance string        // The ID of the attack instance on the plugin, empty once reverted.
+	plugin   *Plugin       // The plugin serving the attack.
+	stopC    chan struct{} // Channel used to stop watching the context.
+	ctxRevC  chan error    // Channel with the result of the revert after the context cancellation.
+	mu       sync.Mutex
+	log      log.Logger // Logger.
+}
+
+// createInstance creates the attack instance on the plugin.
+func (a *Attack) createInstance() error {
+	res, err := a.plugin.call(context.Background(), Request{Method: CreateMethod, Attack: a.ID, Opts: a.opts})
+	if err != nil {
+		return err
+	}
+	a.instance = res.Instance
+	return nil
+}
+
+// Apply will apply the attack on the plugin, the attack will be reverted when
+// the context is cancelled. The attack can be reverted while being applied.
+func (a *Attack) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	// Don't hold the lock while applying so a blocked apply doesn't block the revert.
+	a.mu.Lock()
+	if a.stopC != nil {
+		a.mu.Unlock()
+		return errors.New("plugin attack already applied")
+	}
+	// The plugin forgets the reverted instances, create a new one to apply again.
+	if a.instance == "" {
+		if err := a.createInstance(); err != nil {
+			a.mu.Unlock()
+			return err
+		}
+	}
+	stopC := make(chan struct{})
+	a.stopC = stopC
+	a.ctxRevC = nil
+	instance := a.instance
+	a.mu.Unlock()
+
+	if _, err := a.plugin.call(ctx, Request{Method: ApplyMethod, Instance: instance}); err != nil {
+		// The plugin could still be applying the attack, revert it unless it
+		// has already been reverted.
+		if revC := a.claimRevert(stopC); revC != nil && ctx.Err() != nil {
+			go a.revertOnPlugin(instance, revC)
+		}
+		return err
+	}
+
+	// Revert on the plugin when the context is done, the plugin can't see the context.
+	go func() {
+		select {
+		case <-stopC:
+		case <-ctx.Done():
+			if revC := a.claimRevert(stopC); revC != nil {
+				a.revertOnPlugin(instance, revC)
+			}
+		}
+	}()
+
+	a.log.With("instance", instance).Infof("plugin attack applied")
+	return nil
+}
+
+// claimRevert claims the revert of the apply that set stopC returning the channel
+// for its result, returns nil if the attack has already been reverted so the revert
+// is only sent once.
+func (a *Attack) claimRevert(stopC chan struct{}) chan error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopC != stopC {
+		return nil
+	}
+	a.stopC = nil
+	a.ctxRevC = make(chan error, 1)
+	return a.ctxRevC
+}
+
+// revertOnPlugin reverts the attack on the plugin after the context cancellation
+// logging the error, the result is left for the next revert.
+func (a *Attack) revertOnPlugin(instance string, revC chan<- error) {
+	err := a.revertInstance(context.Background(), instance)
+	if err != nil {
+		a.log.With("instance", instance).Errorf("error reverting plugin attack after the context cancellation: %s", err)
+	}
+	revC <- err
+}
+
+// revertInstance reverts the attack instance on the plugin, once reverted the
+// plugin forgets the instance.
+func (a *Attack) revertInstance(ctx context.Context, instance string) error {
+	if _, err := a.plugin.call(ctx, Request{Method: RevertMethod, Instance: instance}); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	if a.instance == instance {
+		a.instance = ""
+	}
+	a.mu.Unlock()
+	return nil
+}
+
+// Revert will revert the attack on the plugin.
+func (a *Attack) Revert() error {
+	return a.RevertContext(context.Background())
+}
+
+// RevertContext satisfies attack.ContextReverter interface. It will stop waiting
+// for the plugin when the context is done.
+func (a *Attack) RevertContext(ctx context.Context) error {
+	a.mu.Lock()
+	if a.stopC != nil {
+		close(a.stopC)
+		a.stopC = nil
+	}
+	revC := a.ctxRevC
+	a.ctxRevC = nil
+	instance := a.instance
+	a.mu.Unlock()
+
+	// If the attack is being reverted after the context cancellation wait for it
+	// instead of reverting again, only revert again if it failed.
+	if revC != nil {
+		select {
+		case err := <-revC:
+			if err == nil {
+				a.log.Infof("plugin attack reverted")
+				return nil
+			}
+		case <-ctx.Done():
+			a.mu.Lock()
+			if a.ctxRevC == nil {
+				a.ctxRevC = revC
+			}
+			a.mu.Unlock()
+			return fmt.Errorf("revert of %s plugin attack stopped: %s", a.ID, ctx.Err())
+		}
+	}
+
+	// Already reverted.
+	if instance == "" {
+		return nil
+	}
+
+	if err := a.revertInstance(ctx, instance); err != nil {
+		return err
+	}
+	a.log.With("instance", instance).Infof("plugin attack reverted")
+	return nil
+}
+
+// Load will start all the plugin executables of a directory (sorted by name) and
+// register their attacks on the registry. If any of the plugins fails all the
+// started plugins will be closed and their attacks deregistered.
+func Load(dir string, reg attack.Registry) ([]*Plugin, error) {
+	fs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ps         []*Plugin
+		registered []string
+	)
+	closeAll := func() {
+		for _, id := range registered {
+			reg.Deregister(id)
+		}
+		for _, p := range ps {
+			p.Close()
+		}
+	}
+
+	for _, f := range fs {
+		// Only executable files are plugins.
+		if !f.Mode().IsRegular() || f.Mode()&0111 == 0 {
+			continue
+		}
+
+		p, err := Start(filepath.Join(dir, f.Name()))
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		ps = append(ps, p)
+
+		ids, err := p.Register(reg)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		registered = append(registered, ids...)
+		p.log.With("attacks", ids).Infof("plugin loaded")
+	}
+
+	return ps, nil
+}
@@ -0,0 +1,295 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+const (
+	testPluginEnv      = "RAGNAROK_TEST_PLUGIN"
+	testPluginAttackID = "test_plugin_attack"
+)
+
+var testPluginSchema = attack.Schema{
+	{Name: "path", Type: attack.StringOptType, Required: true, Description: "The file created while the attack is applied."},
+	{Name: "fail", Type: attack.BoolOptType, Default: false, Description: "Fail when applying."},
+	{Name: "hang", Type: attack.BoolOptType, Default: false, Description: "Block when applying until the context is done."},
+}
+
+// testAttack creates a file while applied so the tests can check the plugin state.
+type testAttack struct {
+	path string
+	fail bool
+	hang bool
+}
+
+func (t *testAttack) Apply(ctx context.Context) error {
+	if t.fail {
+		return errors.New("wanted error")
+	}
+	if t.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if err := ioutil.WriteFile(t.path, nil, 0644); err != nil {
+		return err
+	}
+	// Revert when the context is done like the real attacks.
+	go func() {
+		<-ctx.Done()
+		os.Remove(t.path)
+	}()
+	return nil
+}
+
+func (t *testAttack) Revert() error {
+	os.Remove(t.path)
+	return nil
+}
+
+// TestMain will run the test binary as a plugin when required.
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) == "1" {
+		reg := attack.NewSimpleRegistry()
+		reg.Register(testPluginAttackID, attack.NewSchemaCreator("Test plugin attack.", testPluginSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+			return &testAttack{path: o["path"].(string), fail: o["fail"].(bool), hang: o["hang"].(bool)}, nil
+		})))
+		if err := Serve(reg, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testPluginDir returns a plugin directory with the test plugin.
+func testPluginDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "ragnarok-plugins")
+	require.NoError(t, err)
+	script := fmt.Sprintf("#!/bin/sh\n%s=1 exec %s\n", testPluginEnv, os.Args[0])
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "test-plugin"), []byte(script), 0755))
+	// Not executable files should be ignored.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0644))
+	return dir
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+
+	reg := attack.NewSimpleRegistry()
+	ps, err := Load(dir, reg)
+	require.NoError(err)
+	require.Len(ps, 1)
+	defer ps[0].Close()
+
+	expInfo := []attack.Info{
+		{ID: testPluginAttackID, Description: "Test plugin attack.", Schema: testPluginSchema},
+	}
+	assert.Equal(expInfo, reg.List())
+
+	// Apply and revert through the plugin.
+	path := filepath.Join(dir, "applied")
+	a, err := reg.New(testPluginAttackID, attack.Opts{"path": path})
+	require.NoError(err)
+	require.NoError(a.Apply(context.Background()))
+	assert.True(exists(path))
+	assert.Error(a.Apply(context.Background()), "applying twice should error")
+	require.NoError(a.Revert())
+	assert.False(exists(path))
+
+	// Reapply after revert.
+	require.NoError(a.Apply(context.Background()))
+	assert.True(exists(path))
+	require.NoError(a.Revert())
+}
+
+func TestLoadAlreadyRegistered(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+
+	reg := attack.NewSimpleRegistry()
+	builtin := attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) { return nil, nil })
+	reg.Register(testPluginAttackID, builtin)
+
+	ps, err := Load(dir, reg)
+	assert.Error(err)
+	assert.Nil(ps)
+	assert.Len(reg.List(), 1)
+}
+
+func TestPluginAttackErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         attack.Opts
+		expCreateErr bool
+		expApplyErr  bool
+	}{
+		{
+			name:         "Creating an attack without the required options should error.",
+			opts:         attack.Opts{},
+			expCreateErr: true,
+		},
+		{
+			name:        "An attack failing on the plugin should error.",
+			opts:        attack.Opts{"path": "/tmp/ragnarok-plugin-fail", "fail": true},
+			expApplyErr: true,
+		},
+	}
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+	reg := attack.NewSimpleRegistry()
+	ps, err := Load(dir, reg)
+	require.NoError(t, err)
+	defer ps[0].Close()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			a, err := reg.New(testPluginAttackID, test.opts)
+			if test.expCreateErr {
+				assert.Error(err)
+				return
+			}
+			if assert.NoError(err) {
+				err := a.Apply(context.Background())
+				assert.Equal(test.expApplyErr, err != nil)
+			}
+		})
+	}
+}
+
+func TestPluginAttackStopsOnContextCancel(t *testing.T) {
+	require := require.New(t)
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+	reg := attack.NewSimpleRegistry()
+	ps, err := Load(dir, reg)
+	require.NoError(err)
+	defer ps[0].Close()
+
+	path := filepath.Join(dir, "applied")
+	a, err := reg.New(testPluginAttackID, attack.Opts{"path": path})
+	require.NoError(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(a.Apply(ctx))
+	require.True(exists(path))
+	cancel()
+
+	for i := 0; exists(path); i++ {
+		if i > 100 {
+			require.Fail("plugin attack wasn't reverted after the context cancellation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(a.Revert())
+}
+
+func TestPluginAttackContextCancelAndRevert(t *testing.T) {
+	require := require.New(t)
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+	reg := attack.NewSimpleRegistry()
+	ps, err := Load(dir, reg)
+	require.NoError(err)
+	defer ps[0].Close()
+
+	// The revert after the context cancellation should only be sent once.
+	for i := 0; i < 20; i++ {
+		a, err := reg.New(testPluginAttackID, attack.Opts{"path": filepath.Join(dir, "applied")})
+		require.NoError(err)
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(a.Apply(ctx))
+		cancel()
+		require.NoError(a.Revert())
+	}
+}
+
+func TestPluginAttackRevertWhileApplying(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+	reg := attack.NewSimpleRegistry()
+	ps, err := Load(dir, reg)
+	require.NoError(err)
+	defer ps[0].Close()
+
+	a, err := reg.New(testPluginAttackID, attack.Opts{"path": filepath.Join(dir, "applied"), "hang": true})
+	require.NoError(err)
+
+	// The apply blocks on the plugin until the context is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	applyC := make(chan error, 1)
+	go func() {
+		applyC <- a.Apply(ctx)
+	}()
+
+	// The blocked apply shouldn't block the revert.
+	revertC := make(chan error, 1)
+	go func() {
+		revertC <- a.Revert()
+	}()
+	select {
+	case err := <-revertC:
+		assert.NoError(err)
+	case <-time.After(5 * time.Second):
+		require.Fail("plugin attack revert blocked by the apply")
+	}
+
+	cancel()
+	select {
+	case err := <-applyC:
+		assert.Error(err)
+	case <-time.After(5 * time.Second):
+		require.Fail("plugin attack apply didn't return after the context cancellation")
+	}
+}
+
+func TestPluginClose(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := testPluginDir(t)
+	defer os.RemoveAll(dir)
+	reg := attack.NewSimpleRegistry()
+	ps, err := Load(dir, reg)
+	require.NoError(err)
+
+	path := filepath.Join(dir, "applied")
+	a, err := reg.New(testPluginAttackID, attack.Opts{"path": path})
+	require.NoError(err)
+	require.NoError(a.Apply(context.Background()))
+
+	// Closing the plugin should revert its attacks.
+	assert.NoError(ps[0].Close())
+	assert.False(exists(path))
+	assert.Error(a.Revert())
+}
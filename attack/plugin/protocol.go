@@ -0,0 +1,59 @@
+/*
+Package plugin has the out of process attacks. A plugin is an executable that
+serves attacks over its standard input and output using a protocol of JSON
+messages, one per line.
+
+The node can send a request without waiting for the responses of the previous
+ones, the plugin handles the requests concurrently and the responses can arrive
+in any order, they are matched with their requests by id:
+
+	{"id":1,"method":"describe"}
+	{"id":1,"attacks":[{"id":"my_attack","description":"...","schema":[...]}]}
+	{"id":2,"method":"create","attack":"my_attack","opts":{"size":1024}}
+	{"id":2,"instance":"1"}
+	{"id":3,"method":"apply","instance":"1"}
+	{"id":3}
+	{"id":4,"method":"revert","instance":"1"}
+	{"id":4,"error":"something failed"}
+
+The plugin needs to exit when its standard input is closed, this way the plugins
+will finish with the node. The standard error of the plugin is used as its log.
+The Serve function implements the plugin side of the protocol for any attack
+registry, so a plugin only needs to register its attacks and serve them.
+*/
+package plugin
+
+import (
+	"github.com/slok/ragnarok/attack"
+)
+
+// Method is a method of the plugin protocol.
+type Method string
+
+const (
+	// DescribeMethod returns the attacks the plugin serves.
+	DescribeMethod Method = "describe"
+	// CreateMethod creates a new attack instance with options.
+	CreateMethod Method = "create"
+	// ApplyMethod applies an attack instance.
+	ApplyMethod Method = "apply"
+	// RevertMethod reverts an attack instance.
+	RevertMethod Method = "revert"
+)
+
+// Request is a request sent to a plugin.
+type Request struct {
+	ID       uint64      `json:"id"`                 // ID is the identifier of the request.
+	Method   Method      `json:"method"`             // Method is the method called.
+	Attack   string      `json:"attack,omitempty"`   // Attack is the ID of the attack to create.
+	Opts     attack.Opts `json:"opts,omitempty"`     // Opts are the options of the attack to create.
+	Instance string      `json:"instance,omitempty"` // Instance is the ID of the attack instance to apply or revert.
+}
+
+// Response is the response of a plugin to a request.
+type Response struct {
+	ID       uint64        `json:"id"`                 // ID is the identifier of the request.
+	Error    string        `json:"error,omitempty"`    // Error is the error of the call, empty if succeeded.
+	Attacks  []attack.Info `json:"attacks,omitempty"`  // Attacks are the attacks served by the plugin.
+	Instance string        `json:"instance,omitempty"` // Instance is the ID of the created attack instance.
+}
@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// server serves the attacks of a registry using the plugin protocol.
+type server struct {
+	reg       attack.Registry
+	instances map[string]attack.Attacker // The created attack instances.
+	nextID    uint64
+	ctx       context.Context // The context used to apply the attacks.
+	mu        sync.Mutex      // Used to protect the instances, the requests are handled concurrently.
+}
+
+// Serve serves the attacks of the registry using the plugin protocol, reading
+// the requests from r and writing the responses to w (usually the standard
+// input and output of the plugin). The requests are handled concurrently so
+// a blocked attack doesn't block the others. It will serve until r is closed,
+// then it will revert all the attack instances.
+func Serve(reg attack.Registry, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &server{
+		reg:       reg,
+		instances: map[string]attack.Attacker{},
+		ctx:       ctx,
+	}
+
+	var (
+		wg     sync.WaitGroup
+		encMu  sync.Mutex
+		encErr error // The first error writing a response.
+	)
+	defer func() {
+		// Stop the running applies before reverting.
+		cancel()
+		wg.Wait()
+		for _, a := range s.instances {
+			a.Revert()
+		}
+	}()
+
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+	for {
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		encMu.Lock()
+		err := encErr
+		encMu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		go func(req Request) {
+			defer wg.Done()
+			res := s.handle(req)
+			res.ID = req.ID
+
+			encMu.Lock()
+			defer encMu.Unlock()
+			if err := enc.Encode(res); err != nil && encErr == nil {
+				encErr = err
+			}
+		}(req)
+	}
+}
+
+// handle handles a request.
+func (s *server) handle(req Request) Response {
+	var res Response
+	switch req.Method {
+	case DescribeMethod:
+		res.Attacks = s.reg.List()
+	case CreateMethod:
+		a, err := s.reg.New(req.Attack, req.Opts)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		s.mu.Lock()
+		s.nextID++
+		res.Instance = strconv.FormatUint(s.nextID, 10)
+		s.instances[res.Instance] = a
+		s.mu.Unlock()
+	case ApplyMethod, RevertMethod:
+		s.mu.Lock()
+		a, ok := s.instances[req.Instance]
+		s.mu.Unlock()
+		if !ok {
+			return Response{Error: fmt.Sprintf("%s attack instance doesn't exist", req.Instance)}
+		}
+		var err error
+		if req.Method == ApplyMethod {
+			err = a.Apply(s.ctx)
+		} else {
+			err = a.Revert()
+		}
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		// The reverted instances aren't used anymore.
+		if req.Method == RevertMethod {
+			s.mu.Lock()
+			delete(s.instances, req.Instance)
+			s.mu.Unlock()
+		}
+	default:
+		return Response{Error: fmt.Sprintf("unknown %s method", req.Method)}
+	}
+	return res
+}
@@ -0,0 +1,453 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// KillID is the identifier of the attack
+	KillID = "process_kill"
+
+	// Options
+	nameKey     = "name"
+	cmdlineKey  = "cmdline"
+	pidfileKey  = "pidfile"
+	signalKey   = "signal"
+	intervalKey = "interval"
+
+	stoppedStateKey = "stopped"
+)
+
+// procPath is the path of the proc filesystem.
+var procPath = "/proc"
+
+// sendSignal sends a signal to a process.
+var sendSignal = syscall.Kill
+
+// signals are the signals that can be sent to the processes.
+var signals = map[string]syscall.Signal{
+	"SIGKILL": syscall.SIGKILL,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGSTOP": syscall.SIGSTOP,
+}
+
+// killSchema is the schema of the attack options.
+var killSchema = attack.Schema{
+	{Name: nameKey, Type: attack.StringOptType, Description: "The name of the target processes, exclusive with cmdline and pidfile."},
+	{Name: cmdlineKey, Type: attack.StringOptType, Description: "The regex of the target processes command line, exclusive with name and pidfile."},
+	{Name: pidfileKey, Type: attack.StringOptType, Description: "The pidfile of the target process, exclusive with name and cmdline."},
+	{Name: signalKey, Type: attack.StringOptType, Default: "SIGKILL", Description: "The signal sent to the processes: SIGKILL, SIGTERM or SIGSTOP."},
+	{Name: intervalKey, Type: attack.DurationOptType, Description: "The interval of the signals, by default the signal is sent only once."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(KillID, attack.NewSchemaCreator("Sends signals to processes once or on an interval.", killSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewKillOpts(o)
+	})))
+}
+
+// Finder returns the PIDs of the target processes.
+type Finder interface {
+	Find() ([]int, error)
+}
+
+// FinderFunc implements Finder interface as a handy way of creating finders quickly.
+type FinderFunc func() ([]int, error)
+
+// Find implements Finder.
+func (f FinderFunc) Find() ([]int, error) {
+	return f()
+}
+
+// Kill failer will apply a failure sending signals to processes, it can send the signal
+// once or repeatedly on an interval.
+type Kill struct {
+	Finder   Finder         // The finder of the target processes.
+	Signal   syscall.Signal // The signal sent to the processes.
+	Interval time.Duration  // The interval of the signals, 0 will send the signal only once.
+
+	stopped map[int]struct{} // The processes that have been stopped and need to be continued.
+	stopC   chan struct{}    // Channel used to stop the signaling loop.
+	wg      sync.WaitGroup   // Used to wait until the signaling loop has finished.
+	applied bool             // Flag that marks the attack has been applied.
+	mu      sync.Mutex
+	log     log.Logger // Logger.
+}
+
+// NewKillOpts returns a new process kill failer using options.
+func NewKillOpts(opts attack.Opts) (*Kill, error) {
+	// Get the target finder.
+	var finders []Finder
+	if v, ok := opts[nameKey]; ok {
+		name, ok := v.(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", nameKey, v)
+		}
+		finders = append(finders, NameFinder(name))
+	}
+	if v, ok := opts[cmdlineKey]; ok {
+		expr, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", cmdlineKey, v)
+		}
+		rgx, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s' option regex: %s", cmdlineKey, err)
+		}
+		finders = append(finders, CmdlineFinder(rgx))
+	}
+	if v, ok := opts[pidfileKey]; ok {
+		path, ok := v.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", pidfileKey, v)
+		}
+		finders = append(finders, PIDFileFinder(path))
+	}
+	if len(finders) != 1 {
+		return nil, fmt.Errorf("one of '%s', '%s' or '%s' options is required", nameKey, cmdlineKey, pidfileKey)
+	}
+
+	// Get the signal.
+	sig := syscall.SIGKILL
+	if v, ok := opts[signalKey]; ok {
+		s, _ := v.(string)
+		if sig, ok = signals[strings.ToUpper(s)]; !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", signalKey, v)
+		}
+	}
+
+	// Get the interval.
+	var interval time.Duration
+	if v, ok := opts[intervalKey]; ok {
+		s, _ := v.(string)
+		var err error
+		if interval, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", intervalKey, v)
+		}
+	}
+
+	return NewKill(finders[0], sig, interval)
+}
+
+// NewKill returns a new process kill failer.
+func NewKill(finder Finder, sig syscall.Signal, interval time.Duration) (*Kill, error) {
+	if finder == nil {
+		return nil, fmt.Errorf("finder is required")
+	}
+
+	if interval < 0 {
+		return nil, fmt.Errorf("interval can't be negative")
+	}
+
+	return &Kill{
+		Finder:   finder,
+		Signal:   sig,
+		Interval: interval,
+		stopped:  map[int]struct{}{},
+		log:      log.Base(),
+	}, nil
+}
+
+// Apply will send the signal to the target processes, if there is an interval
+// it will keep sending the signal until the context is cancelled or the attack
+// is reverted.
+func (k *Kill) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.applied {
+		return errors.New("process kill already applied")
+	}
+
+	n, err := k.signal()
+	if err != nil {
+		// The attack is not applied so it will not be reverted, don't leave the
+		// processes signaled before the error stopped.
+		if cErr := k.continueStopped(); cErr != nil {
+			k.log.Error(cErr)
+		}
+		return err
+	}
+	if n == 0 {
+		return errors.New("no target processes found")
+	}
+	k.applied = true
+
+	if k.Interval > 0 {
+		k.stopC = make(chan struct{})
+		k.wg.Add(1)
+		go k.loop(ctx, k.stopC)
+	}
+
+	return nil
+}
+
+// loop will signal the processes on every interval.
+func (k *Kill) loop(ctx context.Context, stopC chan struct{}) {
+	defer k.wg.Done()
+
+	t := time.NewTicker(k.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopC:
+			return
+		case <-t.C:
+			k.mu.Lock()
+			n, err := k.signal()
+			k.mu.Unlock()
+			if err != nil {
+				k.log.Errorf("error signaling processes: %s", err)
+			} else if n == 0 {
+				k.log.Warnf("no target processes found")
+			}
+		}
+	}
+}
+
+// signal will find the processes and send them the signal, returns the number of
+// signaled processes.
+func (k *Kill) signal() (int, error) {
+	pids, err := k.Finder.Find()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	self := os.Getpid()
+	for _, pid := range pids {
+		if pid == self {
+			continue
+		}
+		if err := sendSignal(pid, k.Signal); err != nil {
+			// The process could have finished between the find and the signal.
+			if err != syscall.ESRCH {
+				return n, fmt.Errorf("error sending %s to %d: %s", k.Signal, pid, err)
+			}
+			continue
+		}
+		if k.Signal == syscall.SIGSTOP {
+			k.stopped[pid] = struct{}{}
+		}
+		n++
+		k.log.With("pid", pid).Infof("sent %s to process", k.Signal)
+	}
+	return n, nil
+}
+
+// Revert will stop sending signals and continue the stopped processes.
+func (k *Kill) Revert() error {
+	// Stop the loop without the lock, the loop needs it to finish.
+	k.mu.Lock()
+	stopC := k.stopC
+	k.stopC = nil
+	k.mu.Unlock()
+	if stopC != nil {
+		close(stopC)
+		k.wg.Wait()
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.applied = false
+	return k.continueStopped()
+}
+
+// continueStopped continues the stopped processes, the ones that can't be
+// continued are kept so a new revert can retry.
+func (k *Kill) continueStopped() error {
+	var errs []string
+	for pid := range k.stopped {
+		if err := sendSignal(pid, syscall.SIGCONT); err != nil && err != syscall.ESRCH {
+			errs = append(errs, fmt.Sprintf("%d: %s", pid, err))
+			continue
+		}
+		delete(k.stopped, pid)
+		k.log.With("pid", pid).Infof("sent %s to process", syscall.SIGCONT)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error continuing processes: %v", errs)
+	}
+	return nil
+}
+
+// Finders.
+
+// listPIDs returns all the PIDs from the proc filesystem.
+func listPIDs() ([]int, error) {
+	fs, err := ioutil.ReadDir(procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, f := range fs {
+		pid, err := strconv.Atoi(f.Name())
+		if err != nil || !f.IsDir() {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+// readCmdline returns the command line arguments of a process.
+func readCmdline(pid int) ([]string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimRight(b, "\x00")
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(b), "\x00"), nil
+}
+
+// NameFinder returns a finder that will find the processes by its exact name, the
+// name can be the process name or the base of the executable.
+func NameFinder(name string) Finder {
+	return FinderFunc(func() ([]int, error) {
+		pids, err := listPIDs()
+		if err != nil {
+			return nil, err
+		}
+
+		var res []int
+		for _, pid := range pids {
+			// Ignore the errors, the process could be finished.
+			comm, err := ioutil.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "comm"))
+			if err != nil {
+				continue
+			}
+			if strings.TrimSpace(string(comm)) == name {
+				res = append(res, pid)
+				continue
+			}
+			args, err := readCmdline(pid)
+			if err == nil && len(args) > 0 && filepath.Base(args[0]) == name {
+				res = append(res, pid)
+			}
+		}
+		return res, nil
+	})
+}
+
+// CmdlineFinder returns a finder that will find the processes whose command line
+// (arguments joined with spaces) matches the regex.
+func CmdlineFinder(rgx *regexp.Regexp) Finder {
+	return FinderFunc(func() ([]int, error) {
+		pids, err := listPIDs()
+		if err != nil {
+			return nil, err
+		}
+
+		var res []int
+		for _, pid := range pids {
+			// Ignore the errors, the process could be finished.
+			args, err := readCmdline(pid)
+			if err != nil || len(args) == 0 {
+				continue
+			}
+			if rgx.MatchString(strings.Join(args, " ")) {
+				res = append(res, pid)
+			}
+		}
+		return res, nil
+	})
+}
+
+// PIDFileFinder returns a finder that will find the process using a PID file.
+func PIDFileFinder(path string) Finder {
+	return FinderFunc(func() ([]int, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid file %s: %s", path, err)
+		}
+		return []int{pid}, nil
+	})
+}
+
+// RecoveryState satisfies attack.Recoverer interface.
+func (k *Kill) RecoveryState() attack.Opts {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	pids := []int{}
+	for pid := range k.stopped {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return attack.Opts{stoppedStateKey: pids}
+}
+
+// Recover satisfies attack.Recoverer interface. The stopped processes and the current
+// target processes are continued, the processes stopped after the state was saved
+// are targets too. If the targets can't be found only the stopped processes are
+// continued.
+func (k *Kill) Recover(state attack.Opts) error {
+	if k.Signal != syscall.SIGSTOP {
+		return nil
+	}
+
+	k.mu.Lock()
+	switch st := state[stoppedStateKey].(type) {
+	case []interface{}:
+		for _, p := range st {
+			if pid, ok := p.(float64); ok {
+				k.stopped[int(pid)] = struct{}{}
+			}
+		}
+	case []int:
+		for _, pid := range st {
+			k.stopped[pid] = struct{}{}
+		}
+	}
+	k.mu.Unlock()
+
+	pids, err := k.Finder.Find()
+	if err != nil {
+		k.log.Warnf("could not find the target processes, only the stopped ones will be continued: %s", err)
+	}
+
+	k.mu.Lock()
+	self := os.Getpid()
+	for _, pid := range pids {
+		if pid != self {
+			k.stopped[pid] = struct{}{}
+		}
+	}
+	k.mu.Unlock()
+
+	return k.Revert()
+}
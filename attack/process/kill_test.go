@@ -0,0 +1,347 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// startChild starts a child process that will sleep and will be killed at the end of the test.
+func startChild(t *testing.T, args ...string) *exec.Cmd {
+	cmd := exec.Command("sleep", args...)
+	require.NoError(t, cmd.Start())
+	return cmd
+}
+
+// waitChild returns the wait channel of the child.
+func waitChild(cmd *exec.Cmd) chan error {
+	c := make(chan error, 1)
+	go func() { c <- cmd.Wait() }()
+	return c
+}
+
+// procState returns the state of a process from the proc filesystem.
+func procState(t *testing.T, pid int) string {
+	b, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	require.NoError(t, err)
+	// The state is the field after the command name (that is between parentheses).
+	fs := strings.Fields(string(b[strings.LastIndex(string(b), ")")+1:]))
+	return fs[0]
+}
+
+// waitState waits until the process is in the state or the timeout is reached.
+func waitState(t *testing.T, pid int, stopped bool) bool {
+	timeout := time.After(time.Second)
+	for {
+		if (procState(t, pid) == "T") == stopped {
+			return true
+		}
+		select {
+		case <-timeout:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func writePIDFile(t *testing.T, dir string, pid int) string {
+	path := filepath.Join(dir, "test.pid")
+	require.NoError(t, ioutil.WriteFile(path, []byte(strconv.Itoa(pid)+"\n"), 0644))
+	return path
+}
+
+func TestKillCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        attack.Opts
+		expSignal   syscall.Signal
+		expInterval time.Duration
+		expErr      bool
+	}{
+		{
+			name:      "Creating a kill by name should use SIGKILL once by default.",
+			opts:      attack.Opts{"name": "nginx"},
+			expSignal: syscall.SIGKILL,
+		},
+		{
+			name:        "Creating a kill by cmdline with signal and interval should use them.",
+			opts:        attack.Opts{"cmdline": "^java .*kafka", "signal": "SIGSTOP", "interval": "1m"},
+			expSignal:   syscall.SIGSTOP,
+			expInterval: time.Minute,
+		},
+		{
+			name:      "Creating a kill by pidfile should be valid.",
+			opts:      attack.Opts{"pidfile": "/var/run/nginx.pid", "signal": "sigterm"},
+			expSignal: syscall.SIGTERM,
+		},
+		{
+			name:   "Creating a kill without target should error.",
+			opts:   attack.Opts{"signal": "SIGKILL"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a kill with multiple targets should error.",
+			opts:   attack.Opts{"name": "nginx", "pidfile": "/var/run/nginx.pid"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a kill with an invalid regex should error.",
+			opts:   attack.Opts{"cmdline": "(nginx"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a kill with an invalid signal should error.",
+			opts:   attack.Opts{"name": "nginx", "signal": "SIGPWN"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a kill with an invalid interval should error.",
+			opts:   attack.Opts{"name": "nginx", "interval": 10},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			k, err := NewKillOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expSignal, k.Signal)
+				assert.Equal(test.expInterval, k.Interval)
+			}
+		})
+	}
+}
+
+func TestFinders(t *testing.T) {
+	require := require.New(t)
+
+	// Create a fake proc filesystem.
+	dir, err := ioutil.TempDir("", "ragnarok-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	procs := map[string][2]string{
+		"10": {"nginx\n", "/usr/sbin/nginx\x00-g\x00daemon off;\x00"},
+		"20": {"java\n", "java\x00-jar\x00kafka.jar\x00"},
+		"30": {"kworker/0:1\n", ""},
+		"40": {"nginx-ex\n", "/usr/local/bin/nginx-exporter\x00"},
+	}
+	for pid, p := range procs {
+		require.NoError(os.Mkdir(filepath.Join(dir, pid), 0755))
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, pid, "comm"), []byte(p[0]), 0644))
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, pid, "cmdline"), []byte(p[1]), 0644))
+	}
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "self"), nil, 0644))
+	defer func(p string) { procPath = p }(procPath)
+	procPath = dir
+
+	tests := []struct {
+		name    string
+		finder  Finder
+		expPIDs []int
+	}{
+		{
+			name:    "Finding by name should match the exact process name.",
+			finder:  NameFinder("nginx"),
+			expPIDs: []int{10},
+		},
+		{
+			name:    "Finding by name should match the executable name.",
+			finder:  NameFinder("nginx-exporter"),
+			expPIDs: []int{40},
+		},
+		{
+			name:    "Finding by cmdline should match the regex.",
+			finder:  CmdlineFinder(regexp.MustCompile(`-jar kafka`)),
+			expPIDs: []int{20},
+		},
+		{
+			name:    "Finding by cmdline should match multiple processes.",
+			finder:  CmdlineFinder(regexp.MustCompile(`nginx`)),
+			expPIDs: []int{10, 40},
+		},
+		{
+			name:    "Finding by cmdline without matches shouldn't return processes.",
+			finder:  CmdlineFinder(regexp.MustCompile(`postgres`)),
+			expPIDs: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			pids, err := test.finder.Find()
+			if assert.NoError(err) {
+				sort.Ints(pids)
+				assert.Equal(test.expPIDs, pids)
+			}
+		})
+	}
+}
+
+func TestKillOnce(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cmd := startChild(t, "60")
+	defer cmd.Process.Kill()
+	waitC := waitChild(cmd)
+
+	k, err := NewKill(PIDFileFinder(writePIDFile(t, dir, cmd.Process.Pid)), syscall.SIGKILL, 0)
+	require.NoError(err)
+	require.NoError(k.Apply(context.Background()))
+
+	select {
+	case <-time.After(2 * time.Second):
+		require.Fail("process should be killed")
+	case err := <-waitC:
+		assert.Error(err)
+	}
+	assert.NoError(k.Revert())
+}
+
+func TestKillNoTargets(t *testing.T) {
+	k, err := NewKill(FinderFunc(func() ([]int, error) { return nil, nil }), syscall.SIGKILL, 0)
+	require.NoError(t, err)
+	assert.Error(t, k.Apply(context.Background()))
+}
+
+func TestKillStopAndContinue(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cmd := startChild(t, "61.2345")
+	defer cmd.Process.Kill()
+	waitChild(cmd)
+
+	k, err := NewKill(CmdlineFinder(regexp.MustCompile(`^sleep 61\.2345$`)), syscall.SIGSTOP, 0)
+	require.NoError(err)
+	require.NoError(k.Apply(context.Background()))
+	assert.True(waitState(t, cmd.Process.Pid, true), "process should be stopped")
+
+	require.NoError(k.Revert())
+	assert.True(waitState(t, cmd.Process.Pid, false), "process should be continued")
+}
+
+func TestKillStopErrorContinuesStopped(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cmd := startChild(t, "61.4567")
+	defer cmd.Process.Kill()
+	waitChild(cmd)
+
+	// The second target can't be signaled.
+	const badPID = 1 << 30
+	defer func(f func(int, syscall.Signal) error) { sendSignal = f }(sendSignal)
+	sendSignal = func(pid int, sig syscall.Signal) error {
+		if pid == badPID {
+			return syscall.EPERM
+		}
+		return syscall.Kill(pid, sig)
+	}
+
+	k, err := NewKill(FinderFunc(func() ([]int, error) { return []int{cmd.Process.Pid, badPID}, nil }), syscall.SIGSTOP, 0)
+	require.NoError(err)
+	assert.Error(k.Apply(context.Background()))
+	assert.True(waitState(t, cmd.Process.Pid, false), "process should be continued after the apply error")
+	assert.Empty(k.stopped)
+}
+
+func TestKillRepeatedly(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	cmd1 := startChild(t, "60")
+	defer cmd1.Process.Kill()
+	waitC1 := waitChild(cmd1)
+
+	k, err := NewKill(PIDFileFinder(writePIDFile(t, dir, cmd1.Process.Pid)), syscall.SIGKILL, 10*time.Millisecond)
+	require.NoError(err)
+	require.NoError(k.Apply(context.Background()))
+	<-waitC1
+
+	// A new process should be killed on the next interval.
+	cmd2 := startChild(t, "60")
+	defer cmd2.Process.Kill()
+	waitC2 := waitChild(cmd2)
+	writePIDFile(t, dir, cmd2.Process.Pid)
+	select {
+	case <-time.After(2 * time.Second):
+		require.Fail("process should be killed")
+	case err := <-waitC2:
+		assert.Error(err)
+	}
+	assert.NoError(k.Revert())
+}
+
+func TestKillRecover(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// One process stopped by the previous attacker and other that is not a target anymore.
+	cmd1 := startChild(t, "61.3456")
+	defer cmd1.Process.Kill()
+	waitChild(cmd1)
+	cmd2 := startChild(t, "61.4567")
+	defer cmd2.Process.Kill()
+	waitChild(cmd2)
+	require.NoError(syscall.Kill(cmd1.Process.Pid, syscall.SIGSTOP))
+	require.NoError(syscall.Kill(cmd2.Process.Pid, syscall.SIGSTOP))
+	require.True(waitState(t, cmd1.Process.Pid, true))
+	require.True(waitState(t, cmd2.Process.Pid, true))
+
+	// The state is recovered from JSON by a new attacker.
+	var state attack.Opts
+	require.NoError(json.Unmarshal([]byte(fmt.Sprintf(`{"stopped":[%d]}`, cmd2.Process.Pid)), &state))
+	k, err := NewKill(CmdlineFinder(regexp.MustCompile(`^sleep 61\.3456$`)), syscall.SIGSTOP, 0)
+	require.NoError(err)
+
+	require.NoError(k.Recover(state))
+	assert.True(waitState(t, cmd1.Process.Pid, false), "target process should be continued")
+	assert.True(waitState(t, cmd2.Process.Pid, false), "stopped process should be continued")
+}
+
+func TestKillRecoverFindError(t *testing.T) {
+	require := require.New(t)
+
+	cmd := startChild(t, "61.5678")
+	defer cmd.Process.Kill()
+	waitChild(cmd)
+	require.NoError(syscall.Kill(cmd.Process.Pid, syscall.SIGSTOP))
+	require.True(waitState(t, cmd.Process.Pid, true))
+
+	// The targets can't be found (e.g. the pid file is missing after the crash).
+	k, err := NewKill(FinderFunc(func() ([]int, error) { return nil, errors.New("wanted error") }), syscall.SIGSTOP, 0)
+	require.NoError(err)
+
+	require.NoError(k.Recover(attack.Opts{"stopped": []int{cmd.Process.Pid}}))
+	assert.True(t, waitState(t, cmd.Process.Pid, false), "stopped process should be continued")
+}
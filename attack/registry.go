@@ -3,6 +3,7 @@ package attack
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/slok/ragnarok/log"
 )
@@ -26,6 +27,49 @@ func (c CreatorFunc) Create(opts Opts) (Attacker, error) {
 	return c(opts)
 }
 
+// SchemaCreater is a Creater that declares the description of the attack and
+// the schema of its options, the registry will use the schema to validate and
+// decode the options before creating the attacker.
+type SchemaCreater interface {
+	Creater
+	Description() string
+	Schema() Schema
+}
+
+// schemaCreator implements SchemaCreater.
+type schemaCreator struct {
+	Creater
+	description string
+	schema      Schema
+}
+
+// NewSchemaCreator returns a new creater with the description of the attack and
+// the schema of its options.
+func NewSchemaCreator(description string, s Schema, c Creater) SchemaCreater {
+	return &schemaCreator{
+		Creater:     c,
+		description: description,
+		schema:      s,
+	}
+}
+
+// Description implements SchemaCreater.
+func (s *schemaCreator) Description() string {
+	return s.description
+}
+
+// Schema implements SchemaCreater.
+func (s *schemaCreator) Schema() Schema {
+	return s.schema
+}
+
+// Info is the information of a registered attack.
+type Info struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	Schema      Schema `json:"schema,omitempty"`
+}
+
 // Registry interface stuff.
 
 // Registry is an interface that needs to be implemented by any attack registry.
@@ -33,6 +77,8 @@ type Registry interface {
 	Register(id string, c Creater) error
 	Deregister(id string) error
 	Exists(id string) bool
+	Validate(id string, opts Opts) error
+	List() []Info
 	New(id string, opts Opts) (Attacker, error)
 }
 
@@ -70,13 +116,53 @@ func (r SimpleRegistry) Exists(id string) bool {
 	return ok
 }
 
-// New is the factory of the attacks based on IDs and options.
-func (r SimpleRegistry) New(id string, opts Opts) (Attacker, error) {
+// decode returns the creator of the attack and the options decoded with the
+// schema of the creator (if it has one).
+func (r SimpleRegistry) decode(id string, opts Opts) (Creater, Opts, error) {
 	c, ok := r[id]
 	if !ok {
-		return nil, fmt.Errorf("%s is not a correct Attack", id)
+		return nil, nil, fmt.Errorf("%s is not a correct Attack", id)
 	}
-	return c.Create(opts)
+
+	sc, ok := c.(SchemaCreater)
+	if !ok {
+		return c, opts, nil
+	}
+	dOpts, err := sc.Schema().Decode(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s attack options: %s", id, err)
+	}
+	return c, dOpts, nil
+}
+
+// Validate checks the attack exists and the options are valid for its schema.
+func (r SimpleRegistry) Validate(id string, opts Opts) error {
+	_, _, err := r.decode(id, opts)
+	return err
+}
+
+// List returns the information of all the registered attacks sorted by ID.
+func (r SimpleRegistry) List() []Info {
+	res := make([]Info, 0, len(r))
+	for id, c := range r {
+		info := Info{ID: id}
+		if sc, ok := c.(SchemaCreater); ok {
+			info.Description = sc.Description()
+			info.Schema = sc.Schema()
+		}
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// New is the factory of the attacks based on IDs and options.
+func (r SimpleRegistry) New(id string, opts Opts) (Attacker, error) {
+	c, dOpts, err := r.decode(id, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.Create(dOpts)
 }
 
 // Global tools for the main registry.
@@ -104,6 +190,16 @@ func Exists(id string) bool {
 	return baseReg.Exists(id)
 }
 
+// Validate validates the options of an attack on the base registry.
+func Validate(id string, opts Opts) error {
+	return baseReg.Validate(id, opts)
+}
+
+// List returns the information of all the attacks on the base registry.
+func List() []Info {
+	return baseReg.List()
+}
+
 // New is the factory method of attackers on the base registry.
 func New(id string, opts Opts) (Attacker, error) {
 	return baseReg.New(id, opts)
@@ -112,3 +112,74 @@ func TestFactory(t *testing.T) {
 	}
 
 }
+
+func TestFactoryWithSchema(t *testing.T) {
+	schema := attack.Schema{
+		{Name: "size", Type: attack.SizeOptType, Required: true},
+		{Name: "interval", Type: attack.DurationOptType, Default: "1s"},
+	}
+
+	tests := []struct {
+		name      string
+		opts      attack.Opts
+		expOpts   attack.Opts
+		expCreate bool
+		expErr    bool
+	}{
+		{
+			name:      "Creating an attack should decode the options with the schema.",
+			opts:      attack.Opts{"size": "1KiB"},
+			expOpts:   attack.Opts{"size": 1024, "interval": "1s"},
+			expCreate: true,
+		},
+		{
+			name:   "Creating an attack with invalid options should error without creating it.",
+			opts:   attack.Opts{"size": "1KiB", "unknown": true},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			m := &mattack.Creater{}
+			if test.expCreate {
+				m.On("Create", test.expOpts).Once().Return(&mattack.Attacker{}, nil)
+			}
+			r := attack.NewSimpleRegistry()
+			r.Register("test", attack.NewSchemaCreator("test attack", schema, m))
+
+			assert.Equal(test.expErr, r.Validate("test", test.opts) != nil)
+			_, err := r.New("test", test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidateMissingAttack(t *testing.T) {
+	r := attack.NewSimpleRegistry()
+	assert.Error(t, r.Validate("missing", attack.Opts{}))
+}
+
+func TestList(t *testing.T) {
+	assert := assert.New(t)
+
+	schema := attack.Schema{
+		{Name: "size", Type: attack.SizeOptType, Required: true, Description: "The size."},
+	}
+	r := attack.NewSimpleRegistry()
+	r.Register("test2", attack.NewSchemaCreator("test attack 2", schema, &mattack.Creater{}))
+	r.Register("test1", &mattack.Creater{})
+
+	expInfo := []attack.Info{
+		{ID: "test1"},
+		{ID: "test2", Description: "test attack 2", Schema: schema},
+	}
+	assert.Equal(expInfo, r.List())
+}
@@ -0,0 +1,25 @@
+package attack
+
+import "context"
+
+// RevertContext reverts an attack returning when the revert finishes or the context
+// is done. The attacks that don't implement ContextReverter are adapted: their revert
+// can't be stopped, it will keep running on background until it returns.
+func RevertContext(ctx context.Context, a Attacker) error {
+	if cr, ok := a.(ContextReverter); ok {
+		return cr.RevertContext(ctx)
+	}
+
+	// Buffered so the revert finishes even if nobody is waiting for it.
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Revert()
+	}()
+
+	select {
+	case err := <-errC:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
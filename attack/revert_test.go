@@ -0,0 +1,50 @@
+package attack_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/slok/ragnarok/attack"
+	mattack "github.com/slok/ragnarok/mocks/attack"
+)
+
+type contextReverterAttacker struct {
+	*mattack.Attacker
+	*mattack.ContextReverter
+}
+
+func TestRevertContext(t *testing.T) {
+	assert := assert.New(t)
+
+	// Context aware attacks receive the context.
+	ctx := context.Background()
+	ca := contextReverterAttacker{Attacker: &mattack.Attacker{}, ContextReverter: &mattack.ContextReverter{}}
+	ca.ContextReverter.On("RevertContext", ctx).Once().Return(errors.New("wanted error"))
+	assert.Error(attack.RevertContext(ctx, ca))
+	ca.ContextReverter.AssertExpectations(t)
+	ca.Attacker.AssertNotCalled(t, "Revert")
+
+	// Not context aware attacks are adapted.
+	a := &mattack.Attacker{}
+	a.On("Revert").Once().Return(nil)
+	assert.NoError(attack.RevertContext(ctx, a))
+	a.AssertExpectations(t)
+}
+
+func TestRevertContextDone(t *testing.T) {
+	assert := assert.New(t)
+
+	// The revert blocks until the test finishes.
+	releaseC := make(chan struct{})
+	defer close(releaseC)
+	a := &mattack.Attacker{}
+	a.On("Revert").Once().Return(nil).Run(func(mock.Arguments) { <-releaseC })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(context.Canceled, attack.RevertContext(ctx, a))
+}
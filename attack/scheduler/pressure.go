@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// PressureID is the identifier of the attack
+	PressureID = "scheduler_pressure"
+
+	// Options
+	goroutinesKey = "goroutines"
+	threadsKey    = "threads"
+	spinKey       = "spin"
+
+	// MaxThreads is the maximum number of locked threads the attack can create,
+	// the Go runtime will crash the process if it reaches 10000 threads so we
+	// leave room for the ones used by the application.
+	MaxThreads = 8000
+)
+
+// pressureSchema is the schema of the attack options.
+var pressureSchema = attack.Schema{
+	{Name: goroutinesKey, Type: attack.IntOptType, Description: "The number of goroutines that will be created."},
+	{Name: threadsKey, Type: attack.IntOptType, Description: "The number of goroutines locked to their own OS thread that will be created."},
+	{Name: spinKey, Type: attack.BoolOptType, Default: false, Description: "Spin instead of blocking."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(PressureID, attack.NewSchemaCreator("Puts pressure on the Go scheduler with goroutines and locked OS threads.", pressureSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewPressureOpts(o)
+	})))
+}
+
+// Pressure failer will apply a failure creating goroutines and locked OS threads
+// that pressure the scheduler and the thread limits of the process.
+type Pressure struct {
+	Goroutines int  // The number of goroutines that will be created.
+	Threads    int  // The number of goroutines locked to their own OS thread that will be created.
+	Spin       bool // If true the goroutines and threads will spin instead of blocking.
+
+	stopC   chan struct{}  // Channel used to stop the goroutines.
+	wg      sync.WaitGroup // Used to wait until all the goroutines have finished.
+	running bool           // Flag that marks the goroutines are running.
+	mu      sync.Mutex
+	log     log.Logger // Logger.
+}
+
+// NewPressureOpts returns a new scheduler pressure failer using options. The
+// missing goroutines or threads will be 0 and by default they will block.
+func NewPressureOpts(opts attack.Opts) (*Pressure, error) {
+	var goroutines, threads int
+	var spin bool
+	if g, ok := opts[goroutinesKey]; ok {
+		if goroutines, ok = g.(int); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", goroutinesKey, g)
+		}
+	}
+
+	if t, ok := opts[threadsKey]; ok {
+		if threads, ok = t.(int); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", threadsKey, t)
+		}
+	}
+
+	if s, ok := opts[spinKey]; ok {
+		if spin, ok = s.(bool); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", spinKey, s)
+		}
+	}
+
+	return NewPressure(goroutines, threads, spin)
+}
+
+// NewPressure returns a new scheduler pressure failer.
+func NewPressure(goroutines, threads int, spin bool) (*Pressure, error) {
+	if goroutines < 0 || threads < 0 {
+		return nil, fmt.Errorf("goroutines and threads can't be negative")
+	}
+
+	if goroutines == 0 && threads == 0 {
+		return nil, fmt.Errorf("at least goroutines or threads need to be set")
+	}
+
+	if threads > MaxThreads {
+		return nil, fmt.Errorf("threads can't be more than %d", MaxThreads)
+	}
+
+	return &Pressure{
+		Goroutines: goroutines,
+		Threads:    threads,
+		Spin:       spin,
+		log:        log.Base(),
+	}, nil
+}
+
+// Apply will start the goroutines and the locked threads, it will return once
+// all the threads have been locked. They will run until the context is
+// cancelled or the attack is reverted.
+func (p *Pressure) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return errors.New("scheduler pressure already applied")
+	}
+
+	p.stopC = make(chan struct{})
+	// Wait until all the threads have been locked.
+	var locked sync.WaitGroup
+	locked.Add(p.Threads)
+	for i := 0; i < p.Threads; i++ {
+		p.wg.Add(1)
+		go p.run(ctx, p.stopC, &locked)
+	}
+	locked.Wait()
+
+	for i := 0; i < p.Goroutines; i++ {
+		p.wg.Add(1)
+		go p.run(ctx, p.stopC, nil)
+	}
+	p.running = true
+	p.log.With("goroutines", p.Goroutines).With("threads", p.Threads).With("spin", p.Spin).Infof("scheduler pressure started")
+	return nil
+}
+
+// run will block or spin until stopped. If locked is set the goroutine will be
+// wired to its own OS thread, the thread is not unlocked when finishing so the
+// runtime terminates it instead of reusing it.
+func (p *Pressure) run(ctx context.Context, stopC chan struct{}, locked *sync.WaitGroup) {
+	defer p.wg.Done()
+	if locked != nil {
+		runtime.LockOSThread()
+		locked.Done()
+	}
+
+	if !p.Spin {
+		select {
+		case <-ctx.Done():
+		case <-stopC:
+		}
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopC:
+			return
+		default:
+		}
+		// Yield so the spinning goroutines keep the run queues busy.
+		runtime.Gosched()
+	}
+}
+
+// Revert will stop all the goroutines and threads and wait until they have finished.
+func (p *Pressure) Revert() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running {
+		return nil
+	}
+
+	close(p.stopC)
+	p.wg.Wait()
+	p.running = false
+	p.log.With("goroutines", p.Goroutines).With("threads", p.Threads).Infof("reverted scheduler pressure")
+	return nil
+}
@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"io/ioutil"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// threads returns the number of OS threads of the process.
+func threads(t *testing.T) int {
+	tasks, err := ioutil.ReadDir("/proc/self/task")
+	require.NoError(t, err)
+	return len(tasks)
+}
+
+// waitCount waits until the count function returns a value that satisfies the
+// condition or the timeout is reached, returns the last value.
+func waitCount(count func() int, cond func(int) bool) int {
+	c := count()
+	for start := time.Now(); !cond(c) && time.Since(start) < 2*time.Second; c = count() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return c
+}
+
+func TestPressureCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          attack.Opts
+		expGoroutines int
+		expThreads    int
+		expSpin       bool
+		expErr        bool
+	}{
+		{
+			name:          "Creating a pressure with all the options should use them.",
+			opts:          attack.Opts{"goroutines": 1000, "threads": 10, "spin": true},
+			expGoroutines: 1000,
+			expThreads:    10,
+			expSpin:       true,
+		},
+		{
+			name:          "Creating a pressure only with goroutines should use defaults.",
+			opts:          attack.Opts{"goroutines": 1000},
+			expGoroutines: 1000,
+		},
+		{
+			name:   "Creating a pressure without goroutines and threads should error.",
+			opts:   attack.Opts{},
+			expErr: true,
+		},
+		{
+			name:   "Creating a pressure with invalid goroutines type should error.",
+			opts:   attack.Opts{"goroutines": "10"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a pressure with negative threads should error.",
+			opts:   attack.Opts{"goroutines": 10, "threads": -1},
+			expErr: true,
+		},
+		{
+			name:   "Creating a pressure with more threads than the maximum should error.",
+			opts:   attack.Opts{"threads": MaxThreads + 1},
+			expErr: true,
+		},
+		{
+			name:   "Creating a pressure with invalid spin type should error.",
+			opts:   attack.Opts{"threads": 10, "spin": "true"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p, err := NewPressureOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expGoroutines, p.Goroutines)
+				assert.Equal(test.expThreads, p.Threads)
+				assert.Equal(test.expSpin, p.Spin)
+			}
+		})
+	}
+}
+
+func TestPressureApplyRevert(t *testing.T) {
+	tests := []struct {
+		name string
+		spin bool
+	}{
+		{
+			name: "Applying a blocking pressure should create the goroutines and threads and revert should stop them.",
+			spin: false,
+		},
+		{
+			name: "Applying a spinning pressure should create the goroutines and threads and revert should stop them.",
+			spin: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			goroutines, ths := 200, 20
+			p, err := NewPressure(goroutines, ths, test.spin)
+			require.NoError(err)
+
+			startGoroutines := runtime.NumGoroutine()
+
+			require.NoError(p.Apply(context.Background()))
+			assert.Error(p.Apply(context.Background()), "applying twice should error")
+			assert.True(runtime.NumGoroutine() >= startGoroutines+goroutines+ths)
+			// Every locked goroutine has its own thread plus the ones for the rest.
+			peakThreads := threads(t)
+			assert.True(peakThreads > ths, "locked threads should be created")
+
+			require.NoError(p.Revert())
+			got := waitCount(runtime.NumGoroutine, func(c int) bool { return c <= startGoroutines })
+			assert.True(got <= startGoroutines, "goroutines should be stopped")
+			got = waitCount(func() int { return threads(t) }, func(c int) bool { return c < peakThreads })
+			assert.True(got < peakThreads, "locked threads should be terminated")
+
+			// Should be able to be applied again after a revert.
+			require.NoError(p.Apply(context.Background()))
+			assert.NoError(p.Revert())
+		})
+	}
+}
+
+func TestPressureStopsOnContextCancel(t *testing.T) {
+	require := require.New(t)
+
+	p, err := NewPressure(10, 2, false)
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(p.Apply(ctx))
+	cancel()
+
+	// Goroutines should finish by themselves.
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-time.After(1 * time.Second):
+		require.Fail("goroutines didn't stop after the context cancellation")
+	case <-done:
+	}
+	require.NoError(p.Revert())
+}
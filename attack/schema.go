@@ -0,0 +1,280 @@
+package attack
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OptType is the type of an attack option.
+type OptType string
+
+const (
+	// StringOptType is a string option.
+	StringOptType OptType = "string"
+	// IntOptType is an integer option, it will be decoded as an int.
+	IntOptType OptType = "int"
+	// FloatOptType is a floating point option, it will be decoded as a float64.
+	FloatOptType OptType = "float"
+	// BoolOptType is a boolean option.
+	BoolOptType OptType = "bool"
+	// SizeOptType is a size in bytes, it accepts numbers or strings with units
+	// ("512MiB", "1GB"), it will be decoded as an int.
+	SizeOptType OptType = "size"
+	// DurationOptType is a duration, it accepts duration strings ("30s"), it
+	// will be decoded as a string.
+	DurationOptType OptType = "duration"
+	// StringListOptType is a list of strings, it will be decoded as a []string.
+	StringListOptType OptType = "stringList"
+	// ListOptType is a list of any kind of values, it will be decoded as a
+	// []interface{} with the integer numbers as ints.
+	ListOptType OptType = "list"
+)
+
+// sizeUnits are the units accepted by the size options.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// OptSpec is the specification of an attack option.
+type OptSpec struct {
+	Name        string      `json:"name"`                  // Name is the key of the option.
+	Type        OptType     `json:"type"`                  // Type is the type of the option.
+	Default     interface{} `json:"default,omitempty"`     // Default is the value used when the option is missing, nil means no default.
+	Required    bool        `json:"required,omitempty"`    // Required marks the option as required.
+	Description string      `json:"description,omitempty"` // Description is the description of the option.
+}
+
+// Schema is the specification of the options of an attack.
+type Schema []OptSpec
+
+// Decode will validate the options and coerce them to the types the attack
+// creators expect, the missing options will be set with their default value.
+// Decoding already decoded options returns the same options.
+func (s Schema) Decode(opts Opts) (Opts, error) {
+	specs := map[string]OptSpec{}
+	for _, spec := range s {
+		specs[spec.Name] = spec
+	}
+
+	// Check unknown options, sorted so the errors are deterministic.
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, ok := specs[k]; !ok {
+			return nil, fmt.Errorf("unknown '%s' option", k)
+		}
+	}
+
+	res := Opts{}
+	for _, spec := range s {
+		v, ok := opts[spec.Name]
+		if !ok || v == nil {
+			if spec.Required {
+				return nil, fmt.Errorf("missing required '%s' option", spec.Name)
+			}
+			if spec.Default == nil {
+				continue
+			}
+			v = spec.Default
+		}
+
+		dv, err := decodeOpt(spec.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value: %s", spec.Name, v, err)
+		}
+		res[spec.Name] = dv
+	}
+
+	return res, nil
+}
+
+// decodeOpt coerces an option value to the type.
+func decodeOpt(t OptType, v interface{}) (interface{}, error) {
+	switch t {
+	case StringOptType:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("not a string")
+		}
+		return s, nil
+	case IntOptType:
+		return decodeInt(v)
+	case FloatOptType:
+		return decodeFloat(v)
+	case BoolOptType:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strconv.ParseBool(b)
+		}
+		return nil, fmt.Errorf("not a boolean")
+	case SizeOptType:
+		return decodeSize(v)
+	case DurationOptType:
+		switch d := v.(type) {
+		case time.Duration:
+			return d.String(), nil
+		case string:
+			if _, err := time.ParseDuration(d); err != nil {
+				return nil, err
+			}
+			return d, nil
+		}
+		return nil, fmt.Errorf("not a duration")
+	case StringListOptType:
+		switch l := v.(type) {
+		case []string:
+			return l, nil
+		case []interface{}:
+			res := make([]string, len(l))
+			for i, e := range l {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("not a string list")
+				}
+				res[i] = s
+			}
+			return res, nil
+		}
+		return nil, fmt.Errorf("not a string list")
+	case ListOptType:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("not a list")
+		}
+		res := make([]interface{}, rv.Len())
+		for i := range res {
+			res[i] = normalizeNumbers(rv.Index(i).Interface())
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unknown '%s' option type", t)
+}
+
+// decodeInt coerces a number or a numeric string to an int.
+func decodeInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int8:
+		return int(n), nil
+	case int16:
+		return int(n), nil
+	case int32:
+		return int(n), nil
+	case int64:
+		return int(n), nil
+	case uint:
+		return int(n), nil
+	case uint8:
+		return int(n), nil
+	case uint16:
+		return int(n), nil
+	case uint32:
+		return int(n), nil
+	case uint64:
+		return int(n), nil
+	case float32:
+		return decodeInt(float64(n))
+	case float64:
+		if n != math.Trunc(n) || math.IsInf(n, 0) {
+			return 0, fmt.Errorf("not an integer")
+		}
+		return int(n), nil
+	case string:
+		return strconv.Atoi(strings.TrimSpace(n))
+	}
+	return 0, fmt.Errorf("not an integer")
+}
+
+// decodeFloat coerces a number or a numeric string to a float64.
+func decodeFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(n), 64)
+	}
+	i, err := decodeInt(v)
+	if err != nil {
+		return 0, fmt.Errorf("not a number")
+	}
+	return float64(i), nil
+}
+
+// decodeSize coerces a number or a size string with units to an int of bytes.
+func decodeSize(v interface{}) (int, error) {
+	s, ok := v.(string)
+	if !ok {
+		return decodeInt(v)
+	}
+
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s'", s)
+	}
+	unit, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit '%s'", s[i:])
+	}
+	return int(n * float64(unit)), nil
+}
+
+// normalizeNumbers returns the value with the integer floats (like the ones
+// decoded from JSON) converted to ints and the maps (like the ones decoded from
+// YAML) with string keys, the lists and maps are normalized recursively.
+func normalizeNumbers(v interface{}) interface{} {
+	switch n := v.(type) {
+	case float64:
+		if n == math.Trunc(n) && !math.IsInf(n, 0) {
+			return int(n)
+		}
+		return n
+	case []interface{}:
+		res := make([]interface{}, len(n))
+		for i, e := range n {
+			res[i] = normalizeNumbers(e)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(n))
+		for k, e := range n {
+			res[k] = normalizeNumbers(e)
+		}
+		return res
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(n))
+		for k, e := range n {
+			res[fmt.Sprint(k)] = normalizeNumbers(e)
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+package attack_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+func TestSchemaDecode(t *testing.T) {
+	schema := attack.Schema{
+		{Name: "name", Type: attack.StringOptType, Required: true},
+		{Name: "count", Type: attack.IntOptType, Default: 1},
+		{Name: "ratio", Type: attack.FloatOptType},
+		{Name: "enabled", Type: attack.BoolOptType, Default: false},
+		{Name: "size", Type: attack.SizeOptType},
+		{Name: "timeout", Type: attack.DurationOptType, Default: "30s"},
+		{Name: "tags", Type: attack.StringListOptType},
+		{Name: "items", Type: attack.ListOptType},
+	}
+
+	tests := []struct {
+		name    string
+		opts    attack.Opts
+		expOpts attack.Opts
+		expErr  bool
+	}{
+		{
+			name: "Decoding only the required options should set the defaults.",
+			opts: attack.Opts{"name": "test"},
+			expOpts: attack.Opts{
+				"name":    "test",
+				"count":   1,
+				"enabled": false,
+				"timeout": "30s",
+			},
+		},
+		{
+			name: "Decoding JSON options should coerce them.",
+			opts: attack.Opts{
+				"name":    "test",
+				"count":   float64(10),
+				"ratio":   float64(2),
+				"enabled": true,
+				"size":    float64(1024),
+				"timeout": "1m",
+				"tags":    []interface{}{"a", "b"},
+				"items":   []interface{}{float64(1), "2-3", map[string]interface{}{"percent": float64(50), "ratio": 0.5}},
+			},
+			expOpts: attack.Opts{
+				"name":    "test",
+				"count":   10,
+				"ratio":   float64(2),
+				"enabled": true,
+				"size":    1024,
+				"timeout": "1m",
+				"tags":    []string{"a", "b"},
+				"items":   []interface{}{1, "2-3", map[string]interface{}{"percent": 50, "ratio": 0.5}},
+			},
+		},
+		{
+			name: "Decoding YAML options should coerce them.",
+			opts: attack.Opts{
+				"name":  "test",
+				"count": int64(10),
+				"items": []interface{}{map[interface{}]interface{}{"percent": 50}},
+			},
+			expOpts: attack.Opts{
+				"name":    "test",
+				"count":   10,
+				"enabled": false,
+				"timeout": "30s",
+				"items":   []interface{}{map[string]interface{}{"percent": 50}},
+			},
+		},
+		{
+			name: "Decoding string options should coerce them.",
+			opts: attack.Opts{
+				"name":    "test",
+				"count":   "10",
+				"ratio":   "0.5",
+				"enabled": "true",
+				"timeout": 2 * time.Second,
+			},
+			expOpts: attack.Opts{
+				"name":    "test",
+				"count":   10,
+				"ratio":   0.5,
+				"enabled": true,
+				"timeout": "2s",
+			},
+		},
+		{
+			name:    "Decoding binary unit sizes should coerce them to bytes.",
+			opts:    attack.Opts{"name": "test", "size": "512MiB"},
+			expOpts: attack.Opts{"name": "test", "count": 1, "enabled": false, "size": 512 * 1024 * 1024, "timeout": "30s"},
+		},
+		{
+			name:    "Decoding decimal unit sizes should coerce them to bytes.",
+			opts:    attack.Opts{"name": "test", "size": "1.5 GB"},
+			expOpts: attack.Opts{"name": "test", "count": 1, "enabled": false, "size": 1500 * 1000 * 1000, "timeout": "30s"},
+		},
+		{
+			name:   "Decoding without a required option should error.",
+			opts:   attack.Opts{"count": 1},
+			expErr: true,
+		},
+		{
+			name:   "Decoding an unknown option should error.",
+			opts:   attack.Opts{"name": "test", "Count": 1},
+			expErr: true,
+		},
+		{
+			name:   "Decoding a not integer number on an int option should error.",
+			opts:   attack.Opts{"name": "test", "count": 1.5},
+			expErr: true,
+		},
+		{
+			name:   "Decoding an invalid size unit should error.",
+			opts:   attack.Opts{"name": "test", "size": "10 parsecs"},
+			expErr: true,
+		},
+		{
+			name:   "Decoding an invalid duration should error.",
+			opts:   attack.Opts{"name": "test", "timeout": 30},
+			expErr: true,
+		},
+		{
+			name:   "Decoding an invalid string list should error.",
+			opts:   attack.Opts{"name": "test", "tags": []interface{}{"a", 1}},
+			expErr: true,
+		},
+		{
+			name:   "Decoding an invalid list should error.",
+			opts:   attack.Opts{"name": "test", "items": "a"},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			opts, err := schema.Decode(test.opts)
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			if assert.NoError(err) {
+				assert.Equal(test.expOpts, opts)
+
+				// Decoding again should return the same options.
+				opts2, err := schema.Decode(opts)
+				assert.NoError(err)
+				assert.Equal(opts, opts2)
+			}
+		})
+	}
+}
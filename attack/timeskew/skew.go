@@ -0,0 +1,137 @@
+package timeskew
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/clock"
+	"github.com/slok/ragnarok/log"
+)
+
+const (
+	// SkewID is the identifier of the attack
+	SkewID = "time_skew"
+
+	// Options
+	offsetKey = "offset"
+	pathKey   = "path"
+)
+
+// skewSchema is the schema of the attack options.
+var skewSchema = attack.Schema{
+	{Name: offsetKey, Type: attack.DurationOptType, Required: true, Description: "The offset that will be applied to the clocks."},
+	{Name: pathKey, Type: attack.StringOptType, Default: clock.DefaultSkewPath, Description: "The file where the offset is published."},
+}
+
+// Register the creator of the attack
+func init() {
+	attack.Register(SkewID, attack.NewSchemaCreator("Publishes a clock offset that skews the time of the services using it.", skewSchema, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		return NewSkewOpts(o)
+	})))
+}
+
+// Skew failer will apply a failure publishing a clock offset, the services using
+// the skew clock (clock.NewSkew) on the same path will see their time skewed.
+type Skew struct {
+	Offset time.Duration // The offset that will be applied to the clocks.
+	Path   string        // The file where the offset is published.
+
+	applied bool // Flag that marks the offset was already published.
+	mu      sync.Mutex
+	log     log.Logger // Logger.
+}
+
+// NewSkewOpts returns a new time skew failer using options. If the path is
+// missing it will use the default skew clock path.
+func NewSkewOpts(opts attack.Opts) (*Skew, error) {
+	o, ok := opts[offsetKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value", offsetKey, opts[offsetKey])
+	}
+	offset, err := time.ParseDuration(o)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s' option with '%v' value: %s", offsetKey, o, err)
+	}
+
+	path := clock.DefaultSkewPath
+	if p, ok := opts[pathKey]; ok {
+		if path, ok = p.(string); !ok {
+			return nil, fmt.Errorf("invalid '%s' option with '%v' value", pathKey, p)
+		}
+	}
+
+	return NewSkew(offset, path)
+}
+
+// NewSkew returns a new time skew failer.
+func NewSkew(offset time.Duration, path string) (*Skew, error) {
+	if offset == 0 {
+		return nil, fmt.Errorf("offset can't be 0")
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("path can't be empty")
+	}
+
+	return &Skew{
+		Offset: offset,
+		Path:   path,
+		log:    log.Base(),
+	}, nil
+}
+
+// Apply will publish the offset.
+func (s *Skew) Apply(ctx context.Context) error {
+	// Check if its done before continuing.
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.applied {
+		return errors.New("time skew already applied")
+	}
+
+	if err := clock.WriteSkewOffset(s.Path, s.Offset); err != nil {
+		return err
+	}
+	s.applied = true
+	s.log.With("offset", s.Offset).With("path", s.Path).Infof("time skew published")
+	return nil
+}
+
+// Revert will reset the published offset to zero.
+func (s *Skew) Revert() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.applied {
+		return nil
+	}
+
+	if err := clock.WriteSkewOffset(s.Path, 0); err != nil {
+		return err
+	}
+	s.applied = false
+	s.log.With("path", s.Path).Infof("reverted time skew")
+	return nil
+}
+
+// RecoveryState satisfies attack.Recoverer interface.
+func (s *Skew) RecoveryState() attack.Opts {
+	return nil
+}
+
+// Recover satisfies attack.Recoverer interface.
+func (s *Skew) Recover(state attack.Opts) error {
+	s.mu.Lock()
+	s.applied = true
+	s.mu.Unlock()
+	return s.Revert()
+}
@@ -0,0 +1,144 @@
+package timeskew
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/clock"
+)
+
+func TestSkewCreationWithOpts(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      attack.Opts
+		expOffset time.Duration
+		expPath   string
+		expErr    bool
+	}{
+		{
+			name:      "Creating a skew with all the options should use them.",
+			opts:      attack.Opts{"offset": "-2h", "path": "/tmp/skew"},
+			expOffset: -2 * time.Hour,
+			expPath:   "/tmp/skew",
+		},
+		{
+			name:      "Creating a skew without path should use the default.",
+			opts:      attack.Opts{"offset": "720h"},
+			expOffset: 720 * time.Hour,
+			expPath:   clock.DefaultSkewPath,
+		},
+		{
+			name:   "Creating a skew without offset should error.",
+			opts:   attack.Opts{},
+			expErr: true,
+		},
+		{
+			name:   "Creating a skew with an invalid offset should error.",
+			opts:   attack.Opts{"offset": "yesterday"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a skew with a 0 offset should error.",
+			opts:   attack.Opts{"offset": "0s"},
+			expErr: true,
+		},
+		{
+			name:   "Creating a skew with an invalid path should error.",
+			opts:   attack.Opts{"offset": "1h", "path": 1},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			s, err := NewSkewOpts(test.opts)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expOffset, s.Offset)
+				assert.Equal(test.expPath, s.Path)
+			}
+		})
+	}
+}
+
+func TestSkewApplyRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-time-skew-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "skew")
+
+	s, err := NewSkew(48*time.Hour, path)
+	require.NoError(err)
+	clk := clock.NewSkew(path)
+
+	require.NoError(s.Apply(context.Background()))
+	assert.Error(s.Apply(context.Background()), "applying twice should error")
+	offset, err := clock.ReadSkewOffset(path)
+	require.NoError(err)
+	assert.Equal(48*time.Hour, offset)
+	assert.WithinDuration(time.Now().Add(48*time.Hour), clk.Now(), time.Second)
+
+	require.NoError(s.Revert())
+	offset, err = clock.ReadSkewOffset(path)
+	require.NoError(err)
+	assert.Equal(time.Duration(0), offset)
+
+	// Should be able to be applied again after a revert.
+	require.NoError(s.Apply(context.Background()))
+	assert.NoError(s.Revert())
+}
+
+func TestSkewApplyCancelledContext(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-time-skew-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "skew")
+
+	s, err := NewSkew(time.Hour, path)
+	require.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(s.Apply(ctx))
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err), "offset shouldn't be published")
+}
+
+func TestSkewRecover(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-time-skew-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "skew")
+
+	s, err := NewSkew(48*time.Hour, path)
+	require.NoError(err)
+	require.NoError(s.Apply(context.Background()))
+
+	// A new attacker should reset the offset.
+	rs, err := NewSkew(48*time.Hour, path)
+	require.NoError(err)
+	require.NoError(rs.Recover(s.RecoveryState()))
+	offset, err := clock.ReadSkewOffset(path)
+	require.NoError(err)
+	assert.Equal(time.Duration(0), offset)
+}
@@ -32,6 +32,14 @@ the same time, taking this into account this will the responsability of the atta
 
 configuraiton/defition examples can be check on failure/testdata path
 
+Stages:
+
+The attacks of a failure are applied at the same time, when the attacks need an order a failure
+can have stages. The stages are applied one after another once the failure attacks have been
+applied, every stage can wait a delay before being applied, last less than the failure timeout
+and ramp the intensity of its attacks on steps, for example: 'slow the disk, after 2m burn 20%
+of the CPU and increase it 20% every minute until 100%'.
+
 Experiment:
 
 On the other part we have the name Experiment, an experiment is only a group of failures that
@@ -0,0 +1,112 @@
+package injection
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/slok/ragnarok/api/chaos/v1"
+)
+
+// newRand returns the random generator of the activation, seeded with the
+// activation seed if there is one.
+func newRand(act *v1.Activation) *rand.Rand {
+	seed := time.Now().UnixNano()
+	if act != nil && act.Seed != 0 {
+		seed = act.Seed
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// activation decides if the failure attacks will be applied and the delay before
+// applying them. The probability is decided before the delay so the decisions
+// are the same for the same seed.
+func (i *Injection) activation() (time.Duration, bool) {
+	act := i.Spec.Activation
+	if act == nil {
+		return 0, true
+	}
+
+	if act.Probability != nil && i.rnd.Float64() >= *act.Probability {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if act.StartWindow > 0 {
+		delay = time.Duration(i.rnd.Int63n(int64(act.StartWindow)))
+	}
+	return delay, true
+}
+
+// flapping returns true if the failure attacks need to be applied and reverted
+// periodically.
+func (i *Injection) flapping() bool {
+	act := i.Spec.Activation
+	return act != nil && act.On > 0 && act.Off > 0
+}
+
+// runActivation applies the failure attacks after the delay and starts the stages,
+// when flapping it will keep reverting and applying the attacks until the context
+// is done.
+func (i *Injection) runActivation(ctx context.Context, delay time.Duration) {
+	defer i.wg.Done()
+
+	if delay > 0 {
+		i.log.Infof("'%s' failure attacks will be applied in %s", i.Metadata.ID, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.clock.After(delay):
+		}
+	}
+
+	if err := i.applyAttacks(ctx, i.attacks); err != nil {
+		i.failBackground(err)
+		return
+	}
+
+	// Start applying the stages.
+	if len(i.stages) > 0 {
+		i.wg.Add(1)
+		go i.runStages(ctx)
+	}
+
+	if !i.flapping() {
+		return
+	}
+
+	act := i.Spec.Activation
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.clock.After(act.On):
+		}
+		if err := i.revertAttacks(i.attacks); err != nil {
+			i.failBackground(err)
+			return
+		}
+		i.log.Debugf("'%s' failure attacks flapped off", i.Metadata.ID)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.clock.After(act.Off):
+		}
+		if err := i.applyAttacks(ctx, i.attacks); err != nil {
+			i.failBackground(err)
+			return
+		}
+		i.log.Debugf("'%s' failure attacks flapped on", i.Metadata.ID)
+	}
+}
+
+// failBackground marks the failure as errored by an error on background and
+// cancels its context so the failure is reverted.
+func (i *Injection) failBackground(err error) {
+	i.log.Errorf("error applying '%s' failure attacks: %s", i.Metadata.ID, err)
+	i.Lock()
+	i.bgErr = err
+	i.Unlock()
+	i.ctxC()
+}
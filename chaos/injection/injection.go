@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/slok/ragnarok/api/chaos/v1"
 	"github.com/slok/ragnarok/attack"
@@ -21,6 +23,32 @@ type Failer interface {
 	Revert() error
 }
 
+// defaultVerifyInterval is the default interval the applied attacks are verified.
+const defaultVerifyInterval = 30 * time.Second
+
+// Config is the configuration of an injection.
+type Config struct {
+	// Registry is the registry used to create the attacks, by default the base registry.
+	Registry attack.Registry
+	// Logger is the logger of the injection, by default the base logger.
+	Logger log.Logger
+	// Clock is the clock of the injection, by default the real clock.
+	Clock clock.Clock
+	// VerifyInterval is the interval the applied attacks that implement attack.Verifier
+	// are verified, 0 will use the default interval and a negative one disables the
+	// verification.
+	VerifyInterval time.Duration
+	// RevertRetry is the retry policy of the attack reverts, by default the reverts are
+	// not retried. Its timeout is the deadline of every attack revert.
+	RevertRetry RetryPolicy
+	// ApplyTimeout is the deadline of every attack apply, the attacks that time out
+	// count as errored. By default the applies don't have deadline.
+	ApplyTimeout time.Duration
+	// JitterSource returns the random numbers (from 0 to 1) of the revert retries
+	// jitter, by default the math/rand ones.
+	JitterSource func() float64
+}
+
 // Injection is a failure that can be applied.
 type Injection struct {
 	*v1.Failure
@@ -33,6 +61,46 @@ type Injection struct {
 
 	erroredAtts []attack.Attacker // Used to track the failured attacks
 	appliedAtts []attack.Attacker // Used to track the correct applied attacks
+
+	stages []*stage       // The stages applied sequentially after the attacks.
+	rnd    *rand.Rand     // Used for the random activation decisions.
+	wg     sync.WaitGroup // Used to wait until the attacks applied on background have stopped.
+	bgErr  error          // The error applying the attacks on background, if any.
+
+	verifyInterval time.Duration // The interval the applied attacks are verified, 0 disables the verification.
+	attsMu         sync.Mutex    // Used to not verify the attacks while being applied or reverted.
+
+	retry          RetryPolicy       // The retry policy of the attack reverts.
+	erroredReverts []attack.Attacker // Used to track the attacks that couldn't be reverted.
+	applyTimeout   time.Duration     // The deadline of the attack applies, 0 disables it.
+	jitterSource   func() float64    // The random source of the revert retries jitter.
+
+	reverts   map[attack.Attacker]*runningRevert // The attack reverts running on background.
+	revertsMu sync.Mutex
+}
+
+// newAttacks creates the attacks of the attack maps.
+func newAttacks(ams []v1.AttackMap, reg attack.Registry) ([]attack.Attacker, error) {
+	atts := make([]attack.Attacker, len(ams))
+
+	for i, tAC := range ams {
+		// Check on each attack slice there is only one attack map.
+		if len(tAC) != 1 {
+			return nil, errors.New("configuration attack doesn't have the correct length")
+		}
+		// Get key/value iterating over a one element map, ugh... .
+		var kind string
+		var aC attack.Opts
+		for kind, aC = range tAC {
+			break
+		}
+		a, err := reg.New(kind, aC)
+		if err != nil {
+			return nil, err
+		}
+		atts[i] = a
+	}
+	return atts, nil
 }
 
 // NewInjection Creates a new Failer object from a failure definition
@@ -44,46 +112,117 @@ func NewInjection(f *v1.Failure, l log.Logger, cl clock.Clock) (*Injection, erro
 // NewInjectionFromReg Creates a new SystemFailure object from a failure definition
 // and a custom registry.
 func NewInjectionFromReg(f *v1.Failure, reg attack.Registry, l log.Logger, cl clock.Clock) (*Injection, error) {
+	return NewInjectionWithConfig(f, Config{
+		Registry: reg,
+		Logger:   l,
+		Clock:    cl,
+	})
+}
+
+// NewInjectionWithConfig Creates a new injection from a failure definition and a
+// configuration, the missing configuration will use the defaults. The apply and
+// revert timeouts of the failure override the configuration ones.
+func NewInjectionWithConfig(f *v1.Failure, cfg Config) (*Injection, error) {
+	reg := cfg.Registry
+	if reg == nil {
+		reg = attack.BaseReg()
+	}
+
 	// Set global logger if no logger
+	l := cfg.Logger
 	if l == nil {
 		l = log.Base()
 	}
 
+	cl := cfg.Clock
 	if cl == nil {
 		cl = clock.New()
 	}
 
+	verifyInterval := cfg.VerifyInterval
+	switch {
+	case verifyInterval == 0:
+		verifyInterval = defaultVerifyInterval
+	case verifyInterval < 0:
+		verifyInterval = 0
+	}
+
+	if err := cfg.RevertRetry.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.ApplyTimeout < 0 {
+		return nil, fmt.Errorf("apply timeout can't be negative")
+	}
+
+	// Override the timeouts with the failure ones.
+	if f.Spec.ApplyTimeout < 0 || f.Spec.RevertTimeout < 0 {
+		return nil, fmt.Errorf("failure timeouts can't be negative")
+	}
+	applyTimeout := cfg.ApplyTimeout
+	if f.Spec.ApplyTimeout > 0 {
+		applyTimeout = f.Spec.ApplyTimeout
+	}
+	retry := cfg.RevertRetry
+	if f.Spec.RevertTimeout > 0 {
+		retry.Timeout = f.Spec.RevertTimeout
+	}
+
+	jitterSource := cfg.JitterSource
+	if jitterSource == nil {
+		jitterSource = rand.Float64
+	}
+
 	// Create the attacks.
-	atts := make([]attack.Attacker, len(f.Spec.Attacks))
+	atts, err := newAttacks(f.Spec.Attacks, reg)
+	if err != nil {
+		return nil, err
+	}
 
-	for i, tAC := range f.Spec.Attacks {
-		// Check on each attack slice there is only one attack map.
-		if len(tAC) != 1 {
-			return nil, errors.New("configuration attack doesn't have the correct length")
+	// Check the activation.
+	if act := f.Spec.Activation; act != nil {
+		if p := act.Probability; p != nil && (*p < 0 || *p > 1) {
+			return nil, fmt.Errorf("activation probability must be between 0 and 1")
 		}
-		// Get key/value iterating over a one element map, ugh... .
-		var kind string
-		var aC attack.Opts
-		for kind, aC = range tAC {
-			break
+		if act.StartWindow < 0 || act.On < 0 || act.Off < 0 {
+			return nil, fmt.Errorf("activation durations can't be negative")
 		}
-		a, err := reg.New(kind, aC)
+		if (act.On == 0) != (act.Off == 0) {
+			return nil, fmt.Errorf("activation flapping needs on and off durations")
+		}
+	}
+
+	// Create the stages.
+	stgs := make([]*stage, len(f.Spec.Stages))
+	for i, st := range f.Spec.Stages {
+		s, err := newStage(st, reg)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid stage %d: %s", i, err)
 		}
-		atts[i] = a
+		stgs[i] = s
 	}
 
 	// Update the v1.
 	f.Status.Creation = cl.Now().UTC()
 	f.Status.CurrentState = v1.EnabledFailureState
 
+	// Only verify if there is something to verify.
+	if !hasVerifiers(atts, stgs) {
+		verifyInterval = 0
+	}
+
 	ij := &Injection{
-		Failure: f,
-		attacks: atts,
-		ctx:     context.Background(),
-		log:     l,
-		clock:   cl,
+		Failure:        f,
+		attacks:        atts,
+		stages:         stgs,
+		rnd:            newRand(f.Spec.Activation),
+		verifyInterval: verifyInterval,
+		retry:          retry,
+		applyTimeout:   applyTimeout,
+		jitterSource:   jitterSource,
+		reverts:        map[attack.Attacker]*runningRevert{},
+		ctx:            context.Background(),
+		log:            l,
+		clock:          cl,
 	}
 
 	return ij, nil
@@ -102,43 +241,30 @@ func (i *Injection) Fail() error {
 
 	i.ctx, i.ctxC = context.WithCancel(i.ctx)
 
-	// channels for the attack results
-	errCh := make(chan attack.Attacker)
-	applyCh := make(chan attack.Attacker)
-
-	for _, a := range i.attacks {
-		go func(a attack.Attacker) {
-			if err := a.Apply(i.ctx); err != nil {
-				// Process the error, if there is any error then we need to revert
-				log.Errorf("error aplying attack: %s", err)
-				errCh <- a
-			} else {
-				applyCh <- a
-			}
-		}(a)
-	}
-
-	// Check for errors, sync with channels
-	for j := 0; j < len(i.attacks); j++ {
-		select {
-		case a := <-errCh:
-			i.erroredAtts = append(i.erroredAtts, a)
-		case a := <-applyCh:
-			i.appliedAtts = append(i.appliedAtts, a)
+	// Activate the attacks.
+	delay, activated := i.activation()
+	switch {
+	case !activated:
+		i.log.Infof("'%s' failure not activated by its probability", i.Metadata.ID)
+	case delay > 0 || i.flapping():
+		// Apply the attacks on background.
+		i.wg.Add(1)
+		go i.runActivation(i.ctx, delay)
+	default:
+		if err := i.applyNow(); err != nil {
+			return err
+		}
+		// Start applying the stages.
+		if len(i.stages) > 0 {
+			i.wg.Add(1)
+			go i.runStages(i.ctx)
 		}
 	}
 
-	// Check if there are any errors, if there are errors then revert the applied ones
-	if len(i.erroredAtts) > 0 {
-		// If reverting correct applied ones then return different error
-		if err := i.Revert(); err != nil {
-			log.Error(err)
-			return fmt.Errorf("error aplying failure & error when trying to revert the applied ones")
-		}
-		i.Lock()
-		i.Status.CurrentState = v1.ErroredFailureState
-		i.Unlock()
-		return fmt.Errorf("error aplying failure")
+	// Verify the attacks while executing.
+	if activated && i.verifyInterval > 0 {
+		i.wg.Add(1)
+		go i.runVerification(i.ctx)
 	}
 
 	// Set execution timer and start the countdown until the revert
@@ -153,45 +279,162 @@ func (i *Injection) Fail() error {
 		// Don't revert if not executing
 		if i.Status.CurrentState != v1.ExecutingFailureState {
 			i.log.Warnf("system failure attempt to finish but this is not in running state: %s", i.Status.CurrentState)
+			i.Unlock()
 			return
 		}
 		i.Unlock()
 		i.Revert()
+
+		// A failure on background makes the whole failure errored.
+		i.Lock()
+		if i.bgErr != nil && i.Status.CurrentState == v1.DisabledFailureState {
+			i.Status.CurrentState = v1.ErroredFailureState
+			i.Status.Message = i.bgErr.Error()
+		}
+		i.Unlock()
 	}()
 	i.Status.Executed = i.clock.Now().UTC()
 	i.log.Infof("execution of '%s' failure started", i.Metadata.ID)
 	return nil
 }
 
-// Revert implements Revert interface.
-func (i *Injection) Revert() error {
-	i.log.Infof("reverting '%s' failure", i.Metadata.ID)
-	defer i.ctxC()
+// applyNow applies all the failure attacks at the same time, if any of the attacks
+// fails the applied ones will be reverted.
+func (i *Injection) applyNow() error {
+	type result struct {
+		a   attack.Attacker
+		err error
+	}
+	// Buffered channel for the attack results, the appliers never block.
+	resCh := make(chan result, len(i.attacks))
 
-	// Only revert the applied attacks
-	errsCh := make(chan error)
-	for _, a := range i.appliedAtts {
-		// TODO: Retry
+	for _, a := range i.attacks {
 		go func(a attack.Attacker) {
-			errsCh <- a.Revert()
+			resCh <- result{a: a, err: i.applyAttack(i.ctx, a)}
 		}(a)
 	}
 
-	i.Status.CurrentState = v1.DisabledFailureState
-	errStr := ""
-	for j := 0; j < len(i.appliedAtts); j++ {
-		if err := <-errsCh; err != nil {
-			errStr = fmt.Sprintf("%s; %s", errStr, err)
+	// Check for errors, sync with channels
+	for range i.attacks {
+		res := <-resCh
+		if res.err != nil {
+			// Process the error, if there is any error then we need to revert
+			log.Errorf("error aplying attack: %s", res.err)
+			i.erroredAtts = append(i.erroredAtts, res.a)
+			continue
+		}
+		i.appliedAtts = append(i.appliedAtts, res.a)
+	}
+
+	// Check if there are any errors, if there are errors then revert the applied ones
+	if len(i.erroredAtts) > 0 {
+		// If reverting correct applied ones then return different error
+		if err := i.Revert(); err != nil {
+			log.Error(err)
+			return fmt.Errorf("error aplying failure & error when trying to revert the applied ones")
 		}
+		err := fmt.Errorf("error aplying failure")
+		i.Lock()
+		i.Status.CurrentState = v1.ErroredFailureState
+		i.Status.Message = fmt.Sprintf("%s: %d of %d attacks failed", err, len(i.erroredAtts), len(i.attacks))
+		i.Unlock()
+		return err
 	}
+	return nil
+}
+
+// applyAttack applies an attack waiting at most the apply timeout. An attack that
+// times out counts as errored, it will be stopped by the cancellation of the context
+// and reverted if its apply ends succeeding.
+func (i *Injection) applyAttack(ctx context.Context, a attack.Attacker) error {
+	if i.applyTimeout == 0 {
+		return a.Apply(ctx)
+	}
+
+	// Buffered so the apply finishes even if nobody is waiting for it.
+	errC := make(chan error, 1)
+	go func() {
+		errC <- a.Apply(ctx)
+	}()
+
+	select {
+	case err := <-errC:
+		return err
+	case <-i.clock.After(i.applyTimeout):
+	}
+
+	// The attack is not tracked as applied, revert it if it ends being applied.
+	go func() {
+		if err := <-errC; err != nil {
+			return
+		}
+		i.log.Warnf("attack applied after timing out, reverting it")
+		if err := i.revertAttack(context.Background(), a); err != nil {
+			i.log.Errorf("error reverting timed out attack: %s", err)
+		}
+	}()
+	return fmt.Errorf("apply timed out after %s", i.applyTimeout)
+}
+
+// Revert implements Revert interface.
+func (i *Injection) Revert() error {
+	i.log.Infof("reverting '%s' failure", i.Metadata.ID)
+	i.Lock()
+	i.Status.CurrentState = v1.DisabledFailureState
+	i.Unlock()
+
+	// Stop the background applications before reverting, they could be applying attacks.
+	if i.ctxC != nil {
+		i.ctxC()
+	}
+	i.wg.Wait()
+
+	// Only revert the applied attacks
+	failed, rErr := i.revertAll(context.Background(), i.appliedAtts)
 
 	var err error
 	i.Lock()
 	i.Status.Finished = i.clock.Now().UTC()
-	if errStr != "" {
+	i.erroredReverts = failed
+	if rErr != nil {
 		i.Status.CurrentState = v1.ErroredRevertingFailureState
-		err = fmt.Errorf("error reverting failure (triggered by errored attacks when aplying attacks): %s", errStr)
+		err = fmt.Errorf("error reverting failure (triggered by errored attacks when aplying attacks): %s", rErr)
+		i.Status.Message = err.Error()
 	}
 	i.Unlock()
 	return err
 }
+
+// RetryRevert retries reverting the attacks that couldn't be reverted, if all of
+// them are reverted the failure will be disabled. Locked operation
+func (i *Injection) RetryRevert() error {
+	return i.RetryRevertContext(context.Background())
+}
+
+// RetryRevertContext is like RetryRevert but the waits between the revert retries
+// end when the context is done.
+func (i *Injection) RetryRevertContext(ctx context.Context) error {
+	i.Lock()
+	if i.Status.CurrentState != v1.ErroredRevertingFailureState {
+		i.Unlock()
+		return fmt.Errorf("invalid state. The only valid state for retrying the revert is: %s", v1.ErroredRevertingFailureState)
+	}
+	atts := i.erroredReverts
+	i.Unlock()
+
+	i.log.Infof("retrying the revert of '%s' failure", i.Metadata.ID)
+	failed, rErr := i.revertAll(ctx, atts)
+
+	i.Lock()
+	defer i.Unlock()
+	i.erroredReverts = failed
+	if rErr != nil {
+		err := fmt.Errorf("error retrying the revert of the failure: %s", rErr)
+		i.Status.Message = err.Error()
+		return err
+	}
+	i.Status.CurrentState = v1.DisabledFailureState
+	i.Status.Finished = i.clock.Now().UTC()
+	i.Status.Message = ""
+	return nil
+}
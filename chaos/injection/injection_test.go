@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -288,6 +290,7 @@ func TestSystemFailureFailAttacksErrorAutoRevertOK(t *testing.T) {
 		if assert.Error(err) {
 			assert.Equal(errors.New("error aplying failure"), err)
 			assert.Equal(v1.ErroredFailureState, in.Status.CurrentState)
+			assert.Equal("error aplying failure: 2 of 3 attacks failed", in.Status.Message)
 			at1.AssertExpectations(t)
 			at2.AssertExpectations(t)
 			at3.AssertExpectations(t)
@@ -329,6 +332,7 @@ func TestSystemFailureFailAttacksErrorAutoRevertError(t *testing.T) {
 		if assert.Error(err) {
 			assert.Equal(errors.New("error aplying failure & error when trying to revert the applied ones"), err)
 			assert.Equal(v1.ErroredRevertingFailureState, in.Status.CurrentState)
+			assert.Contains(in.Status.Message, "revert_error3")
 			at1.AssertExpectations(t)
 			at2.AssertExpectations(t)
 			at3.AssertExpectations(t)
@@ -415,3 +419,922 @@ func TestSystemFailureFailAttacksFinishForced(t *testing.T) {
 		at1.AssertExpectations(t)
 	}
 }
+
+// eventRecorder records the events of the attacks.
+type eventRecorder struct {
+	events []string
+	mu     sync.Mutex
+}
+
+func (e *eventRecorder) record(event string) func(mock.Arguments) {
+	return func(mock.Arguments) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.events = append(e.events, event)
+	}
+}
+
+func (e *eventRecorder) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.events...)
+}
+
+// waitEvents waits until the recorder has the number of events.
+func (e *eventRecorder) waitEvents(t *testing.T, n int) {
+	for i := 0; len(e.get()) < n; i++ {
+		if i > 500 {
+			require.Fail(t, "timeout waiting for the attack events", "got: %v", e.get())
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestSystemFailureStagesAreSequential(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: 1 * time.Hour,
+			Attacks: []v1.AttackMap{
+				{"attack1": attack.Opts{}},
+			},
+			Stages: []v1.Stage{
+				{
+					Duration: 20 * time.Millisecond,
+					Attacks:  []v1.AttackMap{{"attack2": attack.Opts{}}},
+				},
+				{
+					Delay:   10 * time.Millisecond,
+					Attacks: []v1.AttackMap{{"attack3": attack.Opts{}}},
+				},
+			},
+		},
+	}
+
+	// Mock attackers
+	rec := &eventRecorder{}
+	ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	at1, at2, at3 := &mattack.Attacker{}, &mattack.Attacker{}, &mattack.Attacker{}
+	at1.On("Apply", ctxMatcher).Once().Return(nil).Run(rec.record("apply1"))
+	at2.On("Apply", ctxMatcher).Once().Return(nil).Run(rec.record("apply2"))
+	at3.On("Apply", ctxMatcher).Once().Return(nil).Run(rec.record("apply3"))
+	at1.On("Revert").Once().Return(nil)
+	at2.On("Revert").Once().Return(nil).Run(rec.record("revert2"))
+	at3.On("Revert").Once().Return(nil)
+
+	// Mock Registry
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+	reg.On("New", "attack2", attack.Opts{}).Return(at2, nil)
+	reg.On("New", "attack3", attack.Opts{}).Return(at3, nil)
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+	require.NoError(err)
+	require.NoError(in.Fail())
+
+	// The second stage is applied after the first one has finished.
+	rec.waitEvents(t, 4)
+	assert.Equal([]string{"apply1", "apply2", "revert2", "apply3"}, rec.get())
+
+	// Reverting the failure reverts the attacks that are still applied.
+	require.NoError(in.Revert())
+	assert.Equal(v1.DisabledFailureState, in.Status.CurrentState)
+	at1.AssertExpectations(t)
+	at2.AssertExpectations(t)
+	at3.AssertExpectations(t)
+}
+
+func TestSystemFailureStageRamp(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rec := &eventRecorder{}
+	ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	reg := attack.NewSimpleRegistry()
+	reg.Register("attack1", attack.NewSchemaCreator("attack 1", attack.Schema{
+		{Name: "percent", Type: attack.IntOptType, Default: 100},
+		{Name: "size", Type: attack.SizeOptType},
+	}, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		event := fmt.Sprintf("%d-%d", o["percent"], o["size"])
+		at := &mattack.Attacker{}
+		at.On("Apply", ctxMatcher).Once().Return(nil).Run(rec.record("apply" + event))
+		at.On("Revert").Once().Return(nil).Run(rec.record("revert" + event))
+		return at, nil
+	})))
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: 1 * time.Hour,
+			Stages: []v1.Stage{
+				{
+					Ramp: &v1.Ramp{
+						Steps:    4,
+						Interval: 5 * time.Millisecond,
+						Options:  []string{"percent", "size"},
+					},
+					Attacks: []v1.AttackMap{{"attack1": attack.Opts{"size": "4KiB"}}},
+				},
+			},
+		},
+	}
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+	require.NoError(err)
+	require.NoError(in.Fail())
+
+	// Every step reverts the previous one.
+	rec.waitEvents(t, 7)
+	exp := []string{
+		"apply25-1024", "revert25-1024",
+		"apply50-2048", "revert50-2048",
+		"apply75-3072", "revert75-3072",
+		"apply100-4096",
+	}
+	assert.Equal(exp, rec.get())
+	require.NoError(in.Revert())
+	assert.Equal(append(exp, "revert100-4096"), rec.get())
+}
+
+func TestSystemFailureStageRampRoundsUp(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rec := &eventRecorder{}
+	ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	reg := attack.NewSimpleRegistry()
+	reg.Register("attack1", attack.NewSchemaCreator("attack 1", attack.Schema{
+		{Name: "count", Type: attack.IntOptType},
+	}, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) {
+		event := fmt.Sprintf("%d", o["count"])
+		at := &mattack.Attacker{}
+		at.On("Apply", ctxMatcher).Once().Return(nil).Run(rec.record("apply" + event))
+		at.On("Revert").Once().Return(nil).Run(rec.record("revert" + event))
+		return at, nil
+	})))
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: 1 * time.Hour,
+			Stages: []v1.Stage{
+				{
+					Ramp: &v1.Ramp{
+						Steps:    5,
+						Interval: 5 * time.Millisecond,
+						Options:  []string{"count"},
+					},
+					Attacks: []v1.AttackMap{{"attack1": attack.Opts{"count": 3}}},
+				},
+			},
+		},
+	}
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+	require.NoError(err)
+	require.NoError(in.Fail())
+
+	// The first step isn't truncated to 0.
+	rec.waitEvents(t, 9)
+	exp := []string{
+		"apply1", "revert1",
+		"apply2", "revert2",
+		"apply2", "revert2",
+		"apply3", "revert3",
+		"apply3",
+	}
+	assert.Equal(exp, rec.get())
+	require.NoError(in.Revert())
+}
+
+func TestNewInjectionStageRampError(t *testing.T) {
+	reg := attack.NewSimpleRegistry()
+	reg.Register("attack1", attack.NewSchemaCreator("attack 1", attack.Schema{
+		{Name: "name", Type: attack.StringOptType, Default: "test"},
+		{Name: "size", Type: attack.SizeOptType},
+	}, attack.CreatorFunc(func(o attack.Opts) (attack.Attacker, error) { return &mattack.Attacker{}, nil })))
+
+	tests := []struct {
+		name    string
+		options []string
+	}{
+		{
+			name:    "Ramping a not numeric option should error.",
+			options: []string{"name"},
+		},
+		{
+			name:    "Ramping a not set option should error.",
+			options: []string{"size"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &v1.Failure{
+				Spec: v1.FailureSpec{
+					Stages: []v1.Stage{
+						{
+							Ramp:    &v1.Ramp{Steps: 2, Options: test.options},
+							Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+						},
+					},
+				},
+			}
+			_, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSystemFailureStageErrorRevertsFailure(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: 1 * time.Hour,
+			Attacks: []v1.AttackMap{
+				{"attack1": attack.Opts{}},
+			},
+			Stages: []v1.Stage{
+				{Attacks: []v1.AttackMap{{"attack2": attack.Opts{}}}},
+			},
+		},
+	}
+
+	// Mock attackers
+	rec := &eventRecorder{}
+	ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	at1, at2 := &mattack.Attacker{}, &mattack.Attacker{}
+	at1.On("Apply", ctxMatcher).Once().Return(nil)
+	at2.On("Apply", ctxMatcher).Once().Return(errors.New("wanted error"))
+	at1.On("Revert").Once().Return(nil).Run(rec.record("revert1"))
+
+	// Mock Registry
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+	reg.On("New", "attack2", attack.Opts{}).Return(at2, nil)
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+	require.NoError(err)
+	require.NoError(in.Fail())
+
+	rec.waitEvents(t, 1)
+	for i := 0; ; i++ {
+		require.True(i < 500, "failure should be errored")
+		in.Lock()
+		state := in.Status.CurrentState
+		in.Unlock()
+		if state == v1.ErroredFailureState {
+			break
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	at1.AssertExpectations(t)
+	at2.AssertExpectations(t)
+	assert.Equal([]string{"revert1"}, rec.get())
+	in.Lock()
+	assert.Contains(in.Status.Message, "error applying stage 0")
+	in.Unlock()
+}
+
+func TestNewInjectionActivationError(t *testing.T) {
+	big, negative := 1.5, -0.5
+	tests := []struct {
+		name       string
+		activation *v1.Activation
+	}{
+		{
+			name:       "A probability bigger than 1 should error.",
+			activation: &v1.Activation{Probability: &big},
+		},
+		{
+			name:       "A negative probability should error.",
+			activation: &v1.Activation{Probability: &negative},
+		},
+		{
+			name:       "A negative start window should error.",
+			activation: &v1.Activation{StartWindow: -time.Minute},
+		},
+		{
+			name:       "Flapping without off duration should error.",
+			activation: &v1.Activation{On: time.Minute},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &v1.Failure{Spec: v1.FailureSpec{Activation: test.activation}}
+			_, err := injection.NewInjectionFromReg(f, &mattack.Registry{}, nil, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSystemFailureActivationProbability(t *testing.T) {
+	// A 0 probability never applies the failure and 1 always applies it.
+	for _, probability := range []float64{0, 0.5, 1} {
+		for seed := int64(1); seed <= 10; seed++ {
+			probability, seed := probability, seed
+			t.Run(fmt.Sprintf("probability %v seed %d", probability, seed), func(t *testing.T) {
+				testActivationProbability(t, probability, seed)
+			})
+		}
+	}
+}
+
+func testActivationProbability(t *testing.T, probability float64, seed int64) {
+	assert := assert.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout:    1 * time.Hour,
+			Attacks:    []v1.AttackMap{{"attack1": attack.Opts{}}},
+			Activation: &v1.Activation{Probability: &probability, Seed: seed},
+		},
+	}
+	expApply := rand.New(rand.NewSource(seed)).Float64() < probability
+
+	ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	at1 := &mattack.Attacker{}
+	if expApply {
+		at1.On("Apply", ctxMatcher).Once().Return(nil)
+		at1.On("Revert").Once().Return(nil)
+	}
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+	if assert.NoError(err) {
+		assert.NoError(in.Fail())
+		assert.Equal(v1.ExecutingFailureState, in.Status.CurrentState)
+		assert.NoError(in.Revert())
+		at1.AssertExpectations(t)
+	}
+}
+
+func TestSystemFailureActivationDelayAndFlapping(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: 1 * time.Hour,
+			Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+			Activation: &v1.Activation{
+				StartWindow: 10 * time.Minute,
+				On:          1 * time.Minute,
+				Off:         2 * time.Minute,
+				Seed:        42,
+			},
+		},
+	}
+	expDelay := time.Duration(rand.New(rand.NewSource(42)).Int63n(int64(10 * time.Minute)))
+
+	// Mock attackers
+	rec := &eventRecorder{}
+	ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	at1 := &mattack.Attacker{}
+	at1.On("Apply", ctxMatcher).Return(nil).Run(rec.record("apply"))
+	at1.On("Revert").Return(nil).Run(rec.record("revert"))
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+
+	// Mock clock, the timers are fired by the test.
+	startC, onC, offC := make(chan time.Time), make(chan time.Time), make(chan time.Time)
+	cl := &mclock.Clock{}
+	cl.On("Now").Return(time.Now())
+	cl.On("After", f.Spec.Timeout).Return((<-chan time.Time)(make(chan time.Time)))
+	cl.On("After", expDelay).Return((<-chan time.Time)(startC))
+	cl.On("After", 1*time.Minute).Return((<-chan time.Time)(onC))
+	cl.On("After", 2*time.Minute).Return((<-chan time.Time)(offC))
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, cl)
+	require.NoError(err)
+	require.NoError(in.Fail())
+	assert.Empty(rec.get(), "the attacks shouldn't be applied before the start delay")
+
+	startC <- time.Now()
+	rec.waitEvents(t, 1)
+	onC <- time.Now()
+	rec.waitEvents(t, 2)
+	offC <- time.Now()
+	rec.waitEvents(t, 3)
+	onC <- time.Now()
+	rec.waitEvents(t, 4)
+
+	// Reverting while flapped off shouldn't revert again.
+	require.NoError(in.Revert())
+	assert.Equal([]string{"apply", "revert", "apply", "revert"}, rec.get())
+}
+
+// verifiableAttacker is an attacker that can be verified.
+type verifiableAttacker struct {
+	*mattack.Attacker
+	*mattack.Verifier
+}
+
+func TestSystemFailureVerification(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifyErr error
+		expState  v1.FailureState
+	}{
+		{
+			name:     "Attacks verified correctly should keep the failure executing.",
+			expState: v1.ExecutingFailureState,
+		},
+		{
+			name:      "Attacks not in effect should error and revert the failure.",
+			verifyErr: errors.New("wanted error"),
+			expState:  v1.ErroredFailureState,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			f := &v1.Failure{
+				Spec: v1.FailureSpec{
+					Timeout: 1 * time.Hour,
+					Attacks: []v1.AttackMap{
+						{"attack1": attack.Opts{}},
+						{"attack2": attack.Opts{}},
+					},
+				},
+			}
+
+			// Mock attackers, only the second one can be verified.
+			rec := &eventRecorder{}
+			ctxMatcher := mock.MatchedBy(func(ctx context.Context) bool { return true })
+			at1 := &mattack.Attacker{}
+			at2 := verifiableAttacker{Attacker: &mattack.Attacker{}, Verifier: &mattack.Verifier{}}
+			at1.On("Apply", ctxMatcher).Once().Return(nil)
+			at2.Attacker.On("Apply", ctxMatcher).Once().Return(nil)
+			at2.Verifier.On("Verify", ctxMatcher).Once().Return(test.verifyErr).Run(rec.record("verify"))
+			at1.On("Revert").Once().Return(nil)
+			at2.Attacker.On("Revert").Once().Return(nil)
+
+			reg := &mattack.Registry{}
+			reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+			reg.On("New", "attack2", attack.Opts{}).Return(at2, nil)
+
+			// Mock clock.
+			verifyC := make(chan time.Time)
+			cl := &mclock.Clock{}
+			cl.On("Now").Return(time.Now())
+			cl.On("After", f.Spec.Timeout).Return((<-chan time.Time)(make(chan time.Time)))
+			cl.On("After", time.Minute).Return((<-chan time.Time)(verifyC))
+
+			in, err := injection.NewInjectionWithConfig(f, injection.Config{
+				Registry:       reg,
+				Clock:          cl,
+				VerifyInterval: time.Minute,
+			})
+			require.NoError(err)
+			require.NoError(in.Fail())
+
+			verifyC <- time.Now()
+			rec.waitEvents(t, 1)
+
+			// Wait until the failure has the expected state.
+			for i := 0; ; i++ {
+				require.True(i < 500, "failure should be on %s state", test.expState)
+				in.Lock()
+				state := in.Status.CurrentState
+				in.Unlock()
+				if state == test.expState {
+					break
+				}
+				time.Sleep(2 * time.Millisecond)
+			}
+			if test.expState == v1.ExecutingFailureState {
+				assert.NoError(in.Revert())
+			}
+			at1.AssertExpectations(t)
+			at2.Attacker.AssertExpectations(t)
+			at2.Verifier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewInjectionRetryPolicyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy injection.RetryPolicy
+	}{
+		{
+			name:   "A retry policy with negative attempts should error.",
+			policy: injection.RetryPolicy{Attempts: -1},
+		},
+		{
+			name:   "A retry policy with negative timeout should error.",
+			policy: injection.RetryPolicy{Attempts: 2, Timeout: -time.Second},
+		},
+		{
+			name:   "A retry policy with a jitter greater than 1 should error.",
+			policy: injection.RetryPolicy{Attempts: 2, Jitter: 1.5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &v1.Failure{Spec: v1.FailureSpec{Timeout: time.Hour}}
+			_, err := injection.NewInjectionWithConfig(f, injection.Config{
+				Registry:    &mattack.Registry{},
+				RevertRetry: test.policy,
+			})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSystemFailureRevertRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     injection.RetryPolicy
+		jitter     func() float64
+		revertErrs int  // The number of reverts that will fail before succeeding.
+		timeout    bool // The first revert attempt will time out.
+		expReverts int  // The number of expected reverts.
+		expWaits   []time.Duration
+		expErr     bool
+		expState   v1.FailureState
+	}{
+		{
+			name:       "Without retry policy a failed revert should error the failure.",
+			revertErrs: 1,
+			expReverts: 1,
+			expErr:     true,
+			expState:   v1.ErroredRevertingFailureState,
+		},
+		{
+			name:       "With retry policy the failed reverts should be retried with backoff until they succeed.",
+			policy:     injection.RetryPolicy{Attempts: 3, Backoff: time.Second},
+			revertErrs: 2,
+			expReverts: 3,
+			expWaits:   []time.Duration{time.Second, 2 * time.Second},
+			expState:   v1.DisabledFailureState,
+		},
+		{
+			name:       "With retry policy the backoff should be limited.",
+			policy:     injection.RetryPolicy{Attempts: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second},
+			revertErrs: 10,
+			expReverts: 4,
+			expWaits:   []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+			expErr:     true,
+			expState:   v1.ErroredRevertingFailureState,
+		},
+		{
+			name:       "With retry policy the backoff should have jitter.",
+			policy:     injection.RetryPolicy{Attempts: 2, Backoff: time.Second, Jitter: 0.5},
+			jitter:     func() float64 { return 0.75 },
+			revertErrs: 1,
+			expReverts: 2,
+			expWaits:   []time.Duration{1250 * time.Millisecond},
+			expState:   v1.DisabledFailureState,
+		},
+		{
+			name:       "With retry policy the reverts that time out should be waited on the retry instead of reverting again.",
+			policy:     injection.RetryPolicy{Attempts: 2, Backoff: time.Second, Timeout: 10 * time.Second},
+			timeout:    true,
+			expReverts: 1,
+			expWaits:   []time.Duration{10 * time.Second, time.Second, 10 * time.Second},
+			expState:   v1.DisabledFailureState,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			f := &v1.Failure{
+				Spec: v1.FailureSpec{
+					Timeout: time.Hour,
+					Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+				},
+			}
+
+			// Mock attackers, the revert that times out is released when waiting to retry.
+			startedC := make(chan struct{})
+			releaseC := make(chan struct{})
+			var releaseOnce sync.Once
+			release := func() { releaseOnce.Do(func() { close(releaseC) }) }
+			defer release()
+			at1 := &mattack.Attacker{}
+			at1.On("Apply", mock.Anything).Return(nil)
+			if test.timeout {
+				at1.On("Revert").Once().Return(nil).Run(func(mock.Arguments) {
+					close(startedC)
+					<-releaseC
+				})
+			}
+			if test.revertErrs > 0 {
+				at1.On("Revert").Times(test.revertErrs).Return(errors.New("wanted error"))
+			}
+			at1.On("Revert").Return(nil)
+			reg := &mattack.Registry{}
+			reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+
+			// Mock clock, save the waits.
+			var mu sync.Mutex
+			var waits []time.Duration
+			record := func(args mock.Arguments) {
+				mu.Lock()
+				waits = append(waits, args.Get(0).(time.Duration))
+				mu.Unlock()
+			}
+			firedC := make(chan time.Time)
+			close(firedC)
+			cl := &mclock.Clock{}
+			cl.On("Now").Return(time.Now())
+			cl.On("After", f.Spec.Timeout).Return((<-chan time.Time)(make(chan time.Time)))
+			if test.timeout {
+				// Only time out once the revert is blocked.
+				timeoutC := make(chan time.Time)
+				go func() {
+					<-startedC
+					close(timeoutC)
+				}()
+				cl.On("After", test.policy.Timeout).Once().Return((<-chan time.Time)(timeoutC)).Run(record)
+				cl.On("After", test.policy.Timeout).Return((<-chan time.Time)(make(chan time.Time))).Run(record)
+				cl.On("After", test.policy.Backoff).Return((<-chan time.Time)(firedC)).Run(func(args mock.Arguments) {
+					record(args)
+					release()
+				})
+			}
+			cl.On("After", mock.Anything).Return((<-chan time.Time)(firedC)).Run(record)
+
+			in, err := injection.NewInjectionWithConfig(f, injection.Config{
+				Registry:       reg,
+				Clock:          cl,
+				VerifyInterval: -1,
+				RevertRetry:    test.policy,
+				JitterSource:   test.jitter,
+			})
+			require.NoError(err)
+			require.NoError(in.Fail())
+
+			err = in.Revert()
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(test.expState, in.Status.CurrentState)
+			at1.AssertNumberOfCalls(t, "Revert", test.expReverts)
+			mu.Lock()
+			assert.Equal(test.expWaits, waits)
+			mu.Unlock()
+		})
+	}
+}
+
+func TestSystemFailureRetryRevert(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: time.Hour,
+			Attacks: []v1.AttackMap{
+				{"attack1": attack.Opts{}},
+				{"attack2": attack.Opts{}},
+			},
+		},
+	}
+
+	// Mock attackers, the second one fails reverting twice.
+	at1, at2 := &mattack.Attacker{}, &mattack.Attacker{}
+	at1.On("Apply", mock.Anything).Return(nil)
+	at2.On("Apply", mock.Anything).Return(nil)
+	at1.On("Revert").Once().Return(nil)
+	at2.On("Revert").Twice().Return(errors.New("wanted error"))
+	at2.On("Revert").Once().Return(nil)
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+	reg.On("New", "attack2", attack.Opts{}).Return(at2, nil)
+
+	in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+	require.NoError(err)
+	assert.Error(in.RetryRevert(), "retrying the revert of a not errored failure should error")
+	require.NoError(in.Fail())
+
+	assert.Error(in.Revert())
+	assert.Equal(v1.ErroredRevertingFailureState, in.Status.CurrentState)
+
+	// Only the attack that failed is retried.
+	assert.Error(in.RetryRevert())
+	assert.Equal(v1.ErroredRevertingFailureState, in.Status.CurrentState)
+	assert.NotEmpty(in.Status.Message)
+
+	assert.NoError(in.RetryRevert())
+	assert.Equal(v1.DisabledFailureState, in.Status.CurrentState)
+	assert.Empty(in.Status.Message)
+	at1.AssertExpectations(t)
+	at2.AssertExpectations(t)
+}
+
+func TestSystemFailureApplyTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		lateErr   error // The error of the apply after timing out.
+		expRevert bool
+	}{
+		{
+			name:      "An attack applied after timing out should be reverted.",
+			expRevert: true,
+		},
+		{
+			name:      "An attack that fails after timing out shouldn't be reverted.",
+			lateErr:   errors.New("wanted error"),
+			expRevert: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			f := &v1.Failure{
+				Spec: v1.FailureSpec{
+					Timeout: time.Hour,
+					Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+				},
+			}
+
+			// Mock attacker, the apply hangs until released.
+			releaseC := make(chan struct{})
+			revertedC := make(chan struct{})
+			at1 := &mattack.Attacker{}
+			at1.On("Apply", mock.Anything).Once().Return(test.lateErr).Run(func(mock.Arguments) { <-releaseC })
+			at1.On("Revert").Once().Return(nil).Run(func(mock.Arguments) { close(revertedC) })
+			reg := &mattack.Registry{}
+			reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+
+			// Mock clock.
+			timeoutC := make(chan time.Time)
+			close(timeoutC)
+			cl := &mclock.Clock{}
+			cl.On("Now").Return(time.Now())
+			cl.On("After", f.Spec.Timeout).Return((<-chan time.Time)(make(chan time.Time)))
+			cl.On("After", 10*time.Second).Return((<-chan time.Time)(timeoutC))
+
+			in, err := injection.NewInjectionWithConfig(f, injection.Config{
+				Registry:       reg,
+				Clock:          cl,
+				VerifyInterval: -1,
+				ApplyTimeout:   10 * time.Second,
+			})
+			require.NoError(err)
+
+			// The timed out attack makes the failure errored without waiting for it.
+			assert.Error(in.Fail())
+			assert.Equal(v1.ErroredFailureState, in.Status.CurrentState)
+			at1.AssertNotCalled(t, "Revert")
+
+			close(releaseC)
+			wait := 50 * time.Millisecond
+			if test.expRevert {
+				wait = 5 * time.Second
+			}
+			select {
+			case <-revertedC:
+				assert.True(test.expRevert, "the attack shouldn't be reverted")
+			case <-time.After(wait):
+				assert.False(test.expRevert, "the attack should be reverted")
+			}
+		})
+	}
+}
+
+func TestNewInjectionApplyTimeoutError(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1.FailureSpec
+		cfg  injection.Config
+	}{
+		{
+			name: "A negative apply timeout should error.",
+			spec: v1.FailureSpec{Timeout: time.Hour},
+			cfg:  injection.Config{ApplyTimeout: -time.Second},
+		},
+		{
+			name: "A negative failure apply timeout should error.",
+			spec: v1.FailureSpec{Timeout: time.Hour, ApplyTimeout: -time.Second},
+		},
+		{
+			name: "A negative failure revert timeout should error.",
+			spec: v1.FailureSpec{Timeout: time.Hour, RevertTimeout: -time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.cfg.Registry = &mattack.Registry{}
+			_, err := injection.NewInjectionWithConfig(&v1.Failure{Spec: test.spec}, test.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSystemFailureTimeoutsOverride(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout:       time.Hour,
+			Attacks:       []v1.AttackMap{{"attack1": attack.Opts{}}},
+			ApplyTimeout:  20 * time.Second,
+			RevertTimeout: 30 * time.Second,
+		},
+	}
+
+	// Mocks, only the failure timeouts are expected.
+	at1 := &mattack.Attacker{}
+	at1.On("Apply", mock.Anything).Once().Return(nil)
+	at1.On("Revert").Once().Return(nil)
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+	cl := &mclock.Clock{}
+	cl.On("Now").Return(time.Now())
+	cl.On("After", f.Spec.Timeout).Return((<-chan time.Time)(make(chan time.Time)))
+	cl.On("After", 20*time.Second).Return((<-chan time.Time)(make(chan time.Time)))
+	cl.On("After", 30*time.Second).Return((<-chan time.Time)(make(chan time.Time)))
+
+	in, err := injection.NewInjectionWithConfig(f, injection.Config{
+		Registry:       reg,
+		Clock:          cl,
+		VerifyInterval: -1,
+		ApplyTimeout:   10 * time.Second,
+		RevertRetry:    injection.RetryPolicy{Attempts: 1, Timeout: 10 * time.Second},
+	})
+	require.NoError(err)
+	require.NoError(in.Fail())
+	require.NoError(in.Revert())
+
+	assert.Equal(v1.DisabledFailureState, in.Status.CurrentState)
+	at1.AssertExpectations(t)
+	cl.AssertCalled(t, "After", 20*time.Second)
+	cl.AssertCalled(t, "After", 30*time.Second)
+}
+
+// contextReverterAttacker is an attacker that can be reverted with a context.
+type contextReverterAttacker struct {
+	*mattack.Attacker
+	*mattack.ContextReverter
+}
+
+func TestSystemFailureRevertTimeoutCancelsContext(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &v1.Failure{
+		Spec: v1.FailureSpec{
+			Timeout: time.Hour,
+			Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+		},
+	}
+
+	// Mock attacker, the revert returns when its context is cancelled.
+	doneC := make(chan struct{})
+	at1 := contextReverterAttacker{Attacker: &mattack.Attacker{}, ContextReverter: &mattack.ContextReverter{}}
+	at1.Attacker.On("Apply", mock.Anything).Return(nil)
+	at1.ContextReverter.On("RevertContext", mock.Anything).Once().Return(context.Canceled).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+		close(doneC)
+	})
+	reg := &mattack.Registry{}
+	reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+
+	// Mock clock, the revert times out once it's running.
+	timeoutC := make(chan time.Time)
+	close(timeoutC)
+	cl := &mclock.Clock{}
+	cl.On("Now").Return(time.Now())
+	cl.On("After", f.Spec.Timeout).Return((<-chan time.Time)(make(chan time.Time)))
+	cl.On("After", 10*time.Second).Return((<-chan time.Time)(timeoutC))
+
+	in, err := injection.NewInjectionWithConfig(f, injection.Config{
+		Registry:       reg,
+		Clock:          cl,
+		VerifyInterval: -1,
+		RevertRetry:    injection.RetryPolicy{Attempts: 1, Timeout: 10 * time.Second},
+	})
+	require.NoError(err)
+	require.NoError(in.Fail())
+
+	assert.Error(in.Revert())
+	assert.Equal(v1.ErroredRevertingFailureState, in.Status.CurrentState)
+	select {
+	case <-doneC:
+	case <-time.After(5 * time.Second):
+		assert.Fail("the revert context wasn't cancelled")
+	}
+	at1.Attacker.AssertNotCalled(t, "Revert")
+}
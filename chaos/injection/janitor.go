@@ -0,0 +1,154 @@
+package injection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/clock"
+	"github.com/slok/ragnarok/log"
+)
+
+// Janitor retries on background the reverts of the injections that couldn't be
+// reverted, until they are reverted or an operator acknowledges them.
+type Janitor struct {
+	interval   time.Duration
+	injections map[string]*Injection
+	mu         sync.Mutex
+	stopC      chan struct{}
+	wg         sync.WaitGroup
+	clock      clock.Clock
+	log        log.Logger
+}
+
+// NewJanitor returns a new janitor that will retry the reverts on every interval.
+func NewJanitor(interval time.Duration, cl clock.Clock, l log.Logger) (*Janitor, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("janitor interval must be greater than 0")
+	}
+	if cl == nil {
+		cl = clock.New()
+	}
+	if l == nil {
+		l = log.Base()
+	}
+
+	return &Janitor{
+		interval:   interval,
+		injections: map[string]*Injection{},
+		clock:      cl,
+		log:        l.WithField("service", "revertJanitor"),
+	}, nil
+}
+
+// Add adds an injection to the janitor, only the injections that failed reverting
+// will be retried.
+func (j *Janitor) Add(i *Injection) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.injections[i.Metadata.ID]; !ok {
+		j.log.Warnf("'%s' failure revert will be retried until it's reverted or acknowledged", i.Metadata.ID)
+	}
+	j.injections[i.Metadata.ID] = i
+}
+
+// Acknowledge stops retrying the revert of a failure, returns true if the failure
+// revert was being retried.
+func (j *Janitor) Acknowledge(id string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.injections[id]; !ok {
+		return false
+	}
+	delete(j.injections, id)
+	j.log.Warnf("'%s' failure revert acknowledged, it will not be retried", id)
+	return true
+}
+
+// Start starts retrying the reverts on background.
+func (j *Janitor) Start() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stopC != nil {
+		return fmt.Errorf("janitor already running")
+	}
+
+	j.stopC = make(chan struct{})
+	j.wg.Add(1)
+	go j.run(j.stopC)
+	return nil
+}
+
+// Stop stops retrying the reverts and waits until the running retries finish, the
+// running retries don't wait to retry again.
+func (j *Janitor) Stop() error {
+	j.mu.Lock()
+	stopC := j.stopC
+	j.stopC = nil
+	j.mu.Unlock()
+	if stopC == nil {
+		return fmt.Errorf("can't stop, janitor not running")
+	}
+
+	close(stopC)
+	j.wg.Wait()
+	return nil
+}
+
+// run retries the reverts on every interval until stopped.
+func (j *Janitor) run(stopC chan struct{}) {
+	defer j.wg.Done()
+
+	// Cancel the waits of the running retries when stopped.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopC:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-stopC:
+			return
+		case <-j.clock.After(j.interval):
+		}
+		j.retry(ctx)
+	}
+}
+
+// retry retries the revert of all the injections, the reverted injections and the
+// ones that are not errored reverting anymore are removed from the janitor.
+func (j *Janitor) retry(ctx context.Context) {
+	j.mu.Lock()
+	injs := make(map[string]*Injection, len(j.injections))
+	for id, i := range j.injections {
+		injs[id] = i
+	}
+	j.mu.Unlock()
+
+	for id, i := range injs {
+		i.Lock()
+		errored := i.Status.CurrentState == v1.ErroredRevertingFailureState
+		i.Unlock()
+
+		if errored {
+			if err := i.RetryRevertContext(ctx); err != nil {
+				j.log.WithField("failure", id).Errorf("could not revert failure: %s", err)
+				continue
+			}
+			j.log.WithField("failure", id).Infof("failure reverted")
+		}
+
+		j.mu.Lock()
+		if j.injections[id] == i {
+			delete(j.injections, id)
+		}
+		j.mu.Unlock()
+	}
+}
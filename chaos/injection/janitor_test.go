@@ -0,0 +1,95 @@
+package injection_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/ragnarok/api"
+	"github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/chaos/injection"
+	mattack "github.com/slok/ragnarok/mocks/attack"
+	mclock "github.com/slok/ragnarok/mocks/clock"
+)
+
+func TestNewJanitorError(t *testing.T) {
+	_, err := injection.NewJanitor(0, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestJanitorRetryRevert(t *testing.T) {
+	tests := []struct {
+		name        string
+		acknowledge bool
+		expReverts  int
+		expState    v1.FailureState
+	}{
+		{
+			name:       "Errored reverting failures should be reverted by the janitor.",
+			expReverts: 2,
+			expState:   v1.DisabledFailureState,
+		},
+		{
+			name:        "Acknowledged failures should not be reverted by the janitor.",
+			acknowledge: true,
+			expReverts:  1,
+			expState:    v1.ErroredRevertingFailureState,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			f := &v1.Failure{
+				Metadata: api.ObjectMeta{ID: "test"},
+				Spec: v1.FailureSpec{
+					Timeout: time.Hour,
+					Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+				},
+			}
+
+			// Mock attacker, the first revert fails.
+			at1 := &mattack.Attacker{}
+			at1.On("Apply", mock.Anything).Return(nil)
+			at1.On("Revert").Once().Return(errors.New("wanted error"))
+			at1.On("Revert").Return(nil)
+			reg := &mattack.Registry{}
+			reg.On("New", "attack1", attack.Opts{}).Return(at1, nil)
+
+			// Mock clock, the ticks of the janitor are controlled by the test.
+			tickC := make(chan time.Time)
+			cl := &mclock.Clock{}
+			cl.On("After", time.Minute).Return((<-chan time.Time)(tickC))
+
+			in, err := injection.NewInjectionFromReg(f, reg, nil, nil)
+			require.NoError(err)
+			require.NoError(in.Fail())
+			require.Error(in.Revert())
+
+			j, err := injection.NewJanitor(time.Minute, cl, nil)
+			require.NoError(err)
+			j.Add(in)
+			if test.acknowledge {
+				assert.True(j.Acknowledge(f.Metadata.ID))
+			}
+			require.NoError(j.Start())
+			assert.Error(j.Start(), "starting a running janitor should error")
+
+			// Tick and wait until the retry finishes.
+			tickC <- time.Now()
+			require.NoError(j.Stop())
+			assert.Error(j.Stop(), "stopping a stopped janitor should error")
+
+			assert.Equal(test.expState, in.Status.CurrentState)
+			at1.AssertNumberOfCalls(t, "Revert", test.expReverts)
+			assert.False(j.Acknowledge(f.Metadata.ID), "the failure shouldn't be on the janitor")
+		})
+	}
+}
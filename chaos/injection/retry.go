@@ -0,0 +1,172 @@
+package injection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// DefaultRetryPolicy is a sane retry policy for the attack reverts.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	Backoff:    time.Second,
+	MaxBackoff: 30 * time.Second,
+	Jitter:     0.2,
+	Timeout:    time.Minute,
+}
+
+// RetryPolicy is the policy used to retry the attack reverts, the zero value will
+// attempt the reverts only once.
+type RetryPolicy struct {
+	// Attempts is the number of times an attack revert is attempted, 0 and 1 will
+	// attempt it only once.
+	Attempts int
+	// Backoff is the wait before the first retry, doubled on every retry.
+	Backoff time.Duration
+	// MaxBackoff is the maximum wait between retries, 0 doesn't limit the wait.
+	MaxBackoff time.Duration
+	// Jitter is the fraction (from 0 to 1) of the wait randomly added or subtracted
+	// to it, so the retries of different attacks are spread.
+	Jitter float64
+	// Timeout is the maximum time an attack revert attempt can take, 0 doesn't limit
+	// the attempts.
+	Timeout time.Duration
+}
+
+// validate checks the policy is valid.
+func (r RetryPolicy) validate() error {
+	if r.Attempts < 0 || r.Backoff < 0 || r.MaxBackoff < 0 || r.Timeout < 0 {
+		return fmt.Errorf("retry policy values can't be negative")
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return fmt.Errorf("retry policy jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// backoff returns the wait before the retry n (starting on 0).
+func (r RetryPolicy) backoff(n int, rnd float64) time.Duration {
+	d := r.Backoff
+	for j := 0; j < n && (r.MaxBackoff == 0 || d < r.MaxBackoff); j++ {
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+
+	// rnd is between 0 and 1, move it between -jitter and jitter.
+	return d + time.Duration(float64(d)*r.Jitter*(2*rnd-1))
+}
+
+// runningRevert is an attack revert running on background.
+type runningRevert struct {
+	errC   chan error
+	cancel context.CancelFunc
+}
+
+// revertAttack reverts an attack retrying the revert with the retry policy, the
+// waits between the retries end when the context is done.
+func (i *Injection) revertAttack(ctx context.Context, a attack.Attacker) error {
+	attempts := i.retry.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for n := 0; n < attempts; n++ {
+		if n > 0 {
+			wait := i.retry.backoff(n-1, i.jitterSource())
+			i.log.Warnf("error reverting attack, retrying in %s: %s", wait, err)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("revert retries cancelled: %s", err)
+			case <-i.clock.After(wait):
+			}
+		}
+		if err = i.revertAttempt(a); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// revertAttempt reverts an attack, if the revert takes more than the policy timeout
+// the attempt will fail and the revert context will be cancelled. The attackers can
+// ignore the context, so a new revert is not started while the one of a previous
+// attempt is still running, the attempt waits for it instead.
+func (i *Injection) revertAttempt(a attack.Attacker) error {
+	if i.retry.Timeout == 0 {
+		return attack.RevertContext(context.Background(), a)
+	}
+
+	rv := i.startRevert(a)
+	select {
+	case err := <-rv.errC:
+		i.revertsMu.Lock()
+		delete(i.reverts, a)
+		i.revertsMu.Unlock()
+		return err
+	case <-i.clock.After(i.retry.Timeout):
+		rv.cancel()
+		return fmt.Errorf("revert timed out after %s", i.retry.Timeout)
+	}
+}
+
+// startRevert starts reverting the attack on background, if the attack is already
+// being reverted the running revert is returned.
+func (i *Injection) startRevert(a attack.Attacker) *runningRevert {
+	i.revertsMu.Lock()
+	defer i.revertsMu.Unlock()
+	if rv, ok := i.reverts[a]; ok {
+		i.log.Warnf("waiting for the previous revert of the attack")
+		return rv
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rv := &runningRevert{errC: make(chan error, 1), cancel: cancel}
+	i.reverts[a] = rv
+	go func() {
+		defer cancel()
+		// The attacks that can't be cancelled are reverted directly so the
+		// result is the one of the real revert and not the cancellation.
+		if _, ok := a.(attack.ContextReverter); !ok {
+			rv.errC <- a.Revert()
+			return
+		}
+		rv.errC <- attack.RevertContext(ctx, a)
+	}()
+	return rv
+}
+
+// revertAll reverts all the attacks at the same time and returns the ones that
+// couldn't be reverted.
+func (i *Injection) revertAll(ctx context.Context, atts []attack.Attacker) ([]attack.Attacker, error) {
+	type result struct {
+		a   attack.Attacker
+		err error
+	}
+	resC := make(chan result, len(atts))
+	for _, a := range atts {
+		go func(a attack.Attacker) {
+			resC <- result{a: a, err: i.revertAttack(ctx, a)}
+		}(a)
+	}
+
+	var failed []attack.Attacker
+	errStr := ""
+	for range atts {
+		res := <-resC
+		if res.err != nil {
+			errStr = fmt.Sprintf("%s; %s", errStr, res.err)
+			failed = append(failed, res.a)
+		}
+	}
+
+	if errStr != "" {
+		return failed, errors.New(errStr)
+	}
+	return nil, nil
+}
@@ -0,0 +1,239 @@
+package injection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/attack"
+)
+
+// stage is a failure stage ready to be applied.
+type stage struct {
+	v1.Stage
+	steps [][]attack.Attacker // The attacks of every ramp step, only one step if the stage doesn't have ramp.
+}
+
+// newStage creates the attacks of every step of the stage.
+func newStage(st v1.Stage, reg attack.Registry) (*stage, error) {
+	if st.Duration < 0 || st.Delay < 0 {
+		return nil, fmt.Errorf("delay and duration can't be negative")
+	}
+
+	if st.Ramp == nil {
+		atts, err := newAttacks(st.Attacks, reg)
+		if err != nil {
+			return nil, err
+		}
+		return &stage{Stage: st, steps: [][]attack.Attacker{atts}}, nil
+	}
+
+	if st.Ramp.Steps < 1 {
+		return nil, fmt.Errorf("ramp needs at least one step")
+	}
+
+	// The schemas are used to get the ramped options decoded.
+	schemas := map[string]attack.Schema{}
+	for _, info := range reg.List() {
+		schemas[info.ID] = info.Schema
+	}
+
+	steps := make([][]attack.Attacker, st.Ramp.Steps)
+	for n := range steps {
+		ams := make([]v1.AttackMap, len(st.Attacks))
+		for j, am := range st.Attacks {
+			ams[j] = v1.AttackMap{}
+			for kind, opts := range am {
+				rOpts, err := rampOpts(opts, schemas[kind], st.Ramp, n+1)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s attack ramp: %s", kind, err)
+				}
+				ams[j][kind] = rOpts
+			}
+		}
+		atts, err := newAttacks(ams, reg)
+		if err != nil {
+			return nil, err
+		}
+		steps[n] = atts
+	}
+
+	return &stage{Stage: st, steps: steps}, nil
+}
+
+// rampOpts returns the options of a ramp step with the ramped options set to
+// step/steps of their value, the integer options are rounded up.
+func rampOpts(opts attack.Opts, s attack.Schema, r *v1.Ramp, step int) (attack.Opts, error) {
+	res := attack.Opts{}
+	if s != nil {
+		var err error
+		if res, err = s.Decode(opts); err != nil {
+			return nil, err
+		}
+	} else {
+		for k, v := range opts {
+			res[k] = v
+		}
+	}
+
+	for _, k := range r.Options {
+		switch v := res[k].(type) {
+		case int:
+			// Round up, a positive option is never ramped to 0.
+			if v > 0 {
+				res[k] = (v*step + r.Steps - 1) / r.Steps
+			} else {
+				res[k] = v * step / r.Steps
+			}
+		case float64:
+			res[k] = v * float64(step) / float64(r.Steps)
+		case nil:
+			return nil, fmt.Errorf("'%s' option is not set", k)
+		default:
+			return nil, fmt.Errorf("'%s' option with '%v' value is not numeric", k, v)
+		}
+	}
+	return res, nil
+}
+
+// runStages applies the stages sequentially until all of them have finished or
+// the context is done. If a stage fails the failure context will be cancelled so
+// the failure is reverted.
+func (i *Injection) runStages(ctx context.Context) {
+	defer i.wg.Done()
+
+	for n, st := range i.stages {
+		if err := i.runStage(ctx, st); err != nil {
+			i.failBackground(fmt.Errorf("error applying stage %d: %s", n, err))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		i.log.Infof("stage %d of '%s' failure finished", n, i.Metadata.ID)
+	}
+}
+
+// runStage applies a stage and returns when the stage has finished, the attacks of
+// a stage without duration will be kept applied.
+func (i *Injection) runStage(ctx context.Context, st *stage) error {
+	if st.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-i.clock.After(st.Delay):
+		}
+	}
+
+	var end <-chan time.Time
+	if st.Duration > 0 {
+		end = i.clock.After(st.Duration)
+	}
+
+	// Apply every step reverting the previous one.
+	var applied []attack.Attacker
+	finished := false
+	for n, atts := range st.steps {
+		if n > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-end:
+				finished = true
+			case <-i.clock.After(st.Ramp.Interval):
+			}
+			if finished {
+				break
+			}
+			if err := i.revertAttacks(applied); err != nil {
+				return err
+			}
+		}
+		if err := i.applyAttacks(ctx, atts); err != nil {
+			return err
+		}
+		applied = atts
+	}
+
+	if st.Duration == 0 {
+		return nil
+	}
+	if !finished {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-end:
+		}
+	}
+	return i.revertAttacks(applied)
+}
+
+// applyAttacks applies the attacks at the same time and tracks them as applied, if
+// any of the attacks fails the applied ones will be reverted.
+func (i *Injection) applyAttacks(ctx context.Context, atts []attack.Attacker) error {
+	type result struct {
+		a   attack.Attacker
+		err error
+	}
+	i.attsMu.Lock()
+	resCh := make(chan result, len(atts))
+	for _, a := range atts {
+		go func(a attack.Attacker) {
+			resCh <- result{a: a, err: i.applyAttack(ctx, a)}
+		}(a)
+	}
+
+	var applied []attack.Attacker
+	errStr := ""
+	for range atts {
+		res := <-resCh
+		if res.err != nil {
+			errStr = fmt.Sprintf("%s; %s", errStr, res.err)
+			continue
+		}
+		applied = append(applied, res.a)
+	}
+	i.attsMu.Unlock()
+
+	i.Lock()
+	i.appliedAtts = append(i.appliedAtts, applied...)
+	i.Unlock()
+
+	if errStr != "" {
+		if err := i.revertAttacks(applied); err != nil {
+			i.log.Error(err)
+		}
+		return fmt.Errorf("error applying attacks: %s", errStr)
+	}
+	return nil
+}
+
+// revertAttacks reverts the attacks and stops tracking them as applied.
+func (i *Injection) revertAttacks(atts []attack.Attacker) error {
+	i.attsMu.Lock()
+	defer i.attsMu.Unlock()
+
+	errStr := ""
+	for _, a := range atts {
+		if err := i.revertAttack(context.Background(), a); err != nil {
+			errStr = fmt.Sprintf("%s; %s", errStr, err)
+		}
+	}
+
+	i.Lock()
+	for _, a := range atts {
+		for j, aa := range i.appliedAtts {
+			if aa == a {
+				i.appliedAtts = append(i.appliedAtts[:j], i.appliedAtts[j+1:]...)
+				break
+			}
+		}
+	}
+	i.Unlock()
+
+	if errStr != "" {
+		return fmt.Errorf("error reverting attacks: %s", errStr)
+	}
+	return nil
+}
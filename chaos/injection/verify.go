@@ -0,0 +1,69 @@
+package injection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slok/ragnarok/attack"
+)
+
+// hasVerifiers returns true if any of the attacks can be verified.
+func hasVerifiers(atts []attack.Attacker, stgs []*stage) bool {
+	all := append([]attack.Attacker{}, atts...)
+	for _, st := range stgs {
+		for _, step := range st.steps {
+			all = append(all, step...)
+		}
+	}
+
+	for _, a := range all {
+		if _, ok := a.(attack.Verifier); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// runVerification verifies the applied attacks on every interval until the context
+// is done, if the verification fails the failure will be errored.
+func (i *Injection) runVerification(ctx context.Context) {
+	defer i.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.clock.After(i.verifyInterval):
+		}
+
+		if err := i.verify(ctx); err != nil {
+			// Verifications interrupted by the revert are not failures.
+			if ctx.Err() != nil {
+				return
+			}
+			i.failBackground(fmt.Errorf("attack verification failed: %s", err))
+			return
+		}
+	}
+}
+
+// verify verifies the applied attacks that implement attack.Verifier.
+func (i *Injection) verify(ctx context.Context) error {
+	i.attsMu.Lock()
+	defer i.attsMu.Unlock()
+
+	i.Lock()
+	atts := append([]attack.Attacker{}, i.appliedAtts...)
+	i.Unlock()
+
+	for _, a := range atts {
+		v, ok := a.(attack.Verifier)
+		if !ok {
+			continue
+		}
+		if err := v.Verify(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
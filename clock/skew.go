@@ -0,0 +1,161 @@
+package clock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSkewPath is the default file where the clock skew offset is published.
+	DefaultSkewPath = "/tmp/ragnarok-time-skew"
+
+	// defaultSkewRefresh is the interval the skew offset is read again from the file.
+	defaultSkewRefresh = 1 * time.Second
+)
+
+// ReadSkewOffset reads the skew offset from a file, if the file is missing the
+// offset will be 0.
+func ReadSkewOffset(path string) (time.Duration, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	s := strings.TrimSpace(string(b))
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid skew offset '%s': %s", s, err)
+	}
+	return d, nil
+}
+
+// WriteSkewOffset writes the skew offset on a file, the write is atomic so the
+// readers never see a partial offset.
+func WriteSkewOffset(path string, offset time.Duration) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(offset.String()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// skew is a clock implementation of Clock interface that applies an offset
+// read from a file to the times.
+type skew struct {
+	base    Clock
+	path    string
+	refresh time.Duration
+
+	offset time.Duration // The cached offset.
+	readAt time.Time     // The last time the offset was read.
+	mu     sync.Mutex
+}
+
+// NewSkew returns a new clock that will apply the offset published on the path
+// file to the times it returns, the offset is read again every second. The
+// times of the tickers are forwarded by a goroutine that can't know when they
+// are stopped, so tickers should be reused instead of created repeatedly.
+func NewSkew(path string) Clock {
+	return newSkew(Base(), path, defaultSkewRefresh)
+}
+
+func newSkew(base Clock, path string, refresh time.Duration) *skew {
+	return &skew{
+		base:    base,
+		path:    path,
+		refresh: refresh,
+	}
+}
+
+// Offset returns the current offset of the clock, on error reading the offset
+// the last known offset will be used.
+func (s *skew) Offset() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.base.Now()
+	if !s.readAt.IsZero() && now.Sub(s.readAt) < s.refresh {
+		return s.offset
+	}
+	if offset, err := ReadSkewOffset(s.path); err == nil {
+		s.offset = offset
+	}
+	s.readAt = now
+	return s.offset
+}
+
+func (s *skew) After(d time.Duration) <-chan time.Time {
+	return s.forward(s.base.After(d), true)
+}
+func (s *skew) Now() time.Time {
+	return s.base.Now().Add(s.Offset())
+}
+func (s *skew) Sleep(d time.Duration) {
+	s.base.Sleep(d)
+}
+func (s *skew) Tick(d time.Duration) <-chan time.Time {
+	return s.forward(s.base.Tick(d), false)
+}
+func (s *skew) NewTicker(d time.Duration) *time.Ticker {
+	t := s.base.NewTicker(d)
+	t.C = s.forward(t.C, false)
+	return t
+}
+
+// NewTimer returns a timer that sends the skewed time when it fires, the time is
+// sent by the timer function so stopping the timer leaves nothing running.
+func (s *skew) NewTimer(d time.Duration) *time.Timer {
+	c := make(chan time.Time, 1)
+	t := time.AfterFunc(d, func() {
+		select {
+		case c <- s.Now():
+		default:
+		}
+	})
+	t.C = c
+	return t
+}
+
+// forward returns a channel that will receive the times of the source channel
+// with the offset applied, like the time package channels it will drop the
+// times if the receiver is not ready. If once is set it will stop after
+// forwarding the first time.
+func (s *skew) forward(src <-chan time.Time, once bool) <-chan time.Time {
+	dst := make(chan time.Time, 1)
+	go func() {
+		for t := range src {
+			select {
+			case dst <- t.Add(s.Offset()):
+			default:
+			}
+			if once {
+				return
+			}
+		}
+	}()
+	return dst
+}
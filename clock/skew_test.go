@@ -0,0 +1,186 @@
+package clock
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkewOffsetReadWrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   *string
+		expOffset time.Duration
+		expErr    bool
+	}{
+		{
+			name:      "A missing offset file should have 0 offset.",
+			expOffset: 0,
+		},
+		{
+			name:      "An empty offset file should have 0 offset.",
+			content:   stringPtr(""),
+			expOffset: 0,
+		},
+		{
+			name:      "A positive offset should be read.",
+			content:   stringPtr("1h30m\n"),
+			expOffset: 90 * time.Minute,
+		},
+		{
+			name:      "A negative offset should be read.",
+			content:   stringPtr("-5m"),
+			expOffset: -5 * time.Minute,
+		},
+		{
+			name:    "An invalid offset should error.",
+			content: stringPtr("tomorrow"),
+			expErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			dir, err := ioutil.TempDir("", "ragnarok-skew-test")
+			require.NoError(err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "skew")
+			if test.content != nil {
+				require.NoError(ioutil.WriteFile(path, []byte(*test.content), 0644))
+			}
+
+			offset, err := ReadSkewOffset(path)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expOffset, offset)
+			}
+		})
+	}
+}
+
+func TestSkewOffsetWrite(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-skew-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "skew")
+
+	for _, exp := range []time.Duration{time.Hour, -30 * time.Second, 0} {
+		require.NoError(WriteSkewOffset(path, exp))
+		got, err := ReadSkewOffset(path)
+		require.NoError(err)
+		assert.Equal(exp, got)
+	}
+
+	// Only the offset file should be on the directory.
+	fs, err := ioutil.ReadDir(dir)
+	require.NoError(err)
+	assert.Len(fs, 1)
+}
+
+func TestSkewClock(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-skew-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "skew")
+
+	// Without refresh cache so every call reads the offset.
+	s := newSkew(Base(), path, 0)
+	assert.WithinDuration(time.Now(), s.Now(), time.Second)
+
+	offset := 24 * time.Hour
+	require.NoError(WriteSkewOffset(path, offset))
+	assert.WithinDuration(time.Now().Add(offset), s.Now(), time.Second)
+
+	// The channel times should have the offset.
+	select {
+	case got := <-s.After(time.Millisecond):
+		assert.WithinDuration(time.Now().Add(offset), got, time.Second)
+	case <-time.After(time.Second):
+		assert.Fail("after didn't fire")
+	}
+
+	tk := s.NewTicker(time.Millisecond)
+	for i := 0; i < 3; i++ {
+		select {
+		case got := <-tk.C:
+			assert.WithinDuration(time.Now().Add(offset), got, time.Second)
+		case <-time.After(time.Second):
+			assert.Fail("ticker didn't tick")
+		}
+	}
+	tk.Stop()
+
+	tm := s.NewTimer(time.Millisecond)
+	select {
+	case got := <-tm.C:
+		assert.WithinDuration(time.Now().Add(offset), got, time.Second)
+	case <-time.After(time.Second):
+		assert.Fail("timer didn't fire")
+	}
+
+	// Reset the offset.
+	require.NoError(WriteSkewOffset(path, 0))
+	assert.WithinDuration(time.Now(), s.Now(), time.Second)
+}
+
+func TestSkewClockRefresh(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "ragnarok-skew-test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "skew")
+
+	require.NoError(WriteSkewOffset(path, time.Hour))
+	s := newSkew(Base(), path, time.Hour)
+	assert.Equal(time.Hour, s.Offset())
+
+	// The offset is cached until the refresh interval.
+	require.NoError(WriteSkewOffset(path, 2*time.Hour))
+	assert.Equal(time.Hour, s.Offset())
+	s.readAt = time.Now().Add(-2 * time.Hour)
+	assert.Equal(2*time.Hour, s.Offset())
+
+	// On errors the last offset is kept.
+	require.NoError(ioutil.WriteFile(path, []byte("wrong"), 0644))
+	s.readAt = time.Time{}
+	assert.Equal(2*time.Hour, s.Offset())
+}
+
+func TestSkewClockTimersStop(t *testing.T) {
+	s := newSkew(Base(), filepath.Join(os.TempDir(), "ragnarok-skew-test-missing"), time.Hour)
+	before := runtime.NumGoroutine()
+
+	// The stopped and the fired timers shouldn't leave goroutines running.
+	for i := 0; i < 10; i++ {
+		tm := s.NewTimer(time.Hour)
+		require.True(t, tm.Stop())
+		<-s.NewTimer(time.Millisecond).C
+	}
+
+	for i := 0; runtime.NumGoroutine() > before; i++ {
+		require.True(t, i < 100, "timer goroutines still running")
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/slok/ragnarok/apimachinery/serializer"
 	"github.com/slok/ragnarok/apimachinery/validator"
 	"github.com/slok/ragnarok/apimachinery/watch"
+	"github.com/slok/ragnarok/attack"
+	// Register all the attacks so the failures can be validated.
+	_ "github.com/slok/ragnarok/attack/all"
 	clichaosv1 "github.com/slok/ragnarok/client/api/chaos/v1"
 	cliclusterv1 "github.com/slok/ragnarok/client/api/cluster/v1"
 	"github.com/slok/ragnarok/client/controller"
@@ -40,6 +43,7 @@ type masterDependencies struct {
 	logger           log.Logger
 	nodeStatus       service.NodeStatusService
 	failureStatus    service.FailureStatusService
+	attackCatalog    service.AttackCatalogService
 	serializer       serializer.Serializer
 }
 
@@ -50,7 +54,7 @@ func createGRPCServer(cfg config.Config, deps masterDependencies, logger log.Log
 	if err != nil {
 		return nil, err
 	}
-	srvServer := server.NewMasterGRPCServiceServer(deps.failureStatus, deps.nodeStatus, l, clock.Base(), logger)
+	srvServer := server.NewMasterGRPCServiceServer(deps.failureStatus, deps.nodeStatus, deps.attackCatalog, l, clock.Base(), logger)
 	return srvServer, nil
 }
 
@@ -61,7 +65,7 @@ func createHTTPServer(cfg config.Config, deps masterDependencies, logger log.Log
 		return nil, err
 	}
 
-	return web.NewDefaultHTTPServer(deps.serializer, deps.nodeClient, l, logger)
+	return web.NewDefaultHTTPServer(deps.serializer, deps.nodeClient, deps.attackCatalog, l, logger)
 }
 
 // TODO: Debugging stuff, remove.
@@ -102,8 +106,17 @@ func Main() error {
 	// Create dependencies
 	eventMux := watch.NewDefaultBroadcasterFactory(logger)
 	memoryRepoClient := memrepository.NewDefaultClient(eventMux, logger)
-	validator := validator.DefaultObject
-	nodeCli := cliclusterv1.NewNodeClient(validator, memoryRepoClient)
+	// The failures can use the attacks of the master registry and the ones reported
+	// by their nodes (like the node plugin attacks).
+	var nodeCli *cliclusterv1.NodeClient
+	validator := validator.NewObjectWithNodeAttacks(attack.BaseReg(), validator.NodeAttacksGetterFunc(func(nodeID string) ([]string, error) {
+		n, err := nodeCli.Get(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		return n.Spec.Attacks, nil
+	}))
+	nodeCli = cliclusterv1.NewNodeClient(validator, memoryRepoClient)
 	failureCli := clichaosv1.NewFailureClient(validator, memoryRepoClient)
 	experimentCli := clichaosv1.NewExperimentClient(validator, memoryRepoClient)
 
@@ -113,6 +126,7 @@ func Main() error {
 		experimentClient: experimentCli,
 		nodeStatus:       service.NewNodeStatus(*cfg, nodeCli, logger),
 		failureStatus:    service.NewFailureStatus(failureCli, logger),
+		attackCatalog:    service.NewAttackCatalog(attack.BaseReg(), logger),
 		serializer:       serializer.DefaultSerializer,
 	}
 
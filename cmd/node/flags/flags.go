@@ -12,14 +12,21 @@ import (
 
 const (
 	// Default values.
-	defaultDebug  = false
-	defaultDryRun = false
+	defaultDebug      = false
+	defaultDryRun     = false
+	defaultJournalDir = "/var/lib/ragnarok/journal"
 )
 
 type config struct {
 	fs                *flag.FlagSet
 	masterAddress     string
 	heartbeatInterval string
+	pluginDir         string
+	journalDir        string
+	applyTimeout      string
+	revertAttempts    int
+	revertTimeout     string
+	revertJanitor     string
 	debug             bool
 	dryRun            bool
 }
@@ -39,6 +46,36 @@ func new() *config {
 		"Time interval the node will send a heartbeat to the master",
 	)
 
+	cfg.fs.StringVar(
+		&cfg.pluginDir, "plugin.dir", "",
+		"Directory with the attack plugin executables",
+	)
+
+	cfg.fs.StringVar(
+		&cfg.journalDir, "journal.dir", defaultJournalDir,
+		"Directory where the applied attacks are recorded to revert them after a crash, empty disables it",
+	)
+
+	cfg.fs.StringVar(
+		&cfg.applyTimeout, "apply.timeout", "0s",
+		"Maximum time an attack apply can take, 0 uses the default",
+	)
+
+	cfg.fs.IntVar(
+		&cfg.revertAttempts, "revert.attempts", 0,
+		"Attempts of every attack revert, 0 uses the default",
+	)
+
+	cfg.fs.StringVar(
+		&cfg.revertTimeout, "revert.timeout", "0s",
+		"Maximum time an attack revert attempt can take, 0 uses the default",
+	)
+
+	cfg.fs.StringVar(
+		&cfg.revertJanitor, "revert.janitor-interval", "0s",
+		"Time interval the failed reverts are retried, 0 uses the default",
+	)
+
 	cfg.fs.BoolVar(
 		&cfg.debug, "run.debug", defaultDebug,
 		"Run in debug mode",
@@ -72,6 +109,22 @@ func (c *config) parse(args []string) error {
 		err = fmt.Errorf("invalid heartbeat interval")
 	}
 
+	// Check apply valid timing.
+	if d, aErr := time.ParseDuration(c.applyTimeout); aErr != nil || d < 0 {
+		err = fmt.Errorf("invalid apply timeout")
+	}
+
+	// Check revert valid settings.
+	if c.revertAttempts < 0 {
+		err = fmt.Errorf("invalid revert attempts")
+	}
+	if d, rErr := time.ParseDuration(c.revertTimeout); rErr != nil || d < 0 {
+		err = fmt.Errorf("invalid revert timeout")
+	}
+	if d, rErr := time.ParseDuration(c.revertJanitor); rErr != nil || d < 0 {
+		err = fmt.Errorf("invalid revert janitor interval")
+	}
+
 	return err
 }
 
@@ -85,12 +138,21 @@ func GetNodeConfig(args []string) (*nodeconfig.Config, error) {
 
 	// Parse intervals (parsing error validated on the parse).
 	d, _ := time.ParseDuration(cfg.heartbeatInterval)
+	at, _ := time.ParseDuration(cfg.applyTimeout)
+	rt, _ := time.ParseDuration(cfg.revertTimeout)
+	rj, _ := time.ParseDuration(cfg.revertJanitor)
 
 	nodeCfg := &nodeconfig.Config{
-		MasterAddress:     cfg.masterAddress,
-		HeartbeatInterval: d,
-		Debug:             cfg.debug,
-		DryRun:            cfg.dryRun,
+		MasterAddress:         cfg.masterAddress,
+		HeartbeatInterval:     d,
+		PluginDir:             cfg.pluginDir,
+		JournalDir:            cfg.journalDir,
+		ApplyTimeout:          at,
+		RevertAttempts:        cfg.revertAttempts,
+		RevertTimeout:         rt,
+		RevertJanitorInterval: rj,
+		Debug:                 cfg.debug,
+		DryRun:                cfg.dryRun,
 	}
 
 	if err := nodeCfg.Validate(); err != nil {
@@ -29,6 +29,7 @@ func TestCofiguration(t *testing.T) {
 			config.Config{
 				MasterAddress:     "127.0.0.1:8080",
 				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/var/lib/ragnarok/journal",
 				Debug:             true,
 				DryRun:            false,
 			},
@@ -42,6 +43,7 @@ func TestCofiguration(t *testing.T) {
 			config.Config{
 				MasterAddress:     "127.0.0.1:8080",
 				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/var/lib/ragnarok/journal",
 				Debug:             false,
 				DryRun:            true,
 			},
@@ -55,6 +57,7 @@ func TestCofiguration(t *testing.T) {
 			config.Config{
 				MasterAddress:     "127.0.0.1:8080",
 				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/var/lib/ragnarok/journal",
 				Debug:             false,
 				DryRun:            true,
 			},
@@ -68,11 +71,69 @@ func TestCofiguration(t *testing.T) {
 			config.Config{
 				MasterAddress:     "127.0.0.1:8080",
 				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/var/lib/ragnarok/journal",
 				Debug:             false,
 				DryRun:            true,
 			},
 			false,
 		},
+		{
+			[]string{
+				"-master.address", "127.0.0.1:8080",
+				"-plugin.dir", "/usr/lib/ragnarok/plugins",
+			},
+			config.Config{
+				MasterAddress:     "127.0.0.1:8080",
+				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/var/lib/ragnarok/journal",
+				PluginDir:         "/usr/lib/ragnarok/plugins",
+			},
+			false,
+		},
+		{
+			[]string{
+				"-master.address", "127.0.0.1:8080",
+				"-journal.dir", "/tmp/ragnarok/journal",
+			},
+			config.Config{
+				MasterAddress:     "127.0.0.1:8080",
+				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/tmp/ragnarok/journal",
+			},
+			false,
+		},
+		{
+			[]string{
+				"-master.address", "127.0.0.1:8080",
+				"-journal.dir", "",
+			},
+			config.Config{
+				MasterAddress:     "127.0.0.1:8080",
+				HeartbeatInterval: 15 * time.Second,
+			},
+			false,
+		},
+		{
+			[]string{
+				"-master.address", "127.0.0.1:8080",
+				"-apply.timeout", "2m",
+			},
+			config.Config{
+				MasterAddress:     "127.0.0.1:8080",
+				HeartbeatInterval: 15 * time.Second,
+				JournalDir:        "/var/lib/ragnarok/journal",
+				ApplyTimeout:      2 * time.Minute,
+			},
+			false,
+		},
+		{
+			[]string{
+				"-master.address", "127.0.0.1:8080",
+				"-apply.timeout", "-2m",
+			},
+			config.Config{},
+			true,
+		},
 		{
 			[]string{
 				"--heartbeat.interval", "-15s",
@@ -4,13 +4,21 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"google.golang.org/grpc"
 
 	"github.com/google/uuid"
 	clusterv1 "github.com/slok/ragnarok/api/cluster/v1"
 	"github.com/slok/ragnarok/apimachinery/serializer"
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/attack/journal"
+	"github.com/slok/ragnarok/attack/plugin"
+	"github.com/slok/ragnarok/chaos/injection"
+	// Register all the attacks.
+	_ "github.com/slok/ragnarok/attack/all"
 	"github.com/slok/ragnarok/clock"
 	"github.com/slok/ragnarok/cmd/node/flags"
 	"github.com/slok/ragnarok/log"
@@ -19,6 +27,25 @@ import (
 	"github.com/slok/ragnarok/node/service"
 )
 
+const (
+	// defaultRevertJanitorInterval is the default interval the failed reverts are retried.
+	defaultRevertJanitorInterval = time.Minute
+	// defaultApplyTimeout is the default maximum time an attack apply can take.
+	defaultApplyTimeout = 5 * time.Minute
+)
+
+var (
+	cleanersMu sync.Mutex
+	cleaners   []func() // The functions called when cleaning, in reverse order.
+)
+
+// addCleaner registers a function that will be called when cleaning.
+func addCleaner(f func()) {
+	cleanersMu.Lock()
+	defer cleanersMu.Unlock()
+	cleaners = append(cleaners, f)
+}
+
 // Main run main logic.
 func Main() error {
 	nodeID := uuid.New().String()
@@ -57,12 +84,71 @@ func Main() error {
 		return err
 	}
 
+	// Load the attack plugins.
+	if cfg.PluginDir != "" {
+		ps, err := plugin.Load(cfg.PluginDir, attack.BaseReg())
+		if err != nil {
+			return fmt.Errorf("could not load the attack plugins: %v", err)
+		}
+		addCleaner(func() {
+			for _, p := range ps {
+				if err := p.Close(); err != nil {
+					logger.Warnf("%s plugin exited with error: %v", p.Path, err)
+				}
+			}
+		})
+	}
+
+	// Revert the attacks left by a previous run before registering on the master.
+	injCfg := injection.Config{
+		RevertRetry:  injection.DefaultRetryPolicy,
+		ApplyTimeout: defaultApplyTimeout,
+	}
+	if cfg.ApplyTimeout > 0 {
+		injCfg.ApplyTimeout = cfg.ApplyTimeout
+	}
+	if cfg.RevertAttempts > 0 {
+		injCfg.RevertRetry.Attempts = cfg.RevertAttempts
+	}
+	if cfg.RevertTimeout > 0 {
+		injCfg.RevertRetry.Timeout = cfg.RevertTimeout
+	}
+	// On dry run the attacks are not applied, there is nothing to record.
+	if cfg.JournalDir != "" && !cfg.DryRun {
+		j, err := journal.New(cfg.JournalDir, logger)
+		if err != nil {
+			return fmt.Errorf("could not open the attack journal: %v", err)
+		}
+		if err := j.Recover(attack.BaseReg()); err != nil {
+			logger.Errorf("could not recover all the attacks of the journal: %v", err)
+		}
+		injCfg.Registry = journal.NewRegistry(attack.BaseReg(), j)
+	}
+
 	// Create services.
 	apiNode := clusterv1.NewNode()
 	apiNode.Metadata.ID = nodeID
 	apiNode.Metadata.Labels = nodeTags
+	// Report the attacks the node supports when registering.
+	for _, info := range attack.List() {
+		apiNode.Spec.Attacks = append(apiNode.Spec.Attacks, info.ID)
+	}
 	stSrv := service.NewNodeStatus(&apiNode, nsCli, clock.Base(), logger)
-	fSrv := service.NewLogFailureState(nodeID, fCli, clock.Base(), logger)
+	// On dry run the failures are only logged.
+	var fSrv service.FailureState
+	if cfg.DryRun {
+		fSrv = service.NewLogFailureState(nodeID, fCli, clock.Base(), logger)
+	} else {
+		janitorInterval := defaultRevertJanitorInterval
+		if cfg.RevertJanitorInterval > 0 {
+			janitorInterval = cfg.RevertJanitorInterval
+		}
+		janitor, err := injection.NewJanitor(janitorInterval, clock.Base(), logger)
+		if err != nil {
+			return err
+		}
+		fSrv = service.NewInjectionFailureState(nodeID, fCli, injCfg, janitor, clock.Base(), logger)
+	}
 
 	// Create the node.
 	n := node.NewFailureNode(nodeID, *cfg, stSrv, fSrv, logger)
@@ -75,12 +161,24 @@ func Main() error {
 	if err := n.Start(); err != nil {
 		return fmt.Errorf("could not start the node: %v", err)
 	}
+	// Stop the node reverting the injected failures before exiting.
+	addCleaner(func() {
+		if err := n.Stop(); err != nil {
+			logger.Errorf("could not stop the node: %v", err)
+		}
+	})
 
 	return nil
 }
 
 func clean() {
 	log.Debug("Cleaning...")
+	cleanersMu.Lock()
+	defer cleanersMu.Unlock()
+	for i := len(cleaners) - 1; i >= 0; i-- {
+		cleaners[i]()
+	}
+	cleaners = nil
 }
 
 func main() {
@@ -9,3 +9,6 @@ package grpc // import "github.com/slok/ragnarok/grpc"
 
 // Failure protos
 //go:generate protoc -I. -I../../../.. failurestatus/failurestatus.proto --gofast_out=plugins=grpc:.
+
+// Attack catalog protos
+//go:generate protoc -I. -I../../../.. -I${GOOGLEPROTO_PATH} attackcatalog/attackcatalog.proto --gofast_out=plugins=grpc:.
@@ -11,12 +11,14 @@ It has these top-level messages:
 	NodeId
 	FailureId
 	FailuresState
+	FailureStatusReport
 */
 package github_com_slok_ragnarok_grpc_failurestatus
 
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/empty"
 import github_com_slok_ragnarok_api_chaos_v1_pb "github.com/slok/ragnarok/api/chaos/v1/pb"
 
 import (
@@ -88,10 +90,36 @@ func (m *FailuresState) GetFailures() []*github_com_slok_ragnarok_api_chaos_v1_p
 	return nil
 }
 
+// FailureStatusReport is the status of a failure reported by a node.
+type FailureStatusReport struct {
+	NodeId  string                                           `protobuf:"bytes,1,opt,name=nodeId,proto3" json:"nodeId,omitempty"`
+	Failure *github_com_slok_ragnarok_api_chaos_v1_pb.Failure `protobuf:"bytes,2,opt,name=failure" json:"failure,omitempty"`
+}
+
+func (m *FailureStatusReport) Reset()                    { *m = FailureStatusReport{} }
+func (m *FailureStatusReport) String() string            { return proto.CompactTextString(m) }
+func (*FailureStatusReport) ProtoMessage()               {}
+func (*FailureStatusReport) Descriptor() ([]byte, []int) { return fileDescriptorFailurestatus, []int{3} }
+
+func (m *FailureStatusReport) GetNodeId() string {
+	if m != nil {
+		return m.NodeId
+	}
+	return ""
+}
+
+func (m *FailureStatusReport) GetFailure() *github_com_slok_ragnarok_api_chaos_v1_pb.Failure {
+	if m != nil {
+		return m.Failure
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*NodeId)(nil), "github.com.slok.ragnarok.grpc.failurestatus.NodeId")
 	proto.RegisterType((*FailureId)(nil), "github.com.slok.ragnarok.grpc.failurestatus.FailureId")
 	proto.RegisterType((*FailuresState)(nil), "github.com.slok.ragnarok.grpc.failurestatus.FailuresState")
+	proto.RegisterType((*FailureStatusReport)(nil), "github.com.slok.ragnarok.grpc.failurestatus.FailureStatusReport")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -109,6 +137,8 @@ type FailureStatusClient interface {
 	FailureStateList(ctx context.Context, in *NodeId, opts ...grpc.CallOption) (FailureStatus_FailureStateListClient, error)
 	// GetFailure asks for a failure.
 	GetFailure(ctx context.Context, in *FailureId, opts ...grpc.CallOption) (*github_com_slok_ragnarok_api_chaos_v1_pb.Failure, error)
+	// ReportFailureStatus reports the status of a failure on a node.
+	ReportFailureStatus(ctx context.Context, in *FailureStatusReport, opts ...grpc.CallOption) (*google_protobuf.Empty, error)
 }
 
 type failureStatusClient struct {
@@ -160,6 +190,15 @@ func (c *failureStatusClient) GetFailure(ctx context.Context, in *FailureId, opt
 	return out, nil
 }
 
+func (c *failureStatusClient) ReportFailureStatus(ctx context.Context, in *FailureStatusReport, opts ...grpc.CallOption) (*google_protobuf.Empty, error) {
+	out := new(google_protobuf.Empty)
+	err := grpc.Invoke(ctx, "/github.com.slok.ragnarok.grpc.failurestatus.FailureStatus/ReportFailureStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for FailureStatus service
 
 type FailureStatusServer interface {
@@ -167,6 +206,8 @@ type FailureStatusServer interface {
 	FailureStateList(*NodeId, FailureStatus_FailureStateListServer) error
 	// GetFailure asks for a failure.
 	GetFailure(context.Context, *FailureId) (*github_com_slok_ragnarok_api_chaos_v1_pb.Failure, error)
+	// ReportFailureStatus reports the status of a failure on a node.
+	ReportFailureStatus(context.Context, *FailureStatusReport) (*google_protobuf.Empty, error)
 }
 
 func RegisterFailureStatusServer(s *grpc.Server, srv FailureStatusServer) {
@@ -212,6 +253,24 @@ func _FailureStatus_GetFailure_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FailureStatus_ReportFailureStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FailureStatusReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FailureStatusServer).ReportFailureStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/github.com.slok.ragnarok.grpc.failurestatus.FailureStatus/ReportFailureStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FailureStatusServer).ReportFailureStatus(ctx, req.(*FailureStatusReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _FailureStatus_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "github.com.slok.ragnarok.grpc.failurestatus.FailureStatus",
 	HandlerType: (*FailureStatusServer)(nil),
@@ -220,6 +279,10 @@ var _FailureStatus_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetFailure",
 			Handler:    _FailureStatus_GetFailure_Handler,
 		},
+		{
+			MethodName: "ReportFailureStatus",
+			Handler:    _FailureStatus_ReportFailureStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -309,6 +372,40 @@ func (m *FailuresState) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *FailureStatusReport) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FailureStatusReport) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.NodeId) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintFailurestatus(dAtA, i, uint64(len(m.NodeId)))
+		i += copy(dAtA[i:], m.NodeId)
+	}
+	if m.Failure != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintFailurestatus(dAtA, i, uint64(m.Failure.Size()))
+		n1, err := m.Failure.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	return i, nil
+}
+
 func encodeVarintFailurestatus(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -350,6 +447,20 @@ func (m *FailuresState) Size() (n int) {
 	return n
 }
 
+func (m *FailureStatusReport) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.NodeId)
+	if l > 0 {
+		n += 1 + l + sovFailurestatus(uint64(l))
+	}
+	if m.Failure != nil {
+		l = m.Failure.Size()
+		n += 1 + l + sovFailurestatus(uint64(l))
+	}
+	return n
+}
+
 func sovFailurestatus(x uint64) (n int) {
 	for {
 		n++
@@ -602,6 +713,118 @@ func (m *FailuresState) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *FailureStatusReport) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowFailurestatus
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FailureStatusReport: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FailureStatusReport: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFailurestatus
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthFailurestatus
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NodeId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Failure", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowFailurestatus
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthFailurestatus
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Failure == nil {
+				m.Failure = &github_com_slok_ragnarok_api_chaos_v1_pb.Failure{}
+			}
+			if err := m.Failure.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipFailurestatus(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthFailurestatus
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipFailurestatus(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -710,23 +933,28 @@ var (
 func init() { proto.RegisterFile("failurestatus/failurestatus.proto", fileDescriptorFailurestatus) }
 
 var fileDescriptorFailurestatus = []byte{
-	// 276 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0x4c, 0x4b, 0xcc, 0xcc,
-	0x29, 0x2d, 0x4a, 0x2d, 0x2e, 0x49, 0x2c, 0x29, 0x2d, 0xd6, 0x47, 0xe1, 0xe9, 0x15, 0x14, 0xe5,
-	0x97, 0xe4, 0x0b, 0x69, 0xa7, 0x67, 0x96, 0x64, 0x94, 0x26, 0xe9, 0x25, 0xe7, 0xe7, 0xea, 0x15,
-	0xe7, 0xe4, 0x67, 0xeb, 0x15, 0x25, 0xa6, 0xe7, 0x25, 0x16, 0xe5, 0x67, 0xeb, 0xa5, 0x17, 0x15,
-	0x24, 0xeb, 0xa1, 0x68, 0x91, 0x32, 0x43, 0x28, 0xd6, 0x07, 0x29, 0xd6, 0x87, 0x29, 0xd6, 0x4f,
-	0x2c, 0xc8, 0xd4, 0x4f, 0xce, 0x48, 0xcc, 0x2f, 0xd6, 0x2f, 0x33, 0xd4, 0x2f, 0x48, 0x82, 0x59,
-	0x05, 0xb1, 0x44, 0x49, 0x82, 0x8b, 0xcd, 0x2f, 0x3f, 0x25, 0xd5, 0x33, 0x45, 0x88, 0x8f, 0x8b,
-	0x29, 0x33, 0x45, 0x82, 0x51, 0x81, 0x51, 0x83, 0x33, 0x88, 0x29, 0x33, 0x45, 0x49, 0x9a, 0x8b,
-	0xd3, 0x0d, 0xa2, 0x14, 0x8b, 0x64, 0x1c, 0x17, 0x2f, 0x54, 0xb2, 0x38, 0xb8, 0x24, 0xb1, 0x24,
-	0x55, 0xc8, 0x97, 0x8b, 0x03, 0xe6, 0x20, 0x09, 0x46, 0x05, 0x66, 0x0d, 0x6e, 0x23, 0x43, 0x3d,
-	0x9c, 0xee, 0x4f, 0x2c, 0xc8, 0xd4, 0x03, 0x3b, 0x49, 0xaf, 0xcc, 0x50, 0xaf, 0x20, 0x49, 0x0f,
-	0x6a, 0x54, 0x10, 0xdc, 0x08, 0xa3, 0x09, 0x4c, 0x70, 0x0b, 0x82, 0xc1, 0x1e, 0x14, 0x6a, 0x65,
-	0xe4, 0x12, 0x40, 0x12, 0x49, 0xf5, 0xc9, 0x2c, 0x2e, 0x11, 0x32, 0xd6, 0x23, 0x21, 0x8c, 0xf4,
-	0x20, 0x1e, 0x95, 0xb2, 0x22, 0x49, 0x13, 0x8a, 0x37, 0x0d, 0x18, 0x85, 0xca, 0xb9, 0xb8, 0xdc,
-	0x53, 0x4b, 0xa0, 0xa2, 0x42, 0x66, 0xe4, 0x98, 0xe5, 0x99, 0x22, 0x45, 0x7a, 0xe0, 0x38, 0x09,
-	0x9c, 0x78, 0x24, 0xc7, 0x78, 0xe1, 0x91, 0x1c, 0xe3, 0x83, 0x47, 0x72, 0x8c, 0x33, 0x1e, 0xcb,
-	0x31, 0x24, 0xb1, 0x81, 0xa3, 0xd0, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0x6a, 0xdb, 0xf5, 0x60,
-	0x4c, 0x02, 0x00, 0x00,
+	// 357 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x52, 0xcd, 0x6a, 0xea, 0x40,
+	0x14, 0xbe, 0xa3, 0xe0, 0xbd, 0x9e, 0x8b, 0x45, 0x46, 0x90, 0x10, 0x21, 0x58, 0x57, 0x42, 0xe1,
+	0x4c, 0x55, 0x70, 0xd1, 0x55, 0x29, 0xb4, 0x45, 0xfa, 0xb3, 0xd0, 0x7d, 0x61, 0x62, 0xc6, 0x18,
+	0xfc, 0x99, 0x21, 0x99, 0x58, 0xda, 0x5d, 0x17, 0x7d, 0x87, 0x3e, 0x52, 0x97, 0x7d, 0x84, 0x62,
+	0x5f, 0xa4, 0x98, 0x4c, 0x5a, 0x03, 0xed, 0x42, 0x97, 0xe7, 0xef, 0xfb, 0xbe, 0xf3, 0x9d, 0x03,
+	0x87, 0x13, 0x1e, 0xcc, 0xe3, 0x50, 0x44, 0x9a, 0xeb, 0x38, 0x62, 0xb9, 0x08, 0x55, 0x28, 0xb5,
+	0xa4, 0x47, 0x7e, 0xa0, 0xa7, 0xb1, 0x8b, 0x63, 0xb9, 0xc0, 0x68, 0x2e, 0x67, 0x18, 0x72, 0x7f,
+	0xc9, 0x43, 0x39, 0x43, 0x3f, 0x54, 0x63, 0xcc, 0x8d, 0xd8, 0xfd, 0xef, 0x66, 0xb6, 0x69, 0x66,
+	0x59, 0x33, 0xe3, 0x2a, 0x60, 0xe3, 0x29, 0x97, 0x11, 0x5b, 0x75, 0x98, 0x72, 0x33, 0xaa, 0x94,
+	0xc4, 0x6e, 0xf8, 0x52, 0xfa, 0x73, 0xc1, 0x92, 0xc8, 0x8d, 0x27, 0x4c, 0x2c, 0x94, 0x7e, 0x48,
+	0x8b, 0x2d, 0x0b, 0x4a, 0xb7, 0xd2, 0x13, 0x03, 0x8f, 0x1e, 0x40, 0x21, 0xf0, 0x2c, 0xd2, 0x24,
+	0xed, 0xf2, 0xb0, 0x10, 0x78, 0xad, 0x06, 0x94, 0x2f, 0x52, 0x9c, 0x1f, 0x8a, 0x77, 0x50, 0x31,
+	0xc5, 0x68, 0xa4, 0xb9, 0x16, 0xf4, 0x06, 0xfe, 0x65, 0x6a, 0x2d, 0xd2, 0x2c, 0xb6, 0xff, 0x77,
+	0x3b, 0xf8, 0xeb, 0x72, 0x5c, 0x05, 0x98, 0xe8, 0xc5, 0x55, 0x07, 0x95, 0x8b, 0x06, 0x6a, 0xf8,
+	0x05, 0xd1, 0x7a, 0x84, 0x9a, 0x49, 0x8e, 0x92, 0xe5, 0x87, 0x42, 0xc9, 0x50, 0xd3, 0x3a, 0x94,
+	0x96, 0x89, 0x5a, 0x23, 0xc5, 0x44, 0xf4, 0x0a, 0xfe, 0x9a, 0x51, 0xab, 0xd0, 0x24, 0xfb, 0x91,
+	0x67, 0x08, 0xdd, 0xa7, 0x22, 0x54, 0x72, 0xe4, 0xf4, 0x99, 0x40, 0x75, 0x2b, 0x23, 0xae, 0x83,
+	0x48, 0xd3, 0x1e, 0xee, 0x70, 0x3c, 0x4c, 0x4d, 0xb6, 0x4f, 0x76, 0x1a, 0xca, 0x59, 0x7c, 0x4c,
+	0xe8, 0x3d, 0xc0, 0xa5, 0xd0, 0x26, 0x4b, 0xfb, 0xfb, 0x60, 0x0d, 0x3c, 0x7b, 0x77, 0x6f, 0xa8,
+	0x84, 0x5a, 0x7a, 0x81, 0xbc, 0x2f, 0xa7, 0xfb, 0x28, 0xd8, 0x3e, 0xa8, 0x5d, 0xc7, 0xf4, 0x39,
+	0x31, 0x7b, 0x4e, 0x3c, 0xdf, 0x3c, 0xe7, 0x59, 0xf5, 0x75, 0xed, 0x90, 0xb7, 0xb5, 0x43, 0xde,
+	0xd7, 0x0e, 0x79, 0xf9, 0x70, 0xfe, 0xb8, 0xa5, 0xa4, 0xa3, 0xf7, 0x39, 0x00, 0x7e, 0x67, 0x41,
+	0x1c, 0x56, 0x03, 0x00, 0x00,
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/slok/ragnarok/apimachinery/serializer"
 	"github.com/slok/ragnarok/clock"
+	pbac "github.com/slok/ragnarok/grpc/attackcatalog"
 	pbfs "github.com/slok/ragnarok/grpc/failurestatus"
 	pbns "github.com/slok/ragnarok/grpc/nodestatus"
 	"github.com/slok/ragnarok/log"
@@ -22,6 +23,7 @@ const failureStatusUpInterval = 15 * time.Second
 type GRPCServiceServer interface {
 	pbns.NodeStatusServer
 	pbfs.FailureStatusServer
+	pbac.AttackCatalogServer
 
 	// Serve will serve the services.
 	Serve(addr string) error
@@ -32,17 +34,19 @@ type GRPCServiceServer interface {
 type MasterGRPCServiceServer struct {
 	*grpcservice.NodeStatus
 	*grpcservice.FailureStatus
+	*grpcservice.AttackCatalog
 	server   *grpc.Server
 	listener net.Listener
 	logger   log.Logger
 }
 
 // NewMasterGRPCServiceServer returns a new grpc service server with a master as a base.
-func NewMasterGRPCServiceServer(fss service.FailureStatusService, nss service.NodeStatusService, listener net.Listener, clock clock.Clock, logger log.Logger) *MasterGRPCServiceServer {
+func NewMasterGRPCServiceServer(fss service.FailureStatusService, nss service.NodeStatusService, acs service.AttackCatalogService, listener net.Listener, clock clock.Clock, logger log.Logger) *MasterGRPCServiceServer {
 
 	// Create different grpc services.
 	gnss := grpcservice.NewNodeStatus(nss, serializer.PBSerializerDefault, logger)
 	gfss := grpcservice.NewFailureStatus(failureStatusUpInterval, serializer.PBSerializerDefault, fss, clock, logger)
+	gacs := grpcservice.NewAttackCatalog(acs, serializer.PBSerializerDefault, logger)
 
 	// TODO: Authentication.
 	// Create the GRPC server.
@@ -50,6 +54,7 @@ func NewMasterGRPCServiceServer(fss service.FailureStatusService, nss service.No
 	m := &MasterGRPCServiceServer{
 		NodeStatus:    gnss, // Node status service.
 		FailureStatus: gfss, // Failure status service.
+		AttackCatalog: gacs, // Attack catalog service.
 
 		server:   grpcServer,
 		logger:   logger,
@@ -69,6 +74,9 @@ func (m *MasterGRPCServiceServer) registerServices() {
 
 	// Register failure status service.
 	pbfs.RegisterFailureStatusServer(m.server, m)
+
+	// Register attack catalog service.
+	pbac.RegisterAttackCatalogServer(m.server, m)
 }
 
 // Serve implements the GRPCServiceServiceServer interface.
@@ -15,6 +15,7 @@ import (
 	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
 	chaosv1pb "github.com/slok/ragnarok/api/chaos/v1/pb"
 	clusterv1 "github.com/slok/ragnarok/api/cluster/v1"
+	"github.com/slok/ragnarok/apimachinery/serializer"
 	"github.com/slok/ragnarok/clock"
 	pbfs "github.com/slok/ragnarok/grpc/failurestatus"
 	"github.com/slok/ragnarok/log"
@@ -47,13 +48,13 @@ func TestMasterGRPCServiceServerRegisterNode(t *testing.T) {
 		if test.shouldErr {
 			expErr = errors.New("wanted error")
 		}
-		mnss.On("Register", test.id, test.labels).Once().Return(expErr)
+		mnss.On("Register", test.id, test.labels, []string(nil)).Once().Return(expErr)
 
 		// Create our server.
 		l, err := net.Listen("tcp", "127.0.0.1:0") // :0 for a random port.
 		require.NoError(err)
 		defer l.Close()
-		s := server.NewMasterGRPCServiceServer(mfss, mnss, l, clock.Base(), log.Dummy)
+		s := server.NewMasterGRPCServiceServer(mfss, mnss, &mservice.AttackCatalogService{}, l, clock.Base(), log.Dummy)
 		// Serve in background.
 		go func() {
 			s.Serve()
@@ -110,7 +111,7 @@ func TestMasterGRPCServiceServerNodeHeartbeat(t *testing.T) {
 		l, err := net.Listen("tcp", "127.0.0.1:0") // :0 for a random port.
 		require.NoError(err)
 		defer l.Close()
-		s := server.NewMasterGRPCServiceServer(mfss, mnss, l, clock.Base(), log.Dummy)
+		s := server.NewMasterGRPCServiceServer(mfss, mnss, &mservice.AttackCatalogService{}, l, clock.Base(), log.Dummy)
 		// Serve in background.
 		go func() {
 			s.Serve()
@@ -299,7 +300,7 @@ func TestMasterGRPCServiceServerFailureStateList(t *testing.T) {
 			defer l.Close()
 
 			// Create our server
-			s := server.NewMasterGRPCServiceServer(mfss, mnss, l, mclk, log.Dummy)
+			s := server.NewMasterGRPCServiceServer(mfss, mnss, &mservice.AttackCatalogService{}, l, mclk, log.Dummy)
 
 			// Serve in background.
 			go func() {
@@ -396,7 +397,7 @@ func TestMasterGRPCServiceServerGetFailure(t *testing.T) {
 			defer l.Close()
 
 			// Create our server
-			s := server.NewMasterGRPCServiceServer(mfss, mnss, l, clock.Base(), log.Dummy)
+			s := server.NewMasterGRPCServiceServer(mfss, mnss, &mservice.AttackCatalogService{}, l, clock.Base(), log.Dummy)
 
 			// Serve in background.
 			go func() {
@@ -424,3 +425,47 @@ func TestMasterGRPCServiceServerGetFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestMasterGRPCServiceServerAttackCatalogList(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	at := chaosv1.NewAttack()
+	at.Metadata = api.ObjectMeta{ID: "attack1"}
+	at.Spec.Description = "first attack"
+	expList := chaosv1.NewAttackList([]*chaosv1.Attack{&at}, "")
+
+	// Mocks.
+	mnss := &mservice.NodeStatusService{}
+	mfss := &mservice.FailureStatusService{}
+	macs := &mservice.AttackCatalogService{}
+	macs.On("List").Once().Return(&expList, nil)
+
+	// Create our server.
+	l, err := net.Listen("tcp", "127.0.0.1:0") // :0 for a random port.
+	require.NoError(err)
+	defer l.Close()
+	s := server.NewMasterGRPCServiceServer(mfss, mnss, macs, l, clock.Base(), log.Dummy)
+
+	// Serve in background.
+	go func() {
+		s.Serve()
+	}()
+
+	// Create our client.
+	testCli, err := tgrpc.NewTestClient(l.Addr().String())
+	require.NoError(err)
+	defer testCli.Close()
+
+	// Make the call.
+	pbl, err := testCli.AttackCatalogList(context.Background())
+
+	// Check.
+	if assert.NoError(err) {
+		l, err := serializer.PBSerializerDefault.Decode(pbl)
+		if assert.NoError(err) {
+			assert.Equal(&expList, l)
+		}
+	}
+	macs.AssertExpectations(t)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+)
+
+// AttackCatalogService is how the master exposes the attacks that can be used on the failures.
+type AttackCatalogService interface {
+	// List returns all the attacks of the catalog.
+	List() (*chaosv1.AttackList, error)
+}
+
+// AttackCatalog is the implementation of attack catalog service.
+type AttackCatalog struct {
+	reg    attack.Registry // reg is the registry of the attacks.
+	logger log.Logger
+}
+
+// NewAttackCatalog returns a new AttackCatalog.
+func NewAttackCatalog(reg attack.Registry, logger log.Logger) *AttackCatalog {
+	return &AttackCatalog{
+		reg:    reg,
+		logger: logger,
+	}
+}
+
+// List implements AttackCatalogService interface.
+func (a *AttackCatalog) List() (*chaosv1.AttackList, error) {
+	infos := a.reg.List()
+	as := make([]*chaosv1.Attack, len(infos))
+	for i, info := range infos {
+		at := chaosv1.NewAttackFromInfo(info)
+		as[i] = &at
+	}
+	l := chaosv1.NewAttackList(as, "")
+	return &l, nil
+}
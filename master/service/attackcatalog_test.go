@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/ragnarok/api"
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/log"
+	"github.com/slok/ragnarok/master/service"
+	mattack "github.com/slok/ragnarok/mocks/attack"
+)
+
+func TestAttackCatalogList(t *testing.T) {
+	tests := []struct {
+		name       string
+		infos      []attack.Info
+		expAttacks []*chaosv1.Attack
+	}{
+		{
+			name:       "Listing an empty catalog should return an empty list.",
+			infos:      []attack.Info{},
+			expAttacks: []*chaosv1.Attack{},
+		},
+		{
+			name: "Listing the catalog should return all the registered attacks.",
+			infos: []attack.Info{
+				{ID: "attack1", Description: "first attack", Schema: attack.Schema{{Name: "size", Type: attack.SizeOptType}}},
+				{ID: "attack2"},
+			},
+			expAttacks: []*chaosv1.Attack{
+				&chaosv1.Attack{
+					TypeMeta: chaosv1.AttackTypeMeta,
+					Metadata: api.ObjectMeta{ID: "attack1"},
+					Spec: chaosv1.AttackSpec{
+						Description: "first attack",
+						Options:     attack.Schema{{Name: "size", Type: attack.SizeOptType}},
+					},
+				},
+				&chaosv1.Attack{
+					TypeMeta: chaosv1.AttackTypeMeta,
+					Metadata: api.ObjectMeta{ID: "attack2"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			// Mocks.
+			mreg := &mattack.Registry{}
+			mreg.On("List").Once().Return(test.infos)
+
+			ac := service.NewAttackCatalog(mreg, log.Dummy)
+			l, err := ac.List()
+			if assert.NoError(err) {
+				assert.Equal(chaosv1.AttackListTypeMeta, l.TypeMeta)
+				assert.Equal(test.expAttacks, l.Items)
+				mreg.AssertExpectations(t)
+			}
+		})
+	}
+}
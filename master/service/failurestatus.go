@@ -1,6 +1,9 @@
 package service
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/slok/ragnarok/api"
 	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
 	clichaosv1 "github.com/slok/ragnarok/client/api/chaos/v1"
@@ -17,12 +20,16 @@ type FailureStatusService interface {
 	GetNodeExpectedDisabledFailures(nodeID string) []*chaosv1.Failure
 	// GetFailure returns an specific failure.
 	GetFailure(id string) (*chaosv1.Failure, error)
+	// ReportFailureStatus updates the status of a failure with the status reported by a node.
+	ReportFailureStatus(nodeID string, failure *chaosv1.Failure) error
 }
 
 // FailureStatus is the implementation of failure status service.
 type FailureStatus struct {
 	client clichaosv1.FailureClientInterface // client is the client to manage failure objects.
 	logger log.Logger
+
+	reportLock sync.Mutex // reportLock serializes the status updates of the reports.
 }
 
 // NewFailureStatus returns a new FailureStatus
@@ -100,3 +107,30 @@ func (f *FailureStatus) GetFailure(id string) (*chaosv1.Failure, error) {
 	}
 	return flr, nil
 }
+
+// ReportFailureStatus implements FailureStatusService interface. Only the status set
+// by the node is updated, the expected state is managed by the master.
+func (f *FailureStatus) ReportFailureStatus(nodeID string, failure *chaosv1.Failure) error {
+	f.reportLock.Lock()
+	defer f.reportLock.Unlock()
+
+	flr, err := f.client.Get(failure.Metadata.ID)
+	if err != nil {
+		return err
+	}
+
+	if flr.Metadata.Labels[api.LabelNode] != nodeID {
+		return fmt.Errorf("failure %s doesn't belong to node %s", flr.Metadata.ID, nodeID)
+	}
+
+	flr.Status.CurrentState = failure.Status.CurrentState
+	flr.Status.Executed = failure.Status.Executed
+	flr.Status.Finished = failure.Status.Finished
+	flr.Status.Message = failure.Status.Message
+
+	if _, err := f.client.Update(flr); err != nil {
+		return err
+	}
+	f.logger.WithField("failure", flr.Metadata.ID).Debugf("failure state reported by node %s: %s", nodeID, flr.Status.CurrentState)
+	return nil
+}
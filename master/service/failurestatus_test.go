@@ -2,7 +2,9 @@ package service_test
 
 import (
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -271,3 +273,145 @@ func TestGetFailure(t *testing.T) {
 		}
 	}
 }
+
+func TestReportFailureStatus(t *testing.T) {
+	now := time.Now()
+	stored := func() *v1.Failure {
+		return &v1.Failure{
+			Metadata: api.ObjectMeta{
+				ID:     "test1",
+				Labels: map[string]string{api.LabelNode: "node1"},
+			},
+			Status: v1.FailureStatus{
+				CurrentState:  v1.EnabledFailureState,
+				ExpectedState: v1.EnabledFailureState,
+				Creation:      now,
+			},
+		}
+	}
+	reported := &v1.Failure{
+		Metadata: api.ObjectMeta{ID: "test1"},
+		Status: v1.FailureStatus{
+			CurrentState:  v1.ErroredFailureState,
+			ExpectedState: v1.DisabledFailureState,
+			Executed:      now.Add(time.Second),
+			Finished:      now.Add(time.Minute),
+			Message:       "error applying failure",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		nodeID    string
+		getErr    bool
+		updateErr bool
+		expUpdate bool
+		expErr    bool
+	}{
+		{
+			name:      "Reporting the status of a node failure should update the node status of the failure.",
+			nodeID:    "node1",
+			expUpdate: true,
+		},
+		{
+			name:   "Reporting the status of a failure from other node should error.",
+			nodeID: "node2",
+			expErr: true,
+		},
+		{
+			name:   "Reporting the status of a missing failure should error.",
+			nodeID: "node1",
+			getErr: true,
+			expErr: true,
+		},
+		{
+			name:      "Reporting the status when the failure update fails should error.",
+			nodeID:    "node1",
+			updateErr: true,
+			expUpdate: true,
+			expErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var getErr, updateErr error
+			if test.getErr {
+				getErr = errors.New("wanted error")
+			}
+			if test.updateErr {
+				updateErr = errors.New("wanted error")
+			}
+
+			// The expected state and the creation are managed by the master.
+			expFailure := stored()
+			expFailure.Status.CurrentState = reported.Status.CurrentState
+			expFailure.Status.Executed = reported.Status.Executed
+			expFailure.Status.Finished = reported.Status.Finished
+			expFailure.Status.Message = reported.Status.Message
+
+			// Create mocks.
+			mcli := &mclichaosv1.FailureClientInterface{}
+			mcli.On("Get", "test1").Once().Return(stored(), getErr)
+			if test.expUpdate {
+				mcli.On("Update", expFailure).Once().Return(expFailure, updateErr)
+			}
+
+			// Create the service.
+			fss := service.NewFailureStatus(mcli, log.Dummy)
+
+			err := fss.ReportFailureStatus(test.nodeID, reported)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			mcli.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReportFailureStatusConcurrently(t *testing.T) {
+	assert := assert.New(t)
+
+	// Count the reports between getting and updating the failure.
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	mcli := &mclichaosv1.FailureClientInterface{}
+	mcli.On("Get", "test1").Return(func(string) *v1.Failure {
+		return &v1.Failure{
+			Metadata: api.ObjectMeta{
+				ID:     "test1",
+				Labels: map[string]string{api.LabelNode: "node1"},
+			},
+		}
+	}, nil).Run(func(mock.Arguments) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+	mcli.On("Update", mock.Anything).Return(nil, nil).Run(func(mock.Arguments) {
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	})
+
+	fss := service.NewFailureStatus(mcli, log.Dummy)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(fss.ReportFailureStatus("node1", &v1.Failure{Metadata: api.ObjectMeta{ID: "test1"}}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(1, maxInFlight, "the reports should be serialized")
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	emptypb "github.com/golang/protobuf/ptypes/empty"
+	"golang.org/x/net/context"
+
+	chaosv1pb "github.com/slok/ragnarok/api/chaos/v1/pb"
+	"github.com/slok/ragnarok/apimachinery/serializer"
+	"github.com/slok/ragnarok/log"
+	"github.com/slok/ragnarok/master/service"
+)
+
+// AttackCatalog implements the required methods for the AttackCatalog GRPC service.
+type AttackCatalog struct {
+	service    service.AttackCatalogService // The service that has the real logic.
+	serializer serializer.Serializer
+	logger     log.Logger
+}
+
+// NewAttackCatalog returns a new AttackCatalog.
+func NewAttackCatalog(service service.AttackCatalogService, serializer serializer.Serializer, logger log.Logger) *AttackCatalog {
+	return &AttackCatalog{
+		service:    service,
+		serializer: serializer,
+		logger:     logger,
+	}
+}
+
+// List returns all the attacks of the catalog.
+func (a *AttackCatalog) List(ctx context.Context, _ *emptypb.Empty) (*chaosv1pb.AttackList, error) {
+	a.logger.Debugf("attack catalog list GRPC call received")
+	// Check context already cancelled.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	l, err := a.service.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pbl := &chaosv1pb.AttackList{}
+	if err := a.serializer.Encode(l, pbl); err != nil {
+		return nil, err
+	}
+	return pbl, nil
+}
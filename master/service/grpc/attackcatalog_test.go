@@ -0,0 +1,76 @@
+package grpc_test
+
+import (
+	"errors"
+	"testing"
+
+	emptypb "github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/slok/ragnarok/api"
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/apimachinery/serializer"
+	"github.com/slok/ragnarok/log"
+	"github.com/slok/ragnarok/master/service/grpc"
+	mservice "github.com/slok/ragnarok/mocks/master/service"
+)
+
+func TestAttackCatalogGRPCList(t *testing.T) {
+	at := chaosv1.NewAttack()
+	at.Metadata = api.ObjectMeta{ID: "attack1"}
+	at.Spec.Description = "first attack"
+	l := chaosv1.NewAttackList([]*chaosv1.Attack{&at}, "")
+
+	tests := []struct {
+		name    string
+		list    *chaosv1.AttackList
+		listErr error
+		expErr  bool
+	}{
+		{
+			name: "Listing the catalog should return the attacks.",
+			list: &l,
+		},
+		{
+			name:    "Listing the catalog with an error on the service should return an error.",
+			listErr: errors.New("wanted error"),
+			expErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			// Create the mocks.
+			acs := &mservice.AttackCatalogService{}
+			acs.On("List").Once().Return(test.list, test.listErr)
+
+			ac := grpc.NewAttackCatalog(acs, serializer.PBSerializerDefault, log.Dummy)
+			pbl, err := ac.List(context.Background(), &emptypb.Empty{})
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				obj, err := serializer.PBSerializerDefault.Decode(pbl)
+				if assert.NoError(err) {
+					assert.Equal(test.list, obj)
+				}
+			}
+			acs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttackCatalogGRPCListCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	acs := &mservice.AttackCatalogService{}
+	ac := grpc.NewAttackCatalog(acs, serializer.PBSerializerDefault, log.Dummy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ac.List(ctx, &emptypb.Empty{})
+	assert.Error(err)
+	acs.AssertNotCalled(t, "List")
+}
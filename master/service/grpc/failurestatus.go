@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"time"
 
+	emptypb "github.com/golang/protobuf/ptypes/empty"
 	"golang.org/x/net/context"
 
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
 	chaosv1pb "github.com/slok/ragnarok/api/chaos/v1/pb"
 	"github.com/slok/ragnarok/apimachinery/serializer"
 	"github.com/slok/ragnarok/clock"
@@ -98,3 +100,31 @@ func (f *FailureStatus) GetFailure(ctx context.Context, fID *pbfs.FailureId) (*c
 
 	return res, nil
 }
+
+// ReportFailureStatus updates the status of a failure reported by a node.
+func (f *FailureStatus) ReportFailureStatus(ctx context.Context, report *pbfs.FailureStatusReport) (*emptypb.Empty, error) {
+	// Check context already cancelled.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if report.GetFailure() == nil {
+		return nil, fmt.Errorf("failure status report without failure")
+	}
+
+	obj, err := f.serializer.Decode(report.GetFailure())
+	if err != nil {
+		return nil, fmt.Errorf("could not make the call because of unmarshaling error on definition: %v", err)
+	}
+	flr, ok := obj.(*chaosv1.Failure)
+	if !ok {
+		return nil, fmt.Errorf("reported object is not a failure")
+	}
+
+	if err := f.service.ReportFailureStatus(report.GetNodeId(), flr); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -224,3 +224,91 @@ func TestFailureStatusGRPCFailureStateListErr(t *testing.T) {
 
 	close(tC)
 }
+
+func TestFailureStatusGRPCReportFailureStatus(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	stubF := &chaosv1.Failure{
+		Metadata: api.ObjectMeta{
+			ID: "test1",
+		},
+		Status: chaosv1.FailureStatus{
+			CurrentState: chaosv1.ErroredFailureState,
+			Executed:     now,
+			Message:      "wanted error",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		report     *pbfs.FailureStatusReport
+		cancelCtx  bool
+		serviceErr bool
+		expCall    bool
+		expErr     bool
+	}{
+		{
+			name:    "Reporting a failure status should report it to the service.",
+			report:  &pbfs.FailureStatusReport{NodeId: "node1", Failure: testpb.CreatePBFailure(stubF, t)},
+			expCall: true,
+		},
+		{
+			name:       "Reporting a failure status when the service fails should error.",
+			report:     &pbfs.FailureStatusReport{NodeId: "node1", Failure: testpb.CreatePBFailure(stubF, t)},
+			serviceErr: true,
+			expCall:    true,
+			expErr:     true,
+		},
+		{
+			name:   "Reporting without failure should error.",
+			report: &pbfs.FailureStatusReport{NodeId: "node1"},
+			expErr: true,
+		},
+		{
+			name:      "Reporting with a cancelled context should error.",
+			report:    &pbfs.FailureStatusReport{NodeId: "node1", Failure: testpb.CreatePBFailure(stubF, t)},
+			cancelCtx: true,
+			expErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var serviceErr error
+			if test.serviceErr {
+				serviceErr = errors.New("wanted error")
+			}
+
+			// Create mocks.
+			mfss := &mservice.FailureStatusService{}
+			if test.expCall {
+				flrMatcher := mock.MatchedBy(func(f *chaosv1.Failure) bool {
+					return f.Metadata.ID == stubF.Metadata.ID &&
+						f.Status.CurrentState == stubF.Status.CurrentState &&
+						f.Status.Executed.Equal(stubF.Status.Executed) &&
+						f.Status.Message == stubF.Status.Message
+				})
+				mfss.On("ReportFailureStatus", "node1", flrMatcher).Once().Return(serviceErr)
+			}
+
+			// Create the GRPC service.
+			fs := grpc.NewFailureStatus(0, serializer.PBSerializerDefault, mfss, clock.Base(), log.Dummy)
+
+			ctx, cncl := context.WithCancel(context.Background())
+			defer cncl()
+			if test.cancelCtx {
+				cncl()
+			}
+
+			// Report and check.
+			_, err := fs.ReportFailureStatus(ctx, test.report)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			mfss.AssertExpectations(t)
+		})
+	}
+}
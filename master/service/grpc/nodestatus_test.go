@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/net/context"
 
+	"github.com/slok/ragnarok/api"
 	clusterv1 "github.com/slok/ragnarok/api/cluster/v1"
 	clusterv1pb "github.com/slok/ragnarok/api/cluster/v1/pb"
 	"github.com/slok/ragnarok/apimachinery/serializer"
@@ -28,10 +29,14 @@ func TestNodeStatusGRPCRegisterOK(t *testing.T) {
 	ns := grpc.NewNodeStatus(nss, serializer.PBSerializerDefault, log.Dummy)
 	id := "test1"
 	labels := map[string]string{"key1": "value1"}
-	n := testpb.CreateLabelsPBNode(id, labels, t)
+	attacks := []string{"attack1", "attack2"}
+	n := testpb.CreatePBNode(&clusterv1.Node{
+		Metadata: api.ObjectMeta{ID: id, Labels: labels},
+		Spec:     clusterv1.NodeSpec{Attacks: attacks},
+	}, t)
 
 	// Mock service calls on master.
-	nss.On("Register", id, labels).Once().Return(nil)
+	nss.On("Register", id, labels, attacks).Once().Return(nil)
 
 	// Call and check.
 	_, err := ns.Register(context.Background(), n)
@@ -53,7 +58,7 @@ func TestNodeStatusGRPCRegisterError(t *testing.T) {
 	n := testpb.CreateLabelsPBNode(id, labels, t)
 
 	// Mock service calls on master.
-	nss.On("Register", id, labels).Once().Return(errors.New("wanted error"))
+	nss.On("Register", id, labels, []string(nil)).Once().Return(errors.New("wanted error"))
 
 	// Call and check.
 	_, err := ns.Register(context.Background(), n)
@@ -13,8 +13,8 @@ import (
 
 // NodeStatusService is how the master manages the status of the nodes.
 type NodeStatusService interface {
-	// Register registers a new node on the master.
-	Register(id string, labels map[string]string) error
+	// Register registers a new node on the master with the attacks it supports.
+	Register(id string, labels map[string]string, attacks []string) error
 
 	// Heartbeat sets the node state after its heartbeat.
 	Heartbeat(id string, state clusterv1.NodeState) error
@@ -37,8 +37,8 @@ func NewNodeStatus(_ config.Config, client cliclusterv1.NodeClientInterface, log
 }
 
 // Register implements NodeStatusService interface.
-func (f *NodeStatus) Register(id string, labels map[string]string) error {
-	f.logger.WithField("nodeID", id).Infof("node registered on master")
+func (f *NodeStatus) Register(id string, labels map[string]string, attacks []string) error {
+	f.logger.WithField("nodeID", id).WithField("attacks", attacks).Infof("node registered on master")
 	f.nodeLock.Lock()
 	defer f.nodeLock.Unlock()
 
@@ -47,6 +47,9 @@ func (f *NodeStatus) Register(id string, labels map[string]string) error {
 		ID:     id,
 		Labels: labels,
 	}
+	n.Spec = clusterv1.NodeSpec{
+		Attacks: attacks,
+	}
 	n.Status = clusterv1.NodeStatus{
 		State: clusterv1.UnknownNodeState,
 	}
@@ -27,6 +27,9 @@ func TestNodeStatusNodeRegistration(t *testing.T) {
 			ID:     "test1",
 			Labels: map[string]string{"address": "127.0.0.45"},
 		},
+		Spec: clusterv1.NodeSpec{
+			Attacks: []string{"attack1", "attack2"},
+		},
 		Status: clusterv1.NodeStatus{
 			State: clusterv1.UnknownNodeState,
 		},
@@ -41,7 +44,7 @@ func TestNodeStatusNodeRegistration(t *testing.T) {
 	require.NotNil(ns)
 
 	// Check our registered node.
-	err := ns.Register(n.Metadata.ID, n.Metadata.Labels)
+	err := ns.Register(n.Metadata.ID, n.Metadata.Labels, n.Spec.Attacks)
 	if assert.NoError(err) {
 		mcli.AssertExpectations(t)
 	}
@@ -71,7 +74,7 @@ func TestNodeStatusNodeRegistrationError(t *testing.T) {
 	require.NotNil(ns)
 
 	// Check our registered node.
-	err := ns.Register(n.Metadata.ID, n.Metadata.Labels)
+	err := ns.Register(n.Metadata.ID, n.Metadata.Labels, n.Spec.Attacks)
 	if assert.Error(err) {
 		mcli.AssertExpectations(t)
 	}
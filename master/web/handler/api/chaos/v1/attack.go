@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/slok/ragnarok/apimachinery/serializer"
+	"github.com/slok/ragnarok/master/service"
+	"github.com/slok/ragnarok/master/web/handler/util"
+)
+
+// attackRoute is the route of the attack catalog.
+const attackRoute = "/api/chaos/v1/attacks"
+
+// AttackHandler is the handler that handlers the Attack catalog resources.
+type AttackHandler struct {
+	serializer serializer.Serializer
+	catalog    service.AttackCatalogService
+}
+
+// NewAttackHandler returns a new AttackHandler.
+func NewAttackHandler(serializer serializer.Serializer, catalog service.AttackCatalogService) *AttackHandler {
+	return &AttackHandler{
+		serializer: serializer,
+		catalog:    catalog,
+	}
+}
+
+// Create creates an attack, the catalog is read only.
+func (a *AttackHandler) Create(w http.ResponseWriter, r *http.Request) {
+	util.SetJSONNotImplementedError(w)
+}
+
+// Update updates an attack, the catalog is read only.
+func (a *AttackHandler) Update(w http.ResponseWriter, r *http.Request, id string) {
+	util.SetJSONNotImplementedError(w)
+}
+
+// Delete deletes an attack, the catalog is read only.
+func (a *AttackHandler) Delete(w http.ResponseWriter, r *http.Request, id string) {
+	util.SetJSONNotImplementedError(w)
+}
+
+// Get gets an attack.
+func (a *AttackHandler) Get(w http.ResponseWriter, r *http.Request, id string) {
+	util.SetJSONNotImplementedError(w)
+}
+
+// List lists all the attacks of the catalog.
+func (a *AttackHandler) List(w http.ResponseWriter, r *http.Request, opts map[string]string) {
+	l, err := a.catalog.List()
+	if err != nil {
+		util.SetJSONInternalError(w, err.Error())
+		return
+	}
+
+	var b bytes.Buffer
+	if err := a.serializer.Encode(l, &b); err != nil {
+		util.SetJSONInternalError(w, err.Error())
+		return
+	}
+	util.SetJSONOK(w, b.Bytes())
+}
+
+// Watch watches attacks.
+func (a *AttackHandler) Watch(w http.ResponseWriter, r *http.Request, opts map[string]string) {
+	util.SetJSONNotImplementedError(w)
+}
+
+// GetRoute returns the route where the handlers of attacks will listen.
+func (a *AttackHandler) GetRoute() string {
+	return attackRoute
+}
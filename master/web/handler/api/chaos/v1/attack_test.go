@@ -0,0 +1,87 @@
+package v1_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/ragnarok/api"
+	chaosv1 "github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/apimachinery/serializer"
+	"github.com/slok/ragnarok/attack"
+	webapichaosv1 "github.com/slok/ragnarok/master/web/handler/api/chaos/v1"
+	mservice "github.com/slok/ragnarok/mocks/master/service"
+)
+
+func TestAttackHandlerList(t *testing.T) {
+	at := chaosv1.NewAttack()
+	at.Metadata = api.ObjectMeta{ID: "attack1"}
+	at.Spec = chaosv1.AttackSpec{
+		Description: "first attack",
+		Options: attack.Schema{
+			{Name: "size", Type: attack.SizeOptType, Required: true, Description: "The size."},
+		},
+	}
+	l := chaosv1.NewAttackList([]*chaosv1.Attack{&at}, "")
+
+	tests := []struct {
+		name    string
+		list    *chaosv1.AttackList
+		listErr bool
+		expCode int
+		expBody string
+	}{
+		{
+			name:    "Request to list the attacks should return the catalog.",
+			list:    &l,
+			expCode: 200,
+			expBody: `{"kind":"attackList","version":"chaos/v1","listMetadata":{},"items":[{"kind":"attack","version":"chaos/v1","metadata":{"id":"attack1"},"spec":{"description":"first attack","options":[{"name":"size","type":"size","required":true,"description":"The size."}]}}]}`,
+		},
+		{
+			name:    "Request to list the attacks should return an error if the catalog errors.",
+			listErr: true,
+			expCode: 500,
+			expBody: `{"error":"error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var listErr error
+			if test.listErr {
+				listErr = errors.New("error")
+			}
+
+			// Mocks.
+			macs := &mservice.AttackCatalogService{}
+			macs.On("List").Once().Return(test.list, listErr)
+
+			ah := webapichaosv1.NewAttackHandler(serializer.DefaultSerializer, macs)
+			r := httptest.NewRequest("GET", "http://test/api/chaos/v1/attacks", nil)
+			w := httptest.NewRecorder()
+
+			ah.List(w, r, map[string]string{})
+			assert.Equal(test.expCode, w.Code)
+			assert.Equal(test.expBody, strings.TrimSuffix(w.Body.String(), "\n"))
+			macs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttackHandlerReadOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	ah := webapichaosv1.NewAttackHandler(serializer.DefaultSerializer, &mservice.AttackCatalogService{})
+	r := httptest.NewRequest("POST", "http://test/api/chaos/v1/attacks", nil)
+	w := httptest.NewRecorder()
+
+	ah.Create(w, r)
+	assert.Equal(500, w.Code)
+	assert.Equal(`{"error":"not implemented"}`, strings.TrimSuffix(w.Body.String(), "\n"))
+	assert.Equal("/api/chaos/v1/attacks", ah.GetRoute())
+}
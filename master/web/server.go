@@ -9,7 +9,9 @@ import (
 	"github.com/slok/ragnarok/apimachinery/serializer"
 	cliclusterv1 "github.com/slok/ragnarok/client/api/cluster/v1"
 	"github.com/slok/ragnarok/log"
+	"github.com/slok/ragnarok/master/service"
 	"github.com/slok/ragnarok/master/web/handler"
+	chaosv1 "github.com/slok/ragnarok/master/web/handler/api/chaos/v1"
 	clusterv1 "github.com/slok/ragnarok/master/web/handler/api/cluster/v1"
 )
 
@@ -35,6 +37,7 @@ type HTTPServer struct {
 func NewDefaultHTTPServer(
 	serializer serializer.Serializer,
 	nodeCli cliclusterv1.NodeClientInterface,
+	attackCatalog service.AttackCatalogService,
 	listener net.Listener,
 	logger log.Logger) (*HTTPServer, error) {
 
@@ -45,6 +48,10 @@ func NewDefaultHTTPServer(
 	if err := server.HandleResource(nodeh); err != nil {
 		return nil, err
 	}
+	attackh := chaosv1.NewAttackHandler(serializer, attackCatalog)
+	if err := server.HandleResource(attackh); err != nil {
+		return nil, err
+	}
 
 	return server, nil
 }
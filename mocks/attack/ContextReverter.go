@@ -0,0 +1,24 @@
+// Code generated by mockery v1.0.0
+package attack
+
+import context "context"
+import mock "github.com/stretchr/testify/mock"
+
+// ContextReverter is an autogenerated mock type for the ContextReverter type
+type ContextReverter struct {
+	mock.Mock
+}
+
+// RevertContext provides a mock function with given fields: ctx
+func (_m *ContextReverter) RevertContext(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,40 @@
+// Code generated by mockery v1.0.0
+package attack
+
+import attack "github.com/slok/ragnarok/attack"
+import mock "github.com/stretchr/testify/mock"
+
+// Recoverer is an autogenerated mock type for the Recoverer type
+type Recoverer struct {
+	mock.Mock
+}
+
+// Recover provides a mock function with given fields: state
+func (_m *Recoverer) Recover(state attack.Opts) error {
+	ret := _m.Called(state)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(attack.Opts) error); ok {
+		r0 = rf(state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecoveryState provides a mock function with given fields:
+func (_m *Recoverer) RecoveryState() attack.Opts {
+	ret := _m.Called()
+
+	var r0 attack.Opts
+	if rf, ok := ret.Get(0).(func() attack.Opts); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(attack.Opts)
+		}
+	}
+
+	return r0
+}
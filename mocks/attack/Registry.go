@@ -37,6 +37,22 @@ func (_m *Registry) Exists(id string) bool {
 	return r0
 }
 
+// List provides a mock function with given fields:
+func (_m *Registry) List() []attack.Info {
+	ret := _m.Called()
+
+	var r0 []attack.Info
+	if rf, ok := ret.Get(0).(func() []attack.Info); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]attack.Info)
+		}
+	}
+
+	return r0
+}
+
 // New provides a mock function with given fields: id, opts
 func (_m *Registry) New(id string, opts attack.Opts) (attack.Attacker, error) {
 	ret := _m.Called(id, opts)
@@ -73,3 +89,17 @@ func (_m *Registry) Register(id string, c attack.Creater) error {
 
 	return r0
 }
+
+// Validate provides a mock function with given fields: id, opts
+func (_m *Registry) Validate(id string, opts attack.Opts) error {
+	ret := _m.Called(id, opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, attack.Opts) error); ok {
+		r0 = rf(id, opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -8,6 +8,9 @@ package mocks // import "github.com/slok/ragnarok/mocks"
 //go:generate mockery -output ./attack -outpkg attack -dir ../attack -name Registry
 //go:generate mockery -output ./attack -outpkg attack -dir ../attack -name Creater
 //go:generate mockery -output ./attack -outpkg attack -dir ../attack -name Attacker
+//go:generate mockery -output ./attack -outpkg attack -dir ../attack -name Verifier
+//go:generate mockery -output ./attack -outpkg attack -dir ../attack -name Recoverer
+//go:generate mockery -output ./attack -outpkg attack -dir ../attack -name ContextReverter
 
 // Clock mocks
 //go:generate mockery -output ./clock -outpkg clock -dir ../clock -name Clock
@@ -25,6 +28,7 @@ package mocks // import "github.com/slok/ragnarok/mocks"
 // Services mocks
 //go:generate mockery -output ./master/service -outpkg service -dir ../master/service -name NodeStatusService
 //go:generate mockery -output ./master/service -outpkg service -dir ../master/service -name FailureStatusService
+//go:generate mockery -output ./master/service -outpkg service -dir ../master/service -name AttackCatalogService
 
 // GRPC proto clients
 //go:generate mockery -output ./grpc/nodestatus -outpkg nodestatus -dir ../grpc/nodestatus -name NodeStatusClient
@@ -2,6 +2,7 @@
 package failurestatus
 
 import context "golang.org/x/net/context"
+import empty "github.com/golang/protobuf/ptypes/empty"
 import github_com_slok_ragnarok_grpc_failurestatus "github.com/slok/ragnarok/grpc/failurestatus"
 import grpc "google.golang.org/grpc"
 import mock "github.com/stretchr/testify/mock"
@@ -71,3 +72,33 @@ func (_m *FailureStatusClient) GetFailure(ctx context.Context, in *github_com_sl
 
 	return r0, r1
 }
+
+// ReportFailureStatus provides a mock function with given fields: ctx, in, opts
+func (_m *FailureStatusClient) ReportFailureStatus(ctx context.Context, in *github_com_slok_ragnarok_grpc_failurestatus.FailureStatusReport, opts ...grpc.CallOption) (*empty.Empty, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *empty.Empty
+	if rf, ok := ret.Get(0).(func(context.Context, *github_com_slok_ragnarok_grpc_failurestatus.FailureStatusReport, ...grpc.CallOption) *empty.Empty); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*empty.Empty)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *github_com_slok_ragnarok_grpc_failurestatus.FailureStatusReport, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
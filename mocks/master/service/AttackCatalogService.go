@@ -0,0 +1,34 @@
+// Code generated by mockery v1.0.0
+package service
+
+import mock "github.com/stretchr/testify/mock"
+
+import v1 "github.com/slok/ragnarok/api/chaos/v1"
+
+// AttackCatalogService is an autogenerated mock type for the AttackCatalogService type
+type AttackCatalogService struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields:
+func (_m *AttackCatalogService) List() (*v1.AttackList, error) {
+	ret := _m.Called()
+
+	var r0 *v1.AttackList
+	if rf, ok := ret.Get(0).(func() *v1.AttackList); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.AttackList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -24,13 +24,13 @@ func (_m *NodeStatusService) Heartbeat(id string, state v1.NodeState) error {
 	return r0
 }
 
-// Register provides a mock function with given fields: id, labels
-func (_m *NodeStatusService) Register(id string, labels map[string]string) error {
-	ret := _m.Called(id, labels)
+// Register provides a mock function with given fields: id, labels, attacks
+func (_m *NodeStatusService) Register(id string, labels map[string]string, attacks []string) error {
+	ret := _m.Called(id, labels, attacks)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, map[string]string) error); ok {
-		r0 = rf(id, labels)
+	if rf, ok := ret.Get(0).(func(string, map[string]string, []string) error); ok {
+		r0 = rf(id, labels, attacks)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -46,3 +46,17 @@ func (_m *Failure) ProcessFailureStateStreaming(nodeID string, handler client.Fa
 
 	return r0
 }
+
+// ReportFailureStatus provides a mock function with given fields: nodeID, failure
+func (_m *Failure) ReportFailureStatus(nodeID string, failure *v1.Failure) error {
+	ret := _m.Called(nodeID, failure)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *v1.Failure) error); ok {
+		r0 = rf(nodeID, failure)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
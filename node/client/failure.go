@@ -32,6 +32,8 @@ type Failure interface {
 	// ProcessFailureStateStreaming will make a request and start reading the stream from the GRPC to handle the states.
 	// It receives a handler that will be executed on every status. also receives a stop channel that will cancel the stream processing.
 	ProcessFailureStateStreaming(nodeID string, handler FailureStateHandler, stopCh <-chan struct{}) error
+	// ReportFailureStatus reports the status of a failure of the node to the master.
+	ReportFailureStatus(nodeID string, failure *chaosv1.Failure) error
 }
 
 // FailureGRPC staisfies Failure interface with GRPC communication.
@@ -167,3 +169,21 @@ func (f *FailureGRPC) ProcessFailureStateStreaming(nodeID string, handler Failur
 	}()
 	return nil
 }
+
+// ReportFailureStatus satisfies Failure interface.
+func (f *FailureGRPC) ReportFailureStatus(nodeID string, failure *chaosv1.Failure) error {
+	logger := f.logger.WithField("call", "report-failure-status").WithField("failureID", failure.Metadata.ID)
+	logger.Debug("making GRPC service call")
+
+	pbfl := &chaosv1pb.Failure{}
+	if err := f.serializer.Encode(failure, pbfl); err != nil {
+		return fmt.Errorf("could not convert failure to protobuf failure: %v", err)
+	}
+
+	// Make the call.
+	report := &pbfs.FailureStatusReport{NodeId: nodeID, Failure: pbfl}
+	if _, err := f.c.ReportFailureStatus(context.Background(), report); err != nil {
+		return err
+	}
+	return nil
+}
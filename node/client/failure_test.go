@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	emptypb "github.com/golang/protobuf/ptypes/empty"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -293,3 +294,58 @@ func TestFailureStateListStreamingOKWithStop(t *testing.T) {
 		})
 	}
 }
+
+func TestReportFailureStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		expRPCErr bool // Expect GRPC call error.
+	}{
+		{
+			name:      "Report a failure status correctly",
+			expRPCErr: false,
+		},
+		{
+			name:      "RPC call failed",
+			expRPCErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var rpcErr error
+			if test.expRPCErr {
+				rpcErr = errors.New("wanted failure")
+			}
+
+			flr := &chaosv1.Failure{
+				Metadata: api.ObjectMeta{ID: "test1"},
+				Status: chaosv1.FailureStatus{
+					CurrentState: chaosv1.ExecutingFailureState,
+				},
+			}
+
+			// Create mocks.
+			expReport := &pbfs.FailureStatusReport{
+				NodeId:  "node1",
+				Failure: testpb.CreatePBFailure(flr, t),
+			}
+			mc := &mpbfs.FailureStatusClient{}
+			mc.On("ReportFailureStatus", mock.Anything, expReport).Once().Return(&emptypb.Empty{}, rpcErr)
+
+			// Create the service
+			c, err := client.NewFailureGRPC(mc, serializer.PBSerializerDefault, clock.Base(), log.Dummy)
+			require.NoError(err)
+
+			// Make the call and check.
+			err = c.ReportFailureStatus("node1", flr)
+			if test.expRPCErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			mc.AssertExpectations(t)
+		})
+	}
+}
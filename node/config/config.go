@@ -15,6 +15,19 @@ type Config struct {
 	MasterAddress string
 	// HeartbeatInterval is the interval the node will send a heartbeat to the master
 	HeartbeatInterval time.Duration
+	// PluginDir is the directory with the attack plugin executables, empty disables the plugins.
+	PluginDir string
+	// JournalDir is the directory where the applied attacks are recorded so they can be
+	// reverted if the node dies, empty disables the journal.
+	JournalDir string
+	// ApplyTimeout is the maximum time an attack apply can take, 0 uses the default.
+	ApplyTimeout time.Duration
+	// RevertAttempts is the number of attempts of every attack revert, 0 uses the default.
+	RevertAttempts int
+	// RevertTimeout is the maximum time an attack revert attempt can take, 0 uses the default.
+	RevertTimeout time.Duration
+	// RevertJanitorInterval is the interval the failed reverts are retried, 0 uses the default.
+	RevertJanitorInterval time.Duration
 }
 
 
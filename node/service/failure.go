@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/slok/ragnarok/api/chaos/v1"
+	"github.com/slok/ragnarok/chaos/injection"
 	"github.com/slok/ragnarok/clock"
 	"github.com/slok/ragnarok/log"
 	"github.com/slok/ragnarok/node/client"
@@ -26,8 +27,8 @@ type FailureState interface {
 	StopHandling() error
 }
 
-// LogFailureState will process the failures received from the master and will only log them.
-type LogFailureState struct {
+// stateHandling handles the streaming of the failure states received from the master.
+type stateHandling struct {
 	nodeID  string
 	cli     client.Failure
 	stopC   chan struct{}
@@ -37,58 +38,363 @@ type LogFailureState struct {
 	stMu    sync.Mutex // stMu is the status running mutex.
 }
 
+// start starts streaming the failure states from the master to the handler.
+func (s *stateHandling) start(handler client.FailureStateHandler) error {
+	s.stMu.Lock()
+	defer s.stMu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("failure state handler already running")
+	}
+
+	s.logger.Infof("start handling failure status from master...")
+	if err := s.cli.ProcessFailureStateStreaming(s.nodeID, handler, s.stopC); err != nil {
+		return err
+	}
+	s.running = true
+	return nil
+}
+
+// stop stops streaming the failure states from the master.
+func (s *stateHandling) stop() error {
+	s.stMu.Lock()
+	defer s.stMu.Unlock()
+	if !s.running {
+		return fmt.Errorf("can't stop, failure state handler not running")
+	}
+
+	s.logger.Infof("stopping handling failure status from master...")
+	select {
+	case <-s.clock.After(stopTimeout):
+		return fmt.Errorf("timeout stopping the handler of failure statuses from master")
+	case s.stopC <- struct{}{}:
+	}
+	s.running = false
+
+	return nil
+}
+
+// LogFailureState will process the failures received from the master and will only log them.
+type LogFailureState struct {
+	stateHandling
+}
+
 // NewLogFailureState returns a new Failurestate.
 func NewLogFailureState(nodeID string, cli client.Failure, clock clock.Clock, logger log.Logger) *LogFailureState {
 	logger = logger.WithField("kind", "log").WithField("service", "failureState")
 	return &LogFailureState{
-		nodeID: nodeID,
-		cli:    cli,
-		stopC:  make(chan struct{}),
-		logger: logger,
-		clock:  clock,
+		stateHandling: stateHandling{
+			nodeID: nodeID,
+			cli:    cli,
+			stopC:  make(chan struct{}),
+			logger: logger,
+			clock:  clock,
+		},
 	}
 }
 
 // StartHandling satisfies FailureState interface.
 func (l *LogFailureState) StartHandling() error {
-	l.stMu.Lock()
-	defer l.stMu.Unlock()
+	return l.start(l)
+}
 
-	if l.running {
-		return fmt.Errorf("failure state handler already running")
+// StopHandling satisfies FailureState interface.
+func (l *LogFailureState) StopHandling() error {
+	return l.stop()
+}
+
+// ProcessFailureStates implements client.FailureStateHandler
+func (l *LogFailureState) ProcessFailureStates(failures []*v1.Failure) error {
+	for _, fl := range failures {
+		l.logger.WithField("failure", fl.Metadata.ID).Infof("%+v", fl)
 	}
+	return nil
+}
 
-	l.logger.Infof("start handling failure status from master...")
-	if err := l.cli.ProcessFailureStateStreaming(l.nodeID, l, l.stopC); err != nil {
+// InjectionFailureState will process the failures received from the master injecting
+// them on the node. The received failures are reconciled with the running injections:
+// the failures expected to be enabled are injected, the ones expected to be disabled or
+// not received anymore are reverted and the rest are left alone. The status changes of
+// the injections are reported back to the master.
+// The reverts that fail are retried by the janitor until they are reverted, the
+// failure is removed or its expected state is set to stale by an operator.
+type InjectionFailureState struct {
+	stateHandling
+	injCfg     injection.Config
+	janitor    *injection.Janitor
+	injections map[string]*injection.Injection
+	reported   map[string]v1.FailureStatus // reported are the last statuses reported to the master.
+	stopping   bool                        // stopping rejects the new failures while the injections are reverted.
+	mu         sync.Mutex                  // mu is the injections mutex.
+	wg         sync.WaitGroup              // wg waits for the running injection transitions.
+}
+
+// NewInjectionFailureState returns a new InjectionFailureState, the injections will be
+// created with the injection configuration, by default with the service logger and clock.
+// The janitor is optional, without janitor the failed reverts are not retried.
+func NewInjectionFailureState(nodeID string, cli client.Failure, injCfg injection.Config, janitor *injection.Janitor, clock clock.Clock, logger log.Logger) *InjectionFailureState {
+	logger = logger.WithField("kind", "injection").WithField("service", "failureState")
+	if injCfg.Logger == nil {
+		injCfg.Logger = logger
+	}
+	if injCfg.Clock == nil {
+		injCfg.Clock = clock
+	}
+	return &InjectionFailureState{
+		stateHandling: stateHandling{
+			nodeID: nodeID,
+			cli:    cli,
+			stopC:  make(chan struct{}),
+			logger: logger,
+			clock:  clock,
+		},
+		injCfg:     injCfg,
+		janitor:    janitor,
+		injections: map[string]*injection.Injection{},
+		reported:   map[string]v1.FailureStatus{},
+	}
+}
+
+// StartHandling satisfies FailureState interface.
+func (i *InjectionFailureState) StartHandling() error {
+	i.mu.Lock()
+	i.stopping = false
+	i.mu.Unlock()
+
+	if err := i.start(i); err != nil {
 		return err
 	}
-	l.running = true
+	if i.janitor != nil {
+		return i.janitor.Start()
+	}
 	return nil
 }
 
-// StopHandling satisfies FailureState interface.
-func (l *LogFailureState) StopHandling() error {
-	l.stMu.Lock()
-	defer l.stMu.Unlock()
-	if !l.running {
-		return fmt.Errorf("can't stop, failure state handler not running")
+// StopHandling satisfies FailureState interface. The injected failures are reverted
+// so the node doesn't leave them behind when it stops.
+func (i *InjectionFailureState) StopHandling() error {
+	errStr := ""
+	if err := i.stop(); err != nil {
+		errStr = fmt.Sprintf("%s; %s", errStr, err)
+	}
+	if i.janitor != nil {
+		if err := i.janitor.Stop(); err != nil {
+			errStr = fmt.Sprintf("%s; %s", errStr, err)
+		}
+	}
+	if err := i.revertAll(); err != nil {
+		errStr = fmt.Sprintf("%s; %s", errStr, err)
 	}
 
-	l.logger.Infof("stopping handling failure status from master...")
-	select {
-	case <-l.clock.After(stopTimeout):
-		return fmt.Errorf("timeout stopping the handler of failure statuses from master")
-	case l.stopC <- struct{}{}:
+	if errStr != "" {
+		return fmt.Errorf("error stopping the failure handling: %s", errStr)
 	}
-	l.running = false
+	return nil
+}
+
+// revertAll reverts all the injections and forgets them, the new statuses are reported
+// to the master.
+func (i *InjectionFailureState) revertAll() error {
+	// Wait for the running transitions so they are not reverted while being injected.
+	i.mu.Lock()
+	i.stopping = true
+	i.mu.Unlock()
+	i.wg.Wait()
 
+	i.mu.Lock()
+	transitions := map[string]func() error{}
+	for id, inj := range i.injections {
+		transitions[id] = i.revertTransition(inj)
+	}
+	i.mu.Unlock()
+
+	errStr := runTransitions(transitions)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.reportStatuses(); err != nil {
+		errStr = fmt.Sprintf("%s; %s", errStr, err)
+	}
+	i.injections = map[string]*injection.Injection{}
+	i.reported = map[string]v1.FailureStatus{}
+
+	if errStr != "" {
+		return fmt.Errorf("error reverting failures: %s", errStr)
+	}
 	return nil
 }
 
-// ProcessFailureStates implements client.FailureStateHandler
-func (l *LogFailureState) ProcessFailureStates(failures []*v1.Failure) error {
+// ProcessFailureStates implements client.FailureStateHandler. The injections and
+// reverts are run outside the lock, one goroutine per failure, so a slow failure
+// doesn't delay the other ones.
+func (i *InjectionFailureState) ProcessFailureStates(failures []*v1.Failure) error {
+	i.mu.Lock()
+	if i.stopping {
+		i.mu.Unlock()
+		return fmt.Errorf("can't process failures, failure state handler stopping")
+	}
+
+	errStr := ""
+	received := map[string]bool{}
+	acknowledged := map[string]bool{}
+	transitions := map[string]func() error{}
 	for _, fl := range failures {
-		l.logger.WithField("failure", fl.Metadata.ID).Infof("%+v", fl)
+		id := fl.Metadata.ID
+		received[id] = true
+		inj, ok := i.injections[id]
+
+		switch fl.Status.ExpectedState {
+		case v1.EnabledFailureState:
+			if ok {
+				continue
+			}
+			// The injection updates the failure status, don't modify the received failure.
+			inj, err := injection.NewInjectionWithConfig(fl.DeepCopy().(*v1.Failure), i.injCfg)
+			if err != nil {
+				errStr = fmt.Sprintf("%s; could not create '%s' failure injection: %s", errStr, id, err)
+				continue
+			}
+			// Track it even if it fails so it's not injected again.
+			i.injections[id] = inj
+			transitions[id] = i.failTransition(inj)
+		case v1.DisabledFailureState:
+			if !ok {
+				continue
+			}
+			transitions[id] = i.revertTransition(inj)
+		case v1.StaleFailureState:
+			// The operator acknowledges the failure, stop retrying its revert.
+			acknowledged[id] = true
+			if ok && i.janitor != nil {
+				i.janitor.Acknowledge(id)
+			}
+		}
+	}
+
+	// Revert the failures that aren't on the node anymore.
+	for id, inj := range i.injections {
+		if received[id] {
+			continue
+		}
+		transitions[id] = i.revertTransition(inj)
+		delete(i.injections, id)
+		delete(i.reported, id)
+		if i.janitor != nil {
+			i.janitor.Acknowledge(id)
+		}
+	}
+	i.wg.Add(1)
+	i.mu.Unlock()
+
+	errStr += runTransitions(transitions)
+	i.wg.Done()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Retry the reverts that failed.
+	if i.janitor != nil {
+		for id, inj := range i.injections {
+			inj.Lock()
+			errored := inj.Status.CurrentState == v1.ErroredRevertingFailureState
+			inj.Unlock()
+			if errored && !acknowledged[id] {
+				i.janitor.Add(inj)
+			}
+		}
+	}
+
+	if err := i.reportStatuses(); err != nil {
+		errStr = fmt.Sprintf("%s; %s", errStr, err)
+	}
+
+	if errStr != "" {
+		return fmt.Errorf("error processing failures: %s", errStr)
+	}
+	return nil
+}
+
+// runTransitions runs the transitions of the injections concurrently and returns the
+// aggregated errors.
+func runTransitions(transitions map[string]func() error) string {
+	errStr := ""
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, tr := range transitions {
+		wg.Add(1)
+		go func(tr func() error) {
+			defer wg.Done()
+			if err := tr(); err != nil {
+				mu.Lock()
+				errStr = fmt.Sprintf("%s; %s", errStr, err)
+				mu.Unlock()
+			}
+		}(tr)
+	}
+	wg.Wait()
+	return errStr
+}
+
+// failTransition returns the transition that injects the failure.
+func (i *InjectionFailureState) failTransition(inj *injection.Injection) func() error {
+	return func() error {
+		if err := inj.Fail(); err != nil {
+			return fmt.Errorf("could not inject '%s' failure: %s", inj.Metadata.ID, err)
+		}
+		i.logger.WithField("failure", inj.Metadata.ID).Infof("failure injected")
+		return nil
+	}
+}
+
+// revertTransition returns the transition that reverts the injection if it's being executed.
+func (i *InjectionFailureState) revertTransition(inj *injection.Injection) func() error {
+	return func() error {
+		inj.Lock()
+		executing := inj.Status.CurrentState == v1.ExecutingFailureState
+		inj.Unlock()
+		if !executing {
+			return nil
+		}
+
+		if err := inj.Revert(); err != nil {
+			return fmt.Errorf("could not revert '%s' failure: %s", inj.Metadata.ID, err)
+		}
+		i.logger.WithField("failure", inj.Metadata.ID).Infof("failure reverted")
+		return nil
+	}
+}
+
+// reportStatuses reports to the master the statuses of the injections that have changed
+// since the last report, the ones that couldn't be reported will be retried on the next
+// report.
+func (i *InjectionFailureState) reportStatuses() error {
+	errStr := ""
+	for id, inj := range i.injections {
+		inj.Lock()
+		flr := *inj.Failure
+		inj.Unlock()
+
+		if last, ok := i.reported[id]; ok && sameReportedStatus(last, flr.Status) {
+			continue
+		}
+
+		if err := i.cli.ReportFailureStatus(i.nodeID, &flr); err != nil {
+			errStr = fmt.Sprintf("%s; could not report '%s' failure status: %s", errStr, id, err)
+			continue
+		}
+		i.reported[id] = flr.Status
+	}
+
+	if errStr != "" {
+		return fmt.Errorf("error reporting failure statuses: %s", errStr)
 	}
 	return nil
 }
+
+// sameReportedStatus returns true if the status set by the node hasn't changed.
+func sameReportedStatus(a, b v1.FailureStatus) bool {
+	return a.CurrentState == b.CurrentState &&
+		a.Executed.Equal(b.Executed) &&
+		a.Finished.Equal(b.Finished) &&
+		a.Message == b.Message
+}
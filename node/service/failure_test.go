@@ -12,8 +12,10 @@ import (
 	"github.com/slok/ragnarok/api"
 	"github.com/slok/ragnarok/api/chaos/v1"
 	"github.com/slok/ragnarok/attack"
+	"github.com/slok/ragnarok/chaos/injection"
 	"github.com/slok/ragnarok/clock"
 	"github.com/slok/ragnarok/log"
+	mattack "github.com/slok/ragnarok/mocks/attack"
 	mclock "github.com/slok/ragnarok/mocks/clock"
 	mlog "github.com/slok/ragnarok/mocks/log"
 	mclient "github.com/slok/ragnarok/mocks/node/client"
@@ -221,3 +223,274 @@ func TestLogFailureStateProcessing(t *testing.T) {
 		})
 	}
 }
+
+func TestInjectionFailureStateProcessing(t *testing.T) {
+	newFailure := func(id string, st v1.FailureState, kind string) *v1.Failure {
+		return &v1.Failure{
+			Metadata: api.ObjectMeta{ID: id},
+			Spec: v1.FailureSpec{
+				Timeout: time.Hour,
+				Attacks: []v1.AttackMap{{kind: attack.Opts{}}},
+			},
+			Status: v1.FailureStatus{ExpectedState: st},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		processes  [][]*v1.Failure
+		expApplies int
+		expReverts int
+		expErr     bool
+	}{
+		{
+			name: "A failure expected to be enabled should be injected.",
+			processes: [][]*v1.Failure{
+				{newFailure("f1", v1.EnabledFailureState, "attack1")},
+			},
+			expApplies: 1,
+		},
+		{
+			name: "A failure expected to be enabled that is already injected should be left alone.",
+			processes: [][]*v1.Failure{
+				{newFailure("f1", v1.EnabledFailureState, "attack1")},
+				{newFailure("f1", v1.EnabledFailureState, "attack1")},
+			},
+			expApplies: 1,
+		},
+		{
+			name: "A failure expected to be disabled that is injected should be reverted.",
+			processes: [][]*v1.Failure{
+				{newFailure("f1", v1.EnabledFailureState, "attack1")},
+				{newFailure("f1", v1.DisabledFailureState, "attack1")},
+				{newFailure("f1", v1.DisabledFailureState, "attack1")},
+			},
+			expApplies: 1,
+			expReverts: 1,
+		},
+		{
+			name: "A failure that is not received anymore should be reverted.",
+			processes: [][]*v1.Failure{
+				{
+					newFailure("f1", v1.EnabledFailureState, "attack1"),
+					newFailure("f2", v1.EnabledFailureState, "attack1"),
+				},
+				{newFailure("f2", v1.EnabledFailureState, "attack1")},
+			},
+			expApplies: 2,
+			expReverts: 1,
+		},
+		{
+			name: "A failure expected to be disabled that is not injected should be left alone.",
+			processes: [][]*v1.Failure{
+				{newFailure("f1", v1.DisabledFailureState, "attack1")},
+				{newFailure("f1", v1.StaleFailureState, "attack1")},
+			},
+		},
+		{
+			name: "A failure that can't be injected should error without stopping the other ones.",
+			processes: [][]*v1.Failure{
+				{
+					newFailure("f1", v1.EnabledFailureState, "wrong"),
+					newFailure("f2", v1.EnabledFailureState, "attack1"),
+				},
+			},
+			expApplies: 1,
+			expErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			// Mocks.
+			matt := &mattack.Attacker{}
+			matt.On("Apply", mock.Anything).Return(nil)
+			matt.On("Revert").Return(nil)
+			mreg := &mattack.Registry{}
+			mreg.On("New", "attack1", mock.Anything).Return(matt, nil)
+			mreg.On("New", "wrong", mock.Anything).Return(nil, errors.New("wanted error"))
+			mf := &mclient.Failure{}
+			mf.On("ReportFailureStatus", "test", mock.Anything).Return(nil)
+
+			ifs := service.NewInjectionFailureState("test", mf, injection.Config{
+				Registry:       mreg,
+				VerifyInterval: -1,
+			}, nil, clock.Base(), log.Dummy)
+
+			var err error
+			for _, flrs := range test.processes {
+				err = ifs.ProcessFailureStates(flrs)
+			}
+
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			matt.AssertNumberOfCalls(t, "Apply", test.expApplies)
+			matt.AssertNumberOfCalls(t, "Revert", test.expReverts)
+		})
+	}
+}
+
+func TestInjectionFailureStateReporting(t *testing.T) {
+	assert := assert.New(t)
+
+	enabled := &v1.Failure{
+		Metadata: api.ObjectMeta{ID: "f1"},
+		Spec: v1.FailureSpec{
+			Timeout: time.Hour,
+			Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+		},
+		Status: v1.FailureStatus{ExpectedState: v1.EnabledFailureState},
+	}
+	disabled := *enabled
+	disabled.Status.ExpectedState = v1.DisabledFailureState
+
+	// Mocks.
+	matt := &mattack.Attacker{}
+	matt.On("Apply", mock.Anything).Return(nil)
+	matt.On("Revert").Return(nil)
+	mreg := &mattack.Registry{}
+	mreg.On("New", "attack1", mock.Anything).Return(matt, nil)
+
+	// Save the reported states, the first report of the disabled state will fail.
+	var reported []v1.FailureState
+	mf := &mclient.Failure{}
+	mf.On("ReportFailureStatus", "test", mock.Anything).Return(func(_ string, f *v1.Failure) error {
+		if f.Status.CurrentState == v1.DisabledFailureState && len(reported) == 1 {
+			reported = append(reported, v1.UnknownFailureState)
+			return errors.New("wanted error")
+		}
+		reported = append(reported, f.Status.CurrentState)
+		return nil
+	})
+
+	ifs := service.NewInjectionFailureState("test", mf, injection.Config{
+		Registry:       mreg,
+		VerifyInterval: -1,
+	}, nil, clock.Base(), log.Dummy)
+
+	assert.NoError(ifs.ProcessFailureStates([]*v1.Failure{enabled}))
+	assert.NoError(ifs.ProcessFailureStates([]*v1.Failure{enabled}))
+	assert.Error(ifs.ProcessFailureStates([]*v1.Failure{&disabled}))
+	assert.NoError(ifs.ProcessFailureStates([]*v1.Failure{&disabled}))
+	assert.NoError(ifs.ProcessFailureStates([]*v1.Failure{&disabled}))
+
+	exp := []v1.FailureState{
+		v1.ExecutingFailureState,
+		v1.UnknownFailureState,
+		v1.DisabledFailureState,
+	}
+	assert.Equal(exp, reported)
+}
+
+func TestInjectionFailureStateStopReverts(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	enabled := &v1.Failure{
+		Metadata: api.ObjectMeta{ID: "f1"},
+		Spec: v1.FailureSpec{
+			Timeout: time.Hour,
+			Attacks: []v1.AttackMap{{"attack1": attack.Opts{}}},
+		},
+		Status: v1.FailureStatus{ExpectedState: v1.EnabledFailureState},
+	}
+
+	// Mocks.
+	matt := &mattack.Attacker{}
+	matt.On("Apply", mock.Anything).Return(nil)
+	matt.On("Revert").Return(nil)
+	mreg := &mattack.Registry{}
+	mreg.On("New", "attack1", mock.Anything).Return(matt, nil)
+	var reported []v1.FailureState
+	mf := &mclient.Failure{}
+	mf.On("ReportFailureStatus", "test", mock.Anything).Return(func(_ string, f *v1.Failure) error {
+		reported = append(reported, f.Status.CurrentState)
+		return nil
+	})
+	mf.On("ProcessFailureStateStreaming", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		c := args.Get(2).(<-chan struct{})
+		go func() {
+			<-c
+		}()
+	})
+	mc := &mclock.Clock{}
+	mc.On("After", mock.Anything).Return((<-chan time.Time)(make(chan time.Time)))
+
+	ifs := service.NewInjectionFailureState("test", mf, injection.Config{
+		Registry:       mreg,
+		Clock:          clock.Base(),
+		VerifyInterval: -1,
+	}, nil, mc, log.Dummy)
+
+	require.NoError(ifs.StartHandling())
+	require.NoError(ifs.ProcessFailureStates([]*v1.Failure{enabled}))
+	assert.NoError(ifs.StopHandling())
+
+	matt.AssertNumberOfCalls(t, "Revert", 1)
+	assert.Equal([]v1.FailureState{v1.ExecutingFailureState, v1.DisabledFailureState}, reported)
+}
+
+func TestInjectionFailureStateConcurrentTransitions(t *testing.T) {
+	assert := assert.New(t)
+
+	newFailure := func(id string, st v1.FailureState, kind string) *v1.Failure {
+		return &v1.Failure{
+			Metadata: api.ObjectMeta{ID: id},
+			Spec: v1.FailureSpec{
+				Timeout: time.Hour,
+				Attacks: []v1.AttackMap{{kind: attack.Opts{}}},
+			},
+			Status: v1.FailureStatus{ExpectedState: st},
+		}
+	}
+
+	// Every revert waits for the other one, a sequential processing would time out.
+	revert1C, revert2C := make(chan struct{}), make(chan struct{})
+	var timedOut bool
+	waitOther := func(own, other chan struct{}) func(mock.Arguments) {
+		return func(mock.Arguments) {
+			close(own)
+			select {
+			case <-other:
+			case <-time.After(5 * time.Second):
+				timedOut = true
+			}
+		}
+	}
+
+	// Mocks.
+	matt1 := &mattack.Attacker{}
+	matt1.On("Apply", mock.Anything).Return(nil)
+	matt1.On("Revert").Return(nil).Run(waitOther(revert1C, revert2C))
+	matt2 := &mattack.Attacker{}
+	matt2.On("Apply", mock.Anything).Return(nil)
+	matt2.On("Revert").Return(nil).Run(waitOther(revert2C, revert1C))
+	mreg := &mattack.Registry{}
+	mreg.On("New", "attack1", mock.Anything).Return(matt1, nil)
+	mreg.On("New", "attack2", mock.Anything).Return(matt2, nil)
+	mf := &mclient.Failure{}
+	mf.On("ReportFailureStatus", "test", mock.Anything).Return(nil)
+
+	ifs := service.NewInjectionFailureState("test", mf, injection.Config{
+		Registry:       mreg,
+		VerifyInterval: -1,
+	}, nil, clock.Base(), log.Dummy)
+
+	assert.NoError(ifs.ProcessFailureStates([]*v1.Failure{
+		newFailure("f1", v1.EnabledFailureState, "attack1"),
+		newFailure("f2", v1.EnabledFailureState, "attack2"),
+	}))
+	assert.NoError(ifs.ProcessFailureStates([]*v1.Failure{
+		newFailure("f1", v1.DisabledFailureState, "attack1"),
+		newFailure("f2", v1.DisabledFailureState, "attack2"),
+	}))
+
+	assert.False(timedOut, "the reverts should run concurrently")
+	matt1.AssertNumberOfCalls(t, "Revert", 1)
+	matt2.AssertNumberOfCalls(t, "Revert", 1)
+}
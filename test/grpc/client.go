@@ -9,6 +9,7 @@ import (
 
 	chaosv1pb "github.com/slok/ragnarok/api/chaos/v1/pb"
 	clusterv1pb "github.com/slok/ragnarok/api/cluster/v1/pb"
+	pbac "github.com/slok/ragnarok/grpc/attackcatalog"
 	pbfs "github.com/slok/ragnarok/grpc/failurestatus"
 	pbns "github.com/slok/ragnarok/grpc/nodestatus"
 )
@@ -19,6 +20,7 @@ type TestClient struct {
 
 	nsCli pbns.NodeStatusClient
 	fsCli pbfs.FailureStatusClient
+	acCli pbac.AttackCatalogClient
 }
 
 // NewTestClient creates and returns a new test client
@@ -32,6 +34,7 @@ func NewTestClient(addr string) (*TestClient, error) {
 		conn:  conn,
 		nsCli: pbns.NewNodeStatusClient(conn),
 		fsCli: pbfs.NewFailureStatusClient(conn),
+		acCli: pbac.NewAttackCatalogClient(conn),
 	}, nil
 }
 
@@ -59,3 +62,8 @@ func (t *TestClient) FailureStatusGetFailure(ctx context.Context, fID *pbfs.Fail
 func (t *TestClient) FailureStatusFailureStateList(ctx context.Context, nID *pbfs.NodeId) (pbfs.FailureStatus_FailureStateListClient, error) {
 	return t.fsCli.FailureStateList(ctx, nID)
 }
+
+// AttackCatalogList wraps the call to attackcatalog service.
+func (t *TestClient) AttackCatalogList(ctx context.Context) (*chaosv1pb.AttackList, error) {
+	return t.acCli.List(ctx, &pbempty.Empty{})
+}